@@ -0,0 +1,112 @@
+package chain
+
+import "time"
+
+// BreakerState 表示节点熔断器的状态
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // 正常工作
+	BreakerOpen                         // 已熔断，节点被隔离
+	BreakerHalfOpen                     // 隔离期结束，等待探测结果
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig 定义节点熔断参数
+type BreakerConfig struct {
+	FailureThreshold int           // 连续失败多少次后熔断
+	BaseQuarantine   time.Duration // 首次隔离时长，之后每次熔断翻倍
+	MaxQuarantine    time.Duration // 隔离时长上限，节点恢复后稳定运行该时长则重置翻倍次数
+}
+
+// DefaultBreakerConfig 返回默认的熔断参数
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 3,
+		BaseQuarantine:   30 * time.Second,
+		MaxQuarantine:    30 * time.Minute,
+	}
+}
+
+// breaker 是单个节点的熔断器，调用方负责加锁
+type breaker struct {
+	state        BreakerState
+	failures     int       // 连续失败次数
+	trips        int       // 连续熔断次数，决定隔离时长
+	until        time.Time // 隔离结束时间
+	reinstatedAt time.Time // 最近一次恢复时间
+}
+
+// quarantine 返回第 trips 次熔断对应的隔离时长
+func (b *breaker) quarantine(cfg BreakerConfig) time.Duration {
+	d := cfg.BaseQuarantine
+	for i := 1; i < b.trips && d < cfg.MaxQuarantine; i++ {
+		d *= 2
+	}
+	if cfg.MaxQuarantine > 0 && d > cfg.MaxQuarantine {
+		d = cfg.MaxQuarantine
+	}
+	return d
+}
+
+// trip 熔断并隔离节点
+func (b *breaker) trip(cfg BreakerConfig, now time.Time) {
+	b.trips++
+	b.state = BreakerOpen
+	b.until = now.Add(b.quarantine(cfg))
+}
+
+// recordFailure 记录一次失败，返回是否因此熔断
+func (b *breaker) recordFailure(cfg BreakerConfig, now time.Time) bool {
+	switch b.state {
+	case BreakerHalfOpen:
+		// 探测失败，重新隔离且隔离时长翻倍
+		b.trip(cfg, now)
+		return true
+	case BreakerClosed:
+		b.failures++
+		if b.failures >= cfg.FailureThreshold {
+			b.failures = 0
+			b.trip(cfg, now)
+			return true
+		}
+	}
+	return false
+}
+
+// recordSuccess 记录一次成功，返回节点是否因此从隔离中恢复
+func (b *breaker) recordSuccess(cfg BreakerConfig, now time.Time) bool {
+	switch b.state {
+	case BreakerHalfOpen:
+		b.state = BreakerClosed
+		b.failures = 0
+		b.reinstatedAt = now
+		return true
+	case BreakerClosed:
+		b.failures = 0
+		if b.trips > 0 && now.Sub(b.reinstatedAt) > cfg.MaxQuarantine {
+			b.trips = 0
+		}
+	}
+	return false
+}
+
+// probeable 判断节点是否可以被探测，隔离期结束的节点会进入半开状态
+func (b *breaker) probeable(now time.Time) bool {
+	if b.state == BreakerOpen && !now.Before(b.until) {
+		b.state = BreakerHalfOpen
+	}
+	return b.state != BreakerOpen
+}
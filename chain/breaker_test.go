@@ -0,0 +1,134 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBreakerTransitions(t *testing.T) {
+	cfg := BreakerConfig{FailureThreshold: 2, BaseQuarantine: time.Minute, MaxQuarantine: 10 * time.Minute}
+	start := time.Now()
+
+	type step struct {
+		op      string // fail, ok, probe
+		at      time.Duration
+		state   BreakerState
+		changed bool // recordFailure 是否熔断、recordSuccess 是否恢复、probeable 的返回值
+	}
+	tests := []struct {
+		name  string
+		steps []step
+		until time.Duration // 最终的隔离结束时间，0 表示不检查
+	}{
+		{
+			name: "closed stays closed below threshold",
+			steps: []step{
+				{"fail", 0, BreakerClosed, false},
+				{"ok", 0, BreakerClosed, false},
+				{"fail", 0, BreakerClosed, false},
+			},
+		},
+		{
+			name: "closed to open at threshold",
+			steps: []step{
+				{"fail", 0, BreakerClosed, false},
+				{"fail", 0, BreakerOpen, true},
+				{"probe", 30 * time.Second, BreakerOpen, false},
+			},
+			until: time.Minute,
+		},
+		{
+			name: "open to half-open to closed",
+			steps: []step{
+				{"fail", 0, BreakerClosed, false},
+				{"fail", 0, BreakerOpen, true},
+				{"probe", time.Minute, BreakerHalfOpen, true},
+				{"ok", time.Minute, BreakerClosed, true},
+				{"fail", time.Minute, BreakerClosed, false},
+			},
+		},
+		{
+			name: "half-open probe failure doubles quarantine",
+			steps: []step{
+				{"fail", 0, BreakerClosed, false},
+				{"fail", 0, BreakerOpen, true},
+				{"probe", time.Minute, BreakerHalfOpen, true},
+				{"fail", time.Minute, BreakerOpen, true},
+			},
+			until: 3 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b breaker
+			for i, s := range tt.steps {
+				now := start.Add(s.at)
+				var changed bool
+				switch s.op {
+				case "fail":
+					changed = b.recordFailure(cfg, now)
+				case "ok":
+					changed = b.recordSuccess(cfg, now)
+				case "probe":
+					changed = b.probeable(now)
+				}
+				if b.state != s.state || changed != s.changed {
+					t.Fatalf("step %d (%s): expected %s/%v, got %s/%v", i, s.op, s.state, s.changed, b.state, changed)
+				}
+			}
+			if tt.until != 0 && !b.until.Equal(start.Add(tt.until)) {
+				t.Errorf("Expected quarantine until +%s, got +%s", tt.until, b.until.Sub(start))
+			}
+		})
+	}
+}
+
+func TestBreakerQuarantine(t *testing.T) {
+	cfg := BreakerConfig{BaseQuarantine: time.Minute, MaxQuarantine: 5 * time.Minute}
+	tests := []struct {
+		trips int
+		want  time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 5 * time.Minute},
+		{10, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		b := breaker{trips: tt.trips}
+		if got := b.quarantine(cfg); got != tt.want {
+			t.Errorf("trips %d: expected %s, got %s", tt.trips, tt.want, got)
+		}
+	}
+}
+
+func TestClusterHandleErrorIgnoresApplicationErrors(t *testing.T) {
+	nodes := []*fakeNode{{name: "a", height: 100}, {name: "b", height: 100}}
+	c := NewCluster(nodes, time.Minute)
+	c.SetBreakerConfig(BreakerConfig{FailureThreshold: 1, BaseQuarantine: time.Minute, MaxQuarantine: time.Minute})
+	current := c.Current()
+	ctx := context.Background()
+
+	// 合约回滚不计入节点失败，熔断器保持关闭
+	c.HandleError(ctx, errors.New("execution reverted: nonce already processed"), map[string]any{})
+	for _, m := range c.members {
+		if m.breaker.state != BreakerClosed {
+			t.Fatalf("Expected breaker of %s to stay closed after a revert, got %v", m.name, m.breaker.state)
+		}
+	}
+	if c.Current() != current {
+		t.Fatalf("Expected no switch after a revert, got %s", c.Current().name)
+	}
+
+	// 连接错误计入失败并熔断
+	c.HandleError(ctx, syscall.ECONNREFUSED, map[string]any{})
+	for _, m := range c.members {
+		if m.client == current && m.breaker.state != BreakerOpen {
+			t.Fatalf("Expected breaker of %s to open after a connection error, got %v", m.name, m.breaker.state)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package bsc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+var _ relay.BatchProcessor = (*Client)(nil)
+
+// batchABI 是跨链合约批量处理方法的 ABI。ids 为各消息的确定性 ID（relay.MessageID），
+// 合约据此记录 isProcessed 并拒绝重复处理；tokens 为各消息释放的目标端代币，零地址表示合约的默认资产
+const batchABI = `[{
+	"type": "function",
+	"name": "processBatch",
+	"inputs": [
+		{"name": "fromNonce", "type": "uint256"},
+		{"name": "toNonce", "type": "uint256"},
+		{"name": "ids", "type": "bytes32[]"},
+		{"name": "tokens", "type": "address[]"},
+		{"name": "receivers", "type": "address[]"},
+		{"name": "amounts", "type": "uint256[]"}
+	],
+	"outputs": []
+}]`
+
+var bridgeBatchABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(batchABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// PackBatch 将批次编码为 processBatch 的调用数据
+func PackBatch(batch *relay.BatchMsg) ([]byte, error) {
+	ids := make([][32]byte, len(batch.Msgs))
+	tokens := make([]common.Address, len(batch.Msgs))
+	receivers := make([]common.Address, len(batch.Msgs))
+	amounts := make([]*big.Int, len(batch.Msgs))
+	for i, msg := range batch.Msgs {
+		id, err := msg.ID()
+		if err != nil {
+			return nil, fmt.Errorf("%w: nonce %d: %w", relay.ErrInvalidMessage, msg.Nonce, err)
+		}
+		if !common.IsHexAddress(msg.Receiver) {
+			return nil, fmt.Errorf("%w: invalid receiver %q of nonce %d", relay.ErrInvalidMessage, msg.Receiver, msg.Nonce)
+		}
+		amount, err := msg.Value()
+		if err != nil {
+			return nil, err
+		}
+		// 未经代币登记表换算的消息释放默认资产，金额为源端原值
+		if msg.TargetToken != "" {
+			if !common.IsHexAddress(msg.TargetToken) {
+				return nil, fmt.Errorf("%w: invalid target token %q of nonce %d", relay.ErrInvalidMessage, msg.TargetToken, msg.Nonce)
+			}
+			tokens[i] = common.HexToAddress(msg.TargetToken)
+		}
+		ids[i] = common.HexToHash(id)
+		receivers[i] = common.HexToAddress(msg.Receiver)
+		amounts[i] = amount
+	}
+
+	return bridgeBatchABI.Pack("processBatch",
+		new(big.Int).SetUint64(batch.FromNonce),
+		new(big.Int).SetUint64(batch.ToNonce),
+		ids,
+		tokens,
+		receivers,
+		amounts,
+	)
+}
+
+// ProcessBatch 通过 processBatch 合约方法提交一批跨入消息，等待交易打包
+func (c *Client) ProcessBatch(ctx context.Context, batch *relay.BatchMsg) error {
+	data, err := PackBatch(batch)
+	if err != nil {
+		return err
+	}
+
+	c.logger.Info("Processing cross-chain batch", map[string]any{
+		"from_nonce": batch.FromNonce,
+		"to_nonce":   batch.ToNonce,
+		"count":      len(batch.Msgs),
+		"total":      batch.Total,
+		"size":       len(data),
+	})
+
+	receipt, err := c.sendTx(ctx, data, batch.FeePolicy)
+	if err != nil {
+		return err
+	}
+	c.logger.Info("Cross-chain batch processed", map[string]any{
+		"from_nonce": batch.FromNonce,
+		"to_nonce":   batch.ToNonce,
+		"tx_hash":    receipt.TxHash.Hex(),
+		"gas_used":   receipt.GasUsed,
+	})
+	return nil
+}
@@ -0,0 +1,62 @@
+package bsc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+func TestPackBatch(t *testing.T) {
+	msgs := []relay.InMsg{
+		{Nonce: 1, ChainID: "1", TxHash: "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060", LogIndex: 0,
+			Receiver: "0x00000000000000000000000000000000000000b2", Amount: "100", TargetToken: "0x00000000000000000000000000000000000000c3"},
+		{Nonce: 2, ChainID: "1", TxHash: "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060", LogIndex: 1,
+			Receiver: "0x00000000000000000000000000000000000000b3", Amount: "200", TargetToken: "0x00000000000000000000000000000000000000c3"},
+	}
+	batch := relay.NewBatchMsg(msgs)
+
+	data, err := PackBatch(&batch)
+	if err != nil {
+		t.Fatalf("PackBatch failed: %v", err)
+	}
+	method := bridgeBatchABI.Methods["processBatch"]
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		t.Fatalf("Failed to unpack batch: %v", err)
+	}
+
+	// 合约按 ID 记录 isProcessed，ID 必须与 relay.MessageID 一致
+	ids := args[2].([][32]byte)
+	for i, msg := range msgs {
+		want, _ := msg.ID()
+		if common.Hash(ids[i]) != common.HexToHash(want) {
+			t.Errorf("Expected id %s for nonce %d, got %x", want, msg.Nonce, ids[i])
+		}
+	}
+	// 合约释放代币登记表换算时使用的目标端代币
+	if tokens := args[3].([]common.Address); tokens[0] != common.HexToAddress(msgs[0].TargetToken) {
+		t.Errorf("Unexpected tokens %v", tokens)
+	}
+	if amounts := args[5].([]*big.Int); amounts[1].Int64() != 200 {
+		t.Errorf("Unexpected amounts %v", amounts)
+	}
+
+	// 目标端代币地址不是本链地址时不能提交
+	msgs[1].TargetToken = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	foreign := relay.NewBatchMsg(msgs)
+	if _, err := PackBatch(&foreign); !errors.Is(err, relay.ErrInvalidMessage) {
+		t.Errorf("Expected %v for foreign token, got %v", relay.ErrInvalidMessage, err)
+	}
+	msgs[1].TargetToken = msgs[0].TargetToken
+
+	// 无法计算 ID 的消息不能提交
+	msgs[0].TxHash = "invalid"
+	invalid := relay.NewBatchMsg(msgs)
+	if _, err := PackBatch(&invalid); err == nil {
+		t.Error("Expected error for message without a valid ID")
+	}
+}
@@ -2,6 +2,7 @@ package bsc
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -12,25 +13,32 @@ import (
 	"github.com/st-chain/me-bridge/chain"
 	"github.com/st-chain/me-bridge/log"
 	"github.com/st-chain/me-bridge/relay"
+	"github.com/st-chain/me-bridge/signer"
 )
 
 var _ relay.Client = (*Client)(nil)
+var _ relay.BalanceReader = (*Client)(nil)
 
 // Client is a client for interacting with the Binance Smart Chain (BSC) network.
 type Client struct {
-	Network *chain.NetworkConfig
-	Config  *chain.ClientConfig
+	Network  *chain.NetworkConfig
+	Config   *chain.ClientConfig
+	Contract common.Address // 跨链合约地址
 
 	latestHeight uint64
 
 	Client   *bscclient.Client
 	WsClient *bscclient.Client
-	// key    signer.Signer
-	logger *log.Logger
+	Key      signer.Signer // 提交中继交易的账户，为 nil 时不能提交
+	logger   *log.Logger
 }
 
-// NewClient creates a new BSC client instance
-func NewClient(network *chain.NetworkConfig, config *chain.ClientConfig) (*Client, error) {
+// NewClient creates a new BSC client instance. contract 是跨链合约地址，为零地址时返回错误
+func NewClient(network *chain.NetworkConfig, config *chain.ClientConfig, contract common.Address) (*Client, error) {
+	if contract == (common.Address{}) {
+		return nil, fmt.Errorf("%w: contract address is zero", ErrInvalidAddress)
+	}
+
 	// Connect to BSC node
 	client, err := bscclient.Dial(config.RPCURL)
 	if err != nil {
@@ -43,8 +51,9 @@ func NewClient(network *chain.NetworkConfig, config *chain.ClientConfig) (*Clien
 	}
 
 	return &Client{
-		Network: network,
-		Config:  config,
+		Network:  network,
+		Config:   config,
+		Contract: contract,
 
 		Client:   client,
 		WsClient: wsClient,
@@ -61,6 +70,14 @@ func (c *Client) GetBalance(address common.Address, blockNumber *big.Int) (*big.
 	return c.Client.BalanceAt(context.Background(), address, blockNumber)
 }
 
+// Balance 返回中继账户最新的 BNB 余额，供余额监控使用
+func (c *Client) Balance(ctx context.Context, address string) (*big.Int, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, address)
+	}
+	return c.GetBalance(common.HexToAddress(address), nil)
+}
+
 // GetNonce returns the nonce for an address
 func (c *Client) GetNonce(address common.Address) (uint64, error) {
 	return c.Client.PendingNonceAt(context.Background(), address)
@@ -94,3 +111,8 @@ func (c *Client) Close() {
 		c.WsClient.Close()
 	}
 }
+
+// Name returns the configured node name, used to identify the client in a cluster
+func (c *Client) Name() string {
+	return c.Config.Name
+}
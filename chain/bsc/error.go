@@ -1,6 +1,10 @@
 package bsc
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/st-chain/me-bridge/types"
+)
 
 // nonce已经被使用
 var ErrNonceUsed = errors.New("nonce already used")
@@ -28,3 +32,18 @@ var ErrInvalidAddress = errors.New("invalid address")
 
 // 无效交易
 var ErrInvalidTransaction = errors.New("invalid transaction")
+
+// 未配置提交中继交易的密钥
+var ErrNoSigner = errors.New("relayer signer not configured")
+
+func init() {
+	types.RegisterClass(ErrNonceUsed, types.ClassRetryable)
+	types.RegisterClass(ErrInsufficientBalance, types.ClassInsufficientFunds)
+	types.RegisterClass(ErrTransactionNotFound, types.ClassRetryable)
+	types.RegisterClass(ErrTransactionFailed, types.ClassFatal)
+	types.RegisterClass(ErrNodeUnavailable, types.ClassConnection)
+	types.RegisterClass(ErrTimeout, types.ClassTimeout)
+	types.RegisterClass(ErrInvalidAddress, types.ClassFatal)
+	types.RegisterClass(ErrInvalidTransaction, types.ClassFatal)
+	types.RegisterClass(ErrNoSigner, types.ClassFatal)
+}
@@ -0,0 +1,69 @@
+package bsc
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+// eventABI 是跨链合约跨出事件的 ABI，nonce 和 sender 为索引字段
+const eventABI = `[{
+	"type": "event",
+	"name": "Relay",
+	"anonymous": false,
+	"inputs": [
+		{"name": "nonce", "type": "uint256", "indexed": true},
+		{"name": "sender", "type": "address", "indexed": true},
+		{"name": "receiver", "type": "address", "indexed": false},
+		{"name": "token", "type": "address", "indexed": false},
+		{"name": "amount", "type": "uint256", "indexed": false},
+		{"name": "fee", "type": "uint256", "indexed": false}
+	]
+}]`
+
+var bridgeEventABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(eventABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// relayEvent 是解码后的跨出事件
+type relayEvent struct {
+	Nonce    *big.Int
+	Sender   common.Address
+	Receiver common.Address
+	Token    common.Address
+	Amount   *big.Int
+	Fee      *big.Int
+}
+
+// unpackRelayEvent 解码跨出事件的索引字段和数据字段，日志不是跨出事件时返回错误
+func unpackRelayEvent(vLog types.Log) (*relayEvent, error) {
+	event := bridgeEventABI.Events["Relay"]
+	if len(vLog.Topics) == 0 || vLog.Topics[0] != event.ID {
+		return nil, fmt.Errorf("%w: log %s:%d is not a relay event", relay.ErrInvalidMessage, vLog.TxHash.Hex(), vLog.Index)
+	}
+
+	var ev relayEvent
+	if err := bridgeEventABI.UnpackIntoInterface(&ev, "Relay", vLog.Data); err != nil {
+		return nil, fmt.Errorf("%w: log %s:%d: %w", relay.ErrInvalidMessage, vLog.TxHash.Hex(), vLog.Index, err)
+	}
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if err := abi.ParseTopics(&ev, indexed, vLog.Topics[1:]); err != nil {
+		return nil, fmt.Errorf("%w: log %s:%d: %w", relay.ErrInvalidMessage, vLog.TxHash.Hex(), vLog.Index, err)
+	}
+	return &ev, nil
+}
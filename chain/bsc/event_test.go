@@ -0,0 +1,122 @@
+package bsc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+// packRelayEvent 按跨出事件的 ABI 编码测试日志
+func packRelayEvent(t *testing.T, ev relayEvent) types.Log {
+	t.Helper()
+	event := bridgeEventABI.Events["Relay"]
+	data, err := event.Inputs.NonIndexed().Pack(ev.Receiver, ev.Token, ev.Amount, ev.Fee)
+	if err != nil {
+		t.Fatalf("Failed to pack event: %v", err)
+	}
+	return types.Log{
+		Topics: []common.Hash{
+			event.ID,
+			common.BigToHash(ev.Nonce),
+			common.BytesToHash(ev.Sender.Bytes()),
+		},
+		Data:        data,
+		TxHash:      common.HexToHash("0x01"),
+		Index:       3,
+		BlockNumber: 100,
+	}
+}
+
+func TestToRelayLog(t *testing.T) {
+	ev := relayEvent{
+		Nonce:    big.NewInt(42),
+		Sender:   common.HexToAddress("0x00000000000000000000000000000000000000a1"),
+		Receiver: common.HexToAddress("0x00000000000000000000000000000000000000b2"),
+		Token:    common.HexToAddress("0x00000000000000000000000000000000000000c3"),
+		Amount:   big.NewInt(1000),
+		Fee:      big.NewInt(7),
+	}
+	c := &Client{}
+
+	relayLog, err := c.ToRelayLog(packRelayEvent(t, ev))
+	if err != nil {
+		t.Fatalf("Failed to decode relay event: %v", err)
+	}
+	if relayLog.Nonce != 42 || relayLog.Sender != ev.Sender.Hex() || relayLog.Receiver != ev.Receiver.Hex() ||
+		relayLog.Token != ev.Token.Hex() || relayLog.Amount != "1000" || relayLog.Fee != "7" ||
+		relayLog.LogIndex != 3 || relayLog.Height != 100 {
+		t.Errorf("Unexpected relay log %+v", relayLog)
+	}
+
+	// 其他合约事件不能解码为跨出消息
+	other := packRelayEvent(t, ev)
+	other.Topics[0] = common.HexToHash("0x02")
+	if _, err := c.ToRelayLog(other); !errors.Is(err, relay.ErrInvalidMessage) {
+		t.Errorf("Expected %v, got %v", relay.ErrInvalidMessage, err)
+	}
+}
+
+// receiptReader 返回另一节点上解码的源端事件
+type receiptReader struct {
+	log relay.InMsg
+}
+
+func (r receiptReader) ReadSource(ctx context.Context, msg relay.InMsg) (*relay.SourceReceipt, error) {
+	return &relay.SourceReceipt{
+		Node:      "node-b",
+		Found:     true,
+		Succeeded: true,
+		Height:    r.log.Height,
+		Canonical: true,
+		Latest:    r.log.Height,
+		Log:       &r.log,
+	}, nil
+}
+
+func TestSourceVerifierRejectsTamperedEvent(t *testing.T) {
+	ev := relayEvent{
+		Nonce:    big.NewInt(42),
+		Sender:   common.HexToAddress("0x00000000000000000000000000000000000000a1"),
+		Receiver: common.HexToAddress("0x00000000000000000000000000000000000000b2"),
+		Token:    common.HexToAddress("0x00000000000000000000000000000000000000c3"),
+		Amount:   big.NewInt(1000),
+		Fee:      big.NewInt(7),
+	}
+	c := &Client{}
+	decode := func(ev relayEvent) relay.InMsg {
+		relayLog, err := c.ToRelayLog(packRelayEvent(t, ev))
+		if err != nil {
+			t.Fatalf("Failed to decode relay event: %v", err)
+		}
+		return relayLog.ToInMsg("1")
+	}
+	observed := decode(ev)
+	observed.Observer = "node-a"
+
+	if err := relay.NewSourceVerifier(receiptReader{log: decode(ev)}, 1).Verify(context.Background(), observed); err != nil {
+		t.Fatalf("Expected identical event to verify, got %v", err)
+	}
+
+	tampered := map[string]func(*relayEvent){
+		"nonce":    func(e *relayEvent) { e.Nonce = big.NewInt(43) },
+		"sender":   func(e *relayEvent) { e.Sender = common.HexToAddress("0x00000000000000000000000000000000000000d4") },
+		"receiver": func(e *relayEvent) { e.Receiver = common.HexToAddress("0x00000000000000000000000000000000000000d4") },
+		"token":    func(e *relayEvent) { e.Token = common.HexToAddress("0x00000000000000000000000000000000000000d4") },
+		"amount":   func(e *relayEvent) { e.Amount = big.NewInt(1000000) },
+		"fee_paid": func(e *relayEvent) { e.Fee = big.NewInt(0) },
+	}
+	for field, tamper := range tampered {
+		other := ev
+		tamper(&other)
+		err := relay.NewSourceVerifier(receiptReader{log: decode(other)}, 1).Verify(context.Background(), observed)
+		if !errors.Is(err, relay.ErrSourceMismatch) {
+			t.Errorf("Expected tampered %s to be rejected with %v, got %v", field, relay.ErrSourceMismatch, err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package bsc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+var _ relay.GasEstimator = (*Client)(nil)
+
+// EstimateRelayGas 以单条消息的 processBatch 调用估算中继所需的 gas
+func (c *Client) EstimateRelayGas(ctx context.Context, from string, msg relay.InMsg) (uint64, error) {
+	if !common.IsHexAddress(from) {
+		return 0, fmt.Errorf("invalid relayer address %q", from)
+	}
+	data, err := PackBatch(&relay.BatchMsg{FromNonce: msg.Nonce, ToNonce: msg.Nonce, Msgs: []relay.InMsg{msg}})
+	if err != nil {
+		return 0, err
+	}
+	return c.Client.EstimateGas(ctx, ethereum.CallMsg{From: common.HexToAddress(from), To: &c.Contract, Data: data})
+}
+
+// SuggestGasPrice 返回节点建议的 gas 价格
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return c.Client.SuggestGasPrice(ctx)
+}
@@ -0,0 +1,68 @@
+package bsc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/st-chain/me-bridge/relay"
+	"github.com/st-chain/me-bridge/signer"
+)
+
+var _ relay.ContractPauser = (*Client)(nil)
+
+// pauseABI 是跨链合约暂停方法的 ABI
+const pauseABI = `[{"type": "function", "name": "pause", "inputs": [], "outputs": []}]`
+
+var bridgePauseABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(pauseABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// PauseContract 使用运维密钥调用跨链合约 pause()，返回交易哈希，不等待交易打包
+func (c *Client) PauseContract(ctx context.Context, key signer.Signer) (string, error) {
+	data, err := bridgePauseABI.Pack("pause")
+	if err != nil {
+		return "", err
+	}
+	chainID, err := c.chainID()
+	if err != nil {
+		return "", err
+	}
+
+	from := common.HexToAddress(key.Address())
+	nonce, err := c.Client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return "", err
+	}
+	gasPrice, err := c.Client.SuggestGasPrice(ctx)
+	if err != nil {
+		return "", err
+	}
+	gas, err := c.Client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &c.Contract, Data: data})
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := signTx(ctx, key, types.NewTransaction(nonce, c.Contract, nil, gas, gasPrice, data), chainID)
+	if err != nil {
+		return "", err
+	}
+	if err := c.Client.SendTransaction(ctx, tx); err != nil {
+		return "", err
+	}
+
+	c.logger.Warn("Bridge contract pause submitted", map[string]any{
+		"contract": c.Contract.Hex(),
+		"operator": from.Hex(),
+		"tx_hash":  tx.Hash().Hex(),
+	})
+	return tx.Hash().Hex(), nil
+}
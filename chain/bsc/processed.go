@@ -0,0 +1,55 @@
+package bsc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+var _ relay.ProcessedChecker = (*Client)(nil)
+
+// processedABI 是跨链合约查询消息是否已处理的方法 ABI
+const processedABI = `[{
+	"type": "function",
+	"name": "isProcessed",
+	"stateMutability": "view",
+	"inputs": [{"name": "id", "type": "bytes32"}],
+	"outputs": [{"name": "", "type": "bool"}]
+}]`
+
+var bridgeProcessedABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(processedABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// IsProcessed 调用合约 isProcessed(id) 查询消息是否已处理，id 为 relay.MessageID 计算的确定性 ID
+func (c *Client) IsProcessed(ctx context.Context, id string) (bool, error) {
+	data, err := bridgeProcessedABI.Pack("isProcessed", common.HexToHash(id))
+	if err != nil {
+		return false, err
+	}
+
+	out, err := c.Client.CallContract(ctx, ethereum.CallMsg{To: &c.Contract, Data: data}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	values, err := bridgeProcessedABI.Unpack("isProcessed", out)
+	if err != nil {
+		return false, err
+	}
+	processed, ok := values[0].(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected isProcessed result %v", values[0])
+	}
+	return processed, nil
+}
@@ -0,0 +1,80 @@
+package bsc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/st-chain/me-bridge/signer"
+)
+
+// ErrInvalidSignature 签名无法恢复出签名器地址
+var ErrInvalidSignature = errors.New("signature does not match signer address")
+
+var secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
+// chainID 解析网络配置中的链 ID
+func (c *Client) chainID() (*big.Int, error) {
+	if c.Network == nil {
+		return nil, errors.New("network config missing")
+	}
+	id, ok := new(big.Int).SetString(c.Network.ChainID, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid chain id %q", c.Network.ChainID)
+	}
+	return id, nil
+}
+
+// signTx 使用 signer.Signer 对交易签名。
+// SignData 先对数据做 keccak256 再签名，因此传入 EIP-155 签名载荷的 RLP 编码，使签名摘要等于交易签名哈希；
+// 签名器只返回 R、S 时，规范化 S 并通过恢复地址确定 V
+func signTx(ctx context.Context, key signer.Signer, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	txSigner := types.NewEIP155Signer(chainID)
+	payload, err := rlp.EncodeToBytes([]any{
+		tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), chainID, uint(0), uint(0),
+	})
+	if err != nil {
+		return nil, err
+	}
+	hash := txSigner.Hash(tx)
+	if crypto.Keccak256Hash(payload) != hash {
+		return nil, errors.New("unexpected transaction signing payload")
+	}
+
+	sig, err := key.SignData(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) < 64 {
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+
+	// 以太坊要求 S 位于曲线阶的低半部分
+	s := new(big.Int).SetBytes(sig[32:64])
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s.Sub(crypto.S256().Params().N, s)
+	}
+	full := make([]byte, 65)
+	copy(full[:32], sig[:32])
+	s.FillBytes(full[32:64])
+
+	want := common.HexToAddress(key.Address())
+	for v := byte(0); v < 2; v++ {
+		full[64] = v
+		pub, err := crypto.Ecrecover(hash.Bytes(), full)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(crypto.Keccak256(pub[1:])[12:], want.Bytes()) {
+			return tx.WithSignature(txSigner, full)
+		}
+	}
+	return nil, ErrInvalidSignature
+}
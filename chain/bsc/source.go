@@ -0,0 +1,54 @@
+package bsc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+var _ relay.SourceReader = (*Client)(nil)
+
+// ReadSource 重新读取消息所在交易的收据，按日志序号查找跨链合约的跨链事件，
+// 并比较收据的区块哈希与节点主链上同高度的区块哈希判断是否已被重组
+func (c *Client) ReadSource(ctx context.Context, msg relay.InMsg) (*relay.SourceReceipt, error) {
+	latest, err := c.Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &relay.SourceReceipt{Latest: latest}
+
+	receipt, err := c.Client.TransactionReceipt(ctx, common.HexToHash(msg.TxHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Found = true
+	result.Succeeded = receipt.Status == 1
+	result.Height = receipt.BlockNumber.Uint64()
+
+	header, err := c.Client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	result.Canonical = header.Hash() == receipt.BlockHash
+
+	for _, vLog := range receipt.Logs {
+		if vLog.Index != msg.LogIndex || vLog.Address != c.Contract || len(vLog.Topics) == 0 || vLog.Topics[0] != RelayTopic[0][0] {
+			continue
+		}
+		relayLog, err := c.ToRelayLog(*vLog)
+		if err != nil {
+			return nil, err
+		}
+		event := relayLog.ToInMsg(msg.ChainID)
+		result.Log = &event
+		break
+	}
+	return result, nil
+}
@@ -0,0 +1,186 @@
+package bsc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/st-chain/me-bridge/relay"
+	bridgetypes "github.com/st-chain/me-bridge/types"
+)
+
+const (
+	// DefaultReceiptTimeout 是每次等待中继交易打包的最长时间，超时后以相同 nonce 提高 gas 价格替换交易
+	DefaultReceiptTimeout = 2 * time.Minute
+	// maxReplacements 是交易未打包时以相同 nonce 替换的最多次数
+	maxReplacements = 3
+	// receiptPollInterval 是轮询交易收据的间隔
+	receiptPollInterval = time.Second
+)
+
+// sendTx 使用中继密钥签名并发送调用跨链合约的交易，等待交易打包后返回收据。
+// gas 价格取节点建议值，gas 上限取估算值，policy 不为 nil 时按运维指定的策略覆盖。
+// 交易广播后不再分配新的 nonce：超时未打包时以相同 nonce 提高 gas 价格替换，替换次数用尽后返回
+// relay.ErrSubmissionPending，调用方不能重新提交
+func (c *Client) sendTx(ctx context.Context, data []byte, policy *bridgetypes.FeePolicy) (*types.Receipt, error) {
+	if c.Key == nil {
+		return nil, ErrNoSigner
+	}
+	chainID, err := c.chainID()
+	if err != nil {
+		return nil, err
+	}
+
+	from := common.HexToAddress(c.Key.Address())
+	nonce, err := c.Client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := c.Client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var gas uint64
+	if policy == nil || policy.GasLimit == 0 {
+		if gas, err = c.Client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &c.Contract, Data: data}); err != nil {
+			return nil, err
+		}
+	}
+	if gasPrice, gas, err = applyFeePolicy(policy, gasPrice, gas); err != nil {
+		return nil, err
+	}
+
+	tx, err := signTx(ctx, c.Key, types.NewTransaction(nonce, c.Contract, nil, gas, gasPrice, data), chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Client.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	c.logger.Debug("Relay transaction sent", map[string]any{
+		"tx_hash":   tx.Hash().Hex(),
+		"nonce":     nonce,
+		"gas":       gas,
+		"gas_price": gasPrice,
+	})
+
+	// 已广播的交易都可能打包，等待其中任意一笔的收据
+	sent := []common.Hash{tx.Hash()}
+	for replaced := 0; ; replaced++ {
+		waitCtx, cancel := context.WithTimeout(ctx, DefaultReceiptTimeout)
+		receipt, err := c.waitReceipt(waitCtx, sent)
+		cancel()
+		if err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return receipt, fmt.Errorf("%w: %s reverted", ErrTransactionFailed, receipt.TxHash.Hex())
+			}
+			return receipt, nil
+		}
+		if ctx.Err() != nil || replaced >= maxReplacements {
+			return nil, fmt.Errorf("%w: %s nonce %d: %w", relay.ErrSubmissionPending, sent[len(sent)-1].Hex(), nonce, err)
+		}
+
+		bumped, ok := bumpGasPrice(gasPrice, gas, policy)
+		if !ok {
+			// 手续费策略不允许继续提高 gas 价格，只等待已广播的交易
+			continue
+		}
+		replacement, err := signTx(ctx, c.Key, types.NewTransaction(nonce, c.Contract, nil, gas, bumped, data), chainID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s nonce %d: %w", relay.ErrSubmissionPending, sent[len(sent)-1].Hex(), nonce, err)
+		}
+		if err := c.Client.SendTransaction(ctx, replacement); err != nil {
+			// 原交易可能已打包（nonce too low），继续等待已广播的交易
+			c.logger.Warn("failed to replace pending relay transaction", map[string]any{
+				"tx_hash": sent[len(sent)-1].Hex(),
+				"nonce":   nonce,
+				"error":   err,
+			})
+			continue
+		}
+		gasPrice = bumped
+		sent = append(sent, replacement.Hash())
+		c.logger.Warn("relay transaction not mined, replaced with higher gas price", map[string]any{
+			"tx_hash":   replacement.Hash().Hex(),
+			"nonce":     nonce,
+			"gas_price": gasPrice,
+		})
+	}
+}
+
+// waitReceipt 轮询 hashes 中任意一笔交易的收据，直到找到或 ctx 结束
+func (c *Client) waitReceipt(ctx context.Context, hashes []common.Hash) (*types.Receipt, error) {
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+	for {
+		for _, hash := range hashes {
+			receipt, err := c.Client.TransactionReceipt(ctx, hash)
+			if err == nil {
+				return receipt, nil
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				c.logger.Debug("failed to get relay transaction receipt", map[string]any{"tx_hash": hash.Hex(), "error": err})
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// bumpGasPrice 返回替换交易的 gas 价格：在原价格上提高 1/8（节点接受替换的最低幅度为 10%）。
+// 手续费策略指定了 gas 价格或 max_fee 不允许更高的价格时返回 false
+func bumpGasPrice(gasPrice *big.Int, gas uint64, policy *bridgetypes.FeePolicy) (*big.Int, bool) {
+	if policy != nil && policy.GasPrice != "" {
+		return nil, false
+	}
+	bumped := new(big.Int).Add(gasPrice, new(big.Int).Add(new(big.Int).Rsh(gasPrice, 3), big.NewInt(1)))
+	if policy != nil && policy.MaxFee != "" {
+		maxFee, ok := math.ParseBig256(policy.MaxFee)
+		if !ok || new(big.Int).Mul(bumped, new(big.Int).SetUint64(gas)).Cmp(maxFee) > 0 {
+			return nil, false
+		}
+	}
+	return bumped, true
+}
+
+// applyFeePolicy 按运维指定的手续费策略调整 gas 价格和上限：gas_price、gas_limit 直接覆盖，
+// max_fee 限制 gas 价格 × gas 上限，超出时降低 gas 价格
+func applyFeePolicy(policy *bridgetypes.FeePolicy, gasPrice *big.Int, gas uint64) (*big.Int, uint64, error) {
+	if policy == nil {
+		return gasPrice, gas, nil
+	}
+	if policy.GasPrice != "" {
+		price, ok := math.ParseBig256(policy.GasPrice)
+		if !ok || price.Sign() <= 0 {
+			return nil, 0, fmt.Errorf("%w: gas price %q", ErrInvalidTransaction, policy.GasPrice)
+		}
+		gasPrice = price
+	}
+	if policy.GasLimit > 0 {
+		gas = policy.GasLimit
+	}
+	if policy.MaxFee != "" {
+		maxFee, ok := math.ParseBig256(policy.MaxFee)
+		if !ok || maxFee.Sign() <= 0 {
+			return nil, 0, fmt.Errorf("%w: max fee %q", ErrInvalidTransaction, policy.MaxFee)
+		}
+		limit := new(big.Int).SetUint64(gas)
+		if new(big.Int).Mul(gasPrice, limit).Cmp(maxFee) > 0 {
+			gasPrice = new(big.Int).Div(maxFee, limit)
+			if gasPrice.Sign() == 0 {
+				return nil, 0, fmt.Errorf("%w: max fee %s below gas limit %d", ErrInvalidTransaction, policy.MaxFee, gas)
+			}
+		}
+	}
+	return gasPrice, gas, nil
+}
@@ -0,0 +1,73 @@
+package bsc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	bridgetypes "github.com/st-chain/me-bridge/types"
+)
+
+func TestApplyFeePolicy(t *testing.T) {
+	suggested := big.NewInt(3_000_000_000)
+
+	tests := []struct {
+		name     string
+		policy   *bridgetypes.FeePolicy
+		gasPrice int64
+		gas      uint64
+		err      error
+	}{
+		{"no policy", nil, 3_000_000_000, 100_000, nil},
+		{"gas price override", &bridgetypes.FeePolicy{GasPrice: "5000000000"}, 5_000_000_000, 100_000, nil},
+		{"gas limit override", &bridgetypes.FeePolicy{GasLimit: 250_000}, 3_000_000_000, 250_000, nil},
+		{"max fee above cost", &bridgetypes.FeePolicy{MaxFee: "1000000000000000"}, 3_000_000_000, 100_000, nil},
+		{"max fee caps gas price", &bridgetypes.FeePolicy{GasPrice: "5000000000", MaxFee: "200000000000000"}, 2_000_000_000, 100_000, nil},
+		{"max fee below gas limit", &bridgetypes.FeePolicy{MaxFee: "1000"}, 0, 0, ErrInvalidTransaction},
+		{"invalid gas price", &bridgetypes.FeePolicy{GasPrice: "fast"}, 0, 0, ErrInvalidTransaction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gasPrice, gas, err := applyFeePolicy(tt.policy, suggested, 100_000)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil || gasPrice.Int64() != tt.gasPrice || gas != tt.gas {
+				t.Errorf("Expected %d × %d, got %v × %d, %v", tt.gasPrice, tt.gas, gasPrice, gas, err)
+			}
+		})
+	}
+}
+
+func TestBumpGasPrice(t *testing.T) {
+	gasPrice := big.NewInt(8_000_000_000)
+
+	tests := []struct {
+		name   string
+		policy *bridgetypes.FeePolicy
+		want   int64 // 0 表示不允许替换
+	}{
+		{"no policy", nil, 9_000_000_001},
+		{"gas limit only", &bridgetypes.FeePolicy{GasLimit: 100_000}, 9_000_000_001},
+		{"fixed gas price", &bridgetypes.FeePolicy{GasPrice: "8000000000"}, 0},
+		{"max fee allows bump", &bridgetypes.FeePolicy{MaxFee: "1000000000000000"}, 9_000_000_001},
+		{"max fee reached", &bridgetypes.FeePolicy{MaxFee: "800000000000000"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bumped, ok := bumpGasPrice(gasPrice, 100_000, tt.policy)
+			if tt.want == 0 {
+				if ok {
+					t.Fatalf("Expected no replacement, got %v", bumped)
+				}
+				return
+			}
+			if !ok || bumped.Int64() != tt.want {
+				t.Errorf("Expected %d, got %v, %v", tt.want, bumped, ok)
+			}
+		})
+	}
+}
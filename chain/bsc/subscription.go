@@ -2,6 +2,7 @@ package bsc
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"time"
 
@@ -16,7 +17,7 @@ import (
 
 // BSC 跨链事件订阅主题
 var RelayTopic = [][]common.Hash{
-	{common.HexToHash("")},
+	{bridgeEventABI.Events["Relay"].ID},
 }
 
 // TrackHeight tracks the latest block height
@@ -52,12 +53,25 @@ func (c *Client) TrackHeight() error {
 	return nil
 }
 
+// ToRelayLog 解码跨链合约的跨出事件
 func (c *Client) ToRelayLog(vLog types.Log) (*chain.RelayLog, error) {
+	ev, err := unpackRelayEvent(vLog)
+	if err != nil {
+		return nil, err
+	}
+	if !ev.Nonce.IsUint64() {
+		return nil, fmt.Errorf("%w: log %s:%d: nonce %s overflows uint64", relay.ErrInvalidMessage, vLog.TxHash.Hex(), vLog.Index, ev.Nonce)
+	}
 	relayLog := &chain.RelayLog{
 		TxHash:   vLog.TxHash.Hex(),
-		Sender:   "", // 需要解析Data字段获取
-		Receiver: "", // 需要解析Data字段获取
-		Amount:   "", // 需要解析Data字段获取
+		LogIndex: vLog.Index,
+		Height:   vLog.BlockNumber,
+		Sender:   ev.Sender.Hex(),
+		Receiver: ev.Receiver.Hex(),
+		Token:    ev.Token.Hex(),
+		Amount:   ev.Amount.String(),
+		Fee:      ev.Fee.String(),
+		Nonce:    ev.Nonce.Uint64(),
 	}
 	return relayLog, nil
 }
@@ -153,7 +167,7 @@ func (c *Client) ProcessRelayMsgs(relayMsgs <-chan relay.Message) error {
 }
 
 // ProcessInMsgs 处理跨链消息，返回错误通道供 Tunnel 监听
-func (c *Client) ProcessInMsgs(msgs <-chan relay.InMsg) <-chan relay.ProcessError {
+func (c *Client) ProcessInMsgs(msgs <-chan relay.InMsg) (<-chan relay.ProcessError, error) {
 	errorChan := make(chan relay.ProcessError, 10)
 
 	go func() {
@@ -190,22 +204,17 @@ func (c *Client) ProcessInMsgs(msgs <-chan relay.InMsg) <-chan relay.ProcessErro
 		}
 	}()
 
-	return errorChan
+	return errorChan, nil
 }
 
-// processMessage 处理单个跨链消息
+// processMessage 处理单个跨链消息，以只含一条消息的 processBatch 调用提交
 func (c *Client) processMessage(msg relay.InMsg) error {
 	c.logger.Info("Processing cross-chain message", map[string]any{
 		"msg": msg,
 	})
 
-	// TODO: 实现具体的交易发送逻辑
-	// 1. 构造交易
-	// 2. 签名交易
-	// 3. 发送交易
-	// 4. 处理发送结果
-
-	return nil // 临时返回，实际实现时需要处理具体逻辑
+	batch := relay.NewBatchMsg([]relay.InMsg{msg})
+	return c.ProcessBatch(context.Background(), &batch)
 }
 
 // retryMessage 重试消息处理
@@ -21,9 +21,13 @@ type OutClient interface {
 // RelayLog 表示跨链日志事件
 type RelayLog struct {
 	TxHash   string `json:"tx_hash"`
+	LogIndex uint   `json:"log_index"` // 日志在区块中的序号
+	Height   uint64 `json:"height"`    // 日志所在区块
 	Sender   string `json:"sender"`
 	Receiver string `json:"receiver"`
+	Token    string `json:"token,omitempty"` // 源端代币地址
 	Amount   string `json:"amount"`
+	Fee      string `json:"fee,omitempty"` // 源端事件携带的手续费
 	Nonce    uint64 `json:"nonce"`
 }
 
@@ -32,3 +36,19 @@ func (r *RelayLog) GetTxHash() string   { return r.TxHash }
 func (r *RelayLog) GetSender() string   { return r.Sender }
 func (r *RelayLog) GetReceiver() string { return r.Receiver }
 func (r *RelayLog) GetAmount() string   { return r.Amount }
+
+// ToInMsg 将源端日志转换为跨入消息，chainID 为源链 ID，用于计算确定性消息 ID
+func (r *RelayLog) ToInMsg(chainID string) relay.InMsg {
+	return relay.InMsg{
+		Nonce:    r.Nonce,
+		ChainID:  chainID,
+		Height:   r.Height,
+		TxHash:   r.TxHash,
+		LogIndex: r.LogIndex,
+		Sender:   r.Sender,
+		Receiver: r.Receiver,
+		Token:    r.Token,
+		Amount:   r.Amount,
+		FeePaid:  r.Fee,
+	}
+}
@@ -8,26 +8,61 @@ import (
 
 	"github.com/st-chain/me-bridge/log"
 	"github.com/st-chain/me-bridge/relay"
+	"github.com/st-chain/me-bridge/types"
 )
 
 // Cluster manages a set of blockchain clients and provides the "best" current client.
 // T must implement Client (LatestHeight). Concrete endpoints will additionally
 // constrain T to relay.InEndpoint or relay.OutEndpoint via type aliases in endpoint.go.
+// The best client is chosen by a health score combining height lag, request
+// latency, error rate and subscription liveness. Each client is guarded by a
+// circuit breaker that quarantines it after repeated failures.
 type Cluster[T Client] struct {
 	mu              sync.RWMutex
-	clients         []T
-	current         T
+	reconcileMu     sync.Mutex // 串行化 Reconcile，避免并发调用重复添加同一节点
+	members         []*member[T]
+	current         *member[T]
+	bestHeight      int64
+	updatedAt       time.Time
+	health          HealthConfig
+	breaker         BreakerConfig
+	onSwitch        []SwitchFunc[T]
+	drainTimeout    time.Duration
 	monitorInterval time.Duration
 	stopCh          chan struct{}
 	logger          *log.Logger
 	errorHandler    *relay.ErrorHandler
 }
 
+// member 是集群中的一个节点及其健康指标
+type member[T Client] struct {
+	client  T
+	name    string
+	config  string // 建立连接时使用的节点配置摘要，NewCluster 创建的节点为空
+	health  nodeHealth
+	breaker breaker
+}
+
+// namer 由可以提供节点名称的客户端实现
+type namer interface {
+	Name() string
+}
+
+func newMember[T Client](client T, index int) *member[T] {
+	name := fmt.Sprintf("node-%d", index)
+	if n, ok := any(client).(namer); ok && n.Name() != "" {
+		name = n.Name()
+	}
+	return &member[T]{client: client, name: name}
+}
+
 // NewCluster creates a new cluster with given clients and monitor interval.
-// It picks an initial current client based on the highest LatestHeight.
+// It picks an initial current client based on the health score.
 func NewCluster[T Client](clients []T, monitorInterval time.Duration) *Cluster[T] {
 	c := &Cluster[T]{
-		clients:         append([]T(nil), clients...),
+		health:          DefaultHealthConfig(),
+		breaker:         DefaultBreakerConfig(),
+		drainTimeout:    DefaultDrainTimeout,
 		monitorInterval: monitorInterval,
 		stopCh:          make(chan struct{}),
 		logger:          log.WithComponent("cluster"),
@@ -37,30 +72,90 @@ func NewCluster[T Client](clients []T, monitorInterval time.Duration) *Cluster[T
 			RetryDelay: time.Second * 2,
 		},
 	}
+	for i, cl := range clients {
+		c.members = append(c.members, newMember(cl, i))
+	}
 	c.recomputeBest()
 	return c
 }
 
+// SetHealthConfig replaces the health scoring parameters.
+func (c *Cluster[T]) SetHealthConfig(cfg HealthConfig) {
+	c.mu.Lock()
+	c.health = cfg
+	c.mu.Unlock()
+}
+
+// SetBreakerConfig replaces the circuit breaker parameters.
+func (c *Cluster[T]) SetBreakerConfig(cfg BreakerConfig) {
+	c.mu.Lock()
+	c.breaker = cfg
+	c.mu.Unlock()
+}
+
 // Current returns the current best client.
 func (c *Cluster[T]) Current() T {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.current
+	if c.current == nil {
+		var zero T
+		return zero
+	}
+	return c.current.client
 }
 
 // Clients returns a copy of the current client list.
 func (c *Cluster[T]) Clients() []T {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	out := make([]T, len(c.clients))
-	copy(out, c.clients)
+	out := make([]T, len(c.members))
+	for i, m := range c.members {
+		out[i] = m.client
+	}
 	return out
 }
 
+// Size returns the number of configured members, including quarantined ones.
+func (c *Cluster[T]) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.members)
+}
+
+// NameOf returns the name of the member holding client, or "" if the client is not in the cluster.
+func (c *Cluster[T]) NameOf(client T) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, m := range c.members {
+		if any(m.client) == any(client) {
+			return m.name
+		}
+	}
+	return ""
+}
+
+// Other returns the healthiest non-quarantined client whose name is not exclude,
+// used to cross-check data that was observed on a single node.
+func (c *Cluster[T]) Other(exclude string) (T, string, bool) {
+	for _, m := range c.candidates(0) {
+		if m.name != exclude {
+			return m.client, m.name, true
+		}
+	}
+	var zero T
+	return zero, "", false
+}
+
 // SetClients replaces the client set and recomputes the best current client.
 func (c *Cluster[T]) SetClients(clients []T) {
+	members := make([]*member[T], len(clients))
+	for i, cl := range clients {
+		members[i] = newMember(cl, i)
+	}
+
 	c.mu.Lock()
-	c.clients = append([]T(nil), clients...)
+	c.members = members
+	c.current = nil
 	c.mu.Unlock()
 	c.recomputeBest()
 }
@@ -71,7 +166,109 @@ func (c *Cluster[T]) ReplaceClient() T {
 	return c.Current()
 }
 
-// Start begins background monitoring to automatically select the freshest client.
+// Observe records the latency and result of a request made against the current client.
+func (c *Cluster[T]) Observe(latency time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.current != nil {
+		c.current.health.observe(c.health, latency, err)
+		c.recordBreaker(c.current, err, time.Now())
+	}
+}
+
+// recordBreaker feeds a request result into the member's circuit breaker and
+// logs quarantine transitions. Callers must hold c.mu.
+func (c *Cluster[T]) recordBreaker(m *member[T], err error, now time.Time) {
+	if err == nil {
+		if m.breaker.recordSuccess(c.breaker, now) {
+			c.logger.Info("node reinstated from quarantine", map[string]any{
+				"node":  m.name,
+				"trips": m.breaker.trips,
+			})
+		}
+		return
+	}
+
+	if m.breaker.recordFailure(c.breaker, now) {
+		c.logger.Warn("node quarantined", map[string]any{
+			"node":  m.name,
+			"trips": m.breaker.trips,
+			"until": m.breaker.until,
+			"error": err,
+		})
+	}
+}
+
+// scoreOf returns the member's health score; quarantined members score 0.
+// Callers must hold c.mu.
+func (c *Cluster[T]) scoreOf(m *member[T], now time.Time) float64 {
+	if m.breaker.state != BreakerClosed {
+		return 0
+	}
+	return m.health.score(c.health, c.bestHeight, now)
+}
+
+// Subscribed marks the current client as carrying an active subscription.
+func (c *Cluster[T]) Subscribed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.current != nil {
+		c.current.health.subscribed = true
+		c.current.health.lastEvent = time.Now()
+	}
+}
+
+// Heartbeat records that a subscription event was received from the current client.
+func (c *Cluster[T]) Heartbeat() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.current != nil {
+		c.current.health.lastEvent = time.Now()
+	}
+}
+
+// Status returns a snapshot of the health of every client in the cluster.
+func (c *Cluster[T]) Status() ClusterStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	status := ClusterStatus{
+		Current:   -1,
+		Height:    c.bestHeight,
+		Nodes:     make([]NodeStatus, 0, len(c.members)),
+		UpdatedAt: c.updatedAt,
+	}
+	for i, m := range c.members {
+		node := NodeStatus{
+			Index:             i,
+			Name:              m.name,
+			Current:           m == c.current,
+			Height:            m.health.height,
+			HeightLag:         c.bestHeight - m.health.height,
+			Latency:           m.health.latency,
+			ErrorRate:         m.health.errorRate,
+			Reachable:         m.health.reachable,
+			SubscriptionAlive: m.health.subscriptionAlive(c.health, now),
+			Score:             c.scoreOf(m, now),
+			Breaker:           m.breaker.state.String(),
+			Trips:             m.breaker.trips,
+		}
+		if m.breaker.state == BreakerOpen {
+			node.QuarantinedUntil = m.breaker.until
+		}
+		if m.health.lastErr != nil {
+			node.LastError = m.health.lastErr.Error()
+		}
+		if node.Current {
+			status.Current = i
+		}
+		status.Nodes = append(status.Nodes, node)
+	}
+	return status
+}
+
+// Start begins background monitoring to automatically select the healthiest client.
 func (c *Cluster[T]) Start() {
 	if c.monitorInterval <= 0 {
 		return
@@ -103,46 +300,139 @@ func (c *Cluster[T]) monitorLoop() {
 	}
 }
 
-// recomputeBest selects the client with the highest latest height.
+// probeResult 是一次 LatestHeight 探测的结果
+type probeResult struct {
+	height  int64
+	latency time.Duration
+	err     error
+}
+
+// probe 并发探测所有节点的最新高度和延迟
+func (c *Cluster[T]) probe(members []*member[T]) []probeResult {
+	results := make([]probeResult, len(members))
+
+	var wg sync.WaitGroup
+	for i, m := range members {
+		wg.Add(1)
+		go func(i int, cl T) {
+			defer wg.Done()
+			start := time.Now()
+			h, err := cl.LatestHeight()
+			results[i] = probeResult{height: h, latency: time.Since(start), err: err}
+		}(i, m.client)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// recomputeBest probes every client that is not quarantined, updates its
+// health and selects the highest scoring one. Clients whose quarantine has
+// expired are probed in half-open state and reinstated on success. The
+// current client is only replaced when a candidate beats it by more than
+// HealthConfig.SwitchMargin, to avoid flapping.
 func (c *Cluster[T]) recomputeBest() {
-	c.mu.RLock()
-	clients := append([]T(nil), c.clients...)
-	c.mu.RUnlock()
+	now := time.Now()
+
+	c.mu.Lock()
+	var members []*member[T]
+	for _, m := range c.members {
+		if m.breaker.probeable(now) {
+			members = append(members, m)
+		}
+	}
+	c.mu.Unlock()
 
-	if len(clients) == 0 {
+	results := c.probe(members)
+
+	c.mu.Lock()
+	prev, next := c.selectBest(members, results)
+	callbacks := append([]SwitchFunc[T](nil), c.onSwitch...)
+	c.mu.Unlock()
+
+	if next == nil || prev == next {
 		return
 	}
+	var prevClient T
+	if prev != nil {
+		prevClient = prev.client
+	}
+	for _, fn := range callbacks {
+		fn(prevClient, next.client)
+	}
+}
 
-	var (
-		best    T
-		bestH   int64 = -1
-		bestErr error
-	)
+// selectBest applies probe results and updates the current member. It returns
+// the previous and new current member. Callers must hold c.mu.
+func (c *Cluster[T]) selectBest(members []*member[T], results []probeResult) (prev, next *member[T]) {
+	now := time.Now()
+	c.updatedAt = now
+	prev = c.current
 
-	for _, cl := range clients {
-		h, err := cl.LatestHeight()
-		if err != nil {
-			bestErr = err
-			c.logger.Debug("latest height error", map[string]any{"error": err})
+	bestHeight := int64(-1)
+	for i, m := range members {
+		r := results[i]
+		m.health.observe(c.health, r.latency, r.err)
+		m.health.reachable = r.err == nil
+		c.recordBreaker(m, r.err, now)
+		if r.err != nil {
+			c.logger.Debug("latest height error", map[string]any{"node": m.name, "error": r.err})
 			continue
 		}
-		if h > bestH {
-			bestH = h
-			best = cl
+		m.health.height = r.height
+		if r.height > bestHeight {
+			bestHeight = r.height
 		}
 	}
 
-	if bestH < 0 {
-		// all failed; keep current but log once
-		if bestErr != nil {
+	if bestHeight < 0 {
+		// all failed or quarantined; keep current
+		if len(c.members) > 0 {
 			c.logger.Warn("no healthy client in cluster", nil)
 		}
-		return
+		return prev, prev
 	}
+	c.bestHeight = bestHeight
 
-	c.mu.Lock()
+	var (
+		best      *member[T]
+		bestScore = -1.0
+		isMember  = false
+	)
+	for _, m := range c.members {
+		if m == c.current {
+			isMember = true
+		}
+		if m.breaker.state != BreakerClosed {
+			continue
+		}
+		if s := c.scoreOf(m, now); s > bestScore {
+			best, bestScore = m, s
+		}
+	}
+	if best == nil {
+		return prev, prev
+	}
+
+	// 当前节点已被移除时无条件切换
+	if c.current != nil && c.current != best && isMember {
+		curScore := c.scoreOf(c.current, now)
+		if curScore > 0 && bestScore-curScore <= c.health.SwitchMargin {
+			return prev, prev
+		}
+		c.logger.Info("switching current client", map[string]any{
+			"from":       c.current.name,
+			"from_score": curScore,
+			"to":         best.name,
+			"to_score":   bestScore,
+		})
+	}
+	// 订阅随切换迁移到新节点，旧节点不再因订阅失活被扣分
+	if prev != nil && prev != best {
+		prev.health.subscribed = false
+	}
 	c.current = best
-	c.mu.Unlock()
+	return prev, best
 }
 
 // HandleError 处理集群级别的错误
@@ -152,6 +442,23 @@ func (c *Cluster[T]) HandleError(ctx context.Context, err error, metadata map[st
 		"metadata": metadata,
 	})
 
+	// 只有连接和超时错误计入当前节点的失败，合约回滚、nonce 等应用错误与节点健康无关。
+	// 连续失败达到阈值后熔断隔离
+	c.mu.Lock()
+	quarantined := false
+	if c.current != nil && c.isConnectionError(err) {
+		c.current.health.observeResult(c.health, err)
+		c.recordBreaker(c.current, err, time.Now())
+		quarantined = c.current.breaker.state == BreakerOpen
+	}
+	c.mu.Unlock()
+
+	// 当前节点已被隔离，立即切换
+	if quarantined {
+		c.ReplaceClient()
+		return nil
+	}
+
 	// 使用基础错误处理器
 	handleErr := c.errorHandler.HandleError(ctx, err, metadata)
 
@@ -165,21 +472,7 @@ func (c *Cluster[T]) HandleError(ctx context.Context, err error, metadata map[st
 	return handleErr
 }
 
-// isConnectionError 判断是否是连接相关的错误
+// isConnectionError 判断是否是应切换节点的连接类错误
 func (c *Cluster[T]) isConnectionError(err error) bool {
-	errMsg := err.Error()
-	connectionPatterns := []string{
-		"connection refused", "timeout", "network", "dial",
-		"eof", "broken pipe", "connection reset",
-	}
-
-	for _, pattern := range connectionPatterns {
-		if fmt.Sprintf("%v", errMsg) != "" &&
-			len(errMsg) > 0 &&
-			fmt.Sprintf("%s", pattern) != "" {
-			// 简化的字符串检查，避免复杂的依赖
-			return true
-		}
-	}
-	return false
+	return types.IsConnectionError(err)
 }
@@ -0,0 +1,20 @@
+package chain
+
+// NetworkConfig 定义区块链网络配置
+type NetworkConfig struct {
+	Name          string          `yaml:"network" json:"network"`               // 网络名称，如 "ethereum", "bsc", "tron"
+	ChainID       string          `yaml:"chain_id" json:"chain_id"`             // 链 ID
+	MaxConns      int32           `yaml:"max_conns" json:"max_conns"`           // 最大连接数
+	Timeout       int64           `yaml:"timeout" json:"timeout"`               // 连接超时时间（毫秒）
+	MaxRetries    int32           `yaml:"max_retries" json:"max_retries"`       // 最大重试次数
+	RetryInterval int64           `yaml:"retry_interval" json:"retry_interval"` // 重试间隔（毫秒）
+	ClientConfigs []*ClientConfig `yaml:"target_configs" json:"target_configs"` // 节点配置列表
+}
+
+// ClientConfig 定义单个节点配置，Name 在同一网络内唯一，用于识别集群成员
+type ClientConfig struct {
+	Name    string `yaml:"name" json:"name"`         // 节点名称
+	GRPCURL string `yaml:"grpc_url" json:"grpc_url"` // gRPC 地址
+	RPCURL  string `yaml:"rpc_url" json:"rpc_url"`   // RPC 地址
+	WSURL   string `yaml:"ws_url" json:"ws_url"`     // WebSocket 地址
+}
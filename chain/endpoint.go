@@ -1,22 +1,70 @@
 package chain
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
 	"time"
 
+	"github.com/st-chain/me-bridge/log"
 	"github.com/st-chain/me-bridge/relay"
+	"github.com/st-chain/me-bridge/signer"
 )
 
+// ErrNoDialer 端点未配置节点拨号函数，无法在运行时增加节点
+var ErrNoDialer = errors.New("endpoint has no dialer")
+
+// ErrProcessedUnsupported 节点客户端不支持查询消息是否已处理
+var ErrProcessedUnsupported = errors.New("processed check not supported")
+
+// ErrPauseUnsupported 节点客户端不支持暂停跨链合约
+var ErrPauseUnsupported = errors.New("contract pause not supported")
+
+// ErrSourceUnsupported 节点客户端不支持重新读取源端交易收据
+var ErrSourceUnsupported = errors.New("source receipt not supported")
+
+// ErrNoOtherNode 集群中没有观察节点之外的可用节点，无法核对源端事件
+var ErrNoOtherNode = errors.New("no other node available")
+
+// ErrBalanceUnsupported 节点客户端不支持查询账户余额
+var ErrBalanceUnsupported = errors.New("balance query not supported")
+
 // InEndpoint 实现 relay.InEndpoint 接口，通过 Cluster 统一管理多个节点。
 type InEndpoint struct {
-	Config  *relay.EndpointConfig `json:"config"`
+	Config  *relay.EndpointConfig                 `json:"config"`
+	Quorum  QuorumConfig                          `json:"quorum"` // 关键查询的多节点一致性参数
+	Dialer  func(*ClientConfig) (InClient, error) `json:"-"`      // 运行时新增节点时的拨号函数
 	cluster *Cluster[InClient]
+
+	mu     sync.Mutex
+	sub    *inSubscription
+	logger *log.Logger
 }
 
-// LatestHeight 返回当前终端的最新区块高度
-func (e *InEndpoint) LatestHeight() (int64, error) { return e.cluster.Current().LatestHeight() }
+// inSubscription 记录跨入消息订阅，用于切换节点后重新订阅并回补遗漏的消息
+type inSubscription struct {
+	out        chan relay.InMsg // 上层消费的通道
+	lastHeight uint64           // 已转发消息的最高区块
+	stop       chan struct{}    // 停止转发当前节点的消息
+}
+
+// LatestHeight 返回当前终端的最新区块高度，并记录请求延迟用于节点健康评分
+func (e *InEndpoint) LatestHeight() (int64, error) {
+	start := time.Now()
+	h, err := e.cluster.Current().LatestHeight()
+	e.cluster.Observe(time.Since(start), err)
+	return h, err
+}
 
 // Status 返回当前终端的可用性状态信息
-func (e *InEndpoint) Status() map[string]any { return nil }
+func (e *InEndpoint) Status() map[string]any {
+	return map[string]any{
+		"network": e.Config.Network,
+		"cluster": e.cluster.Status(),
+	}
+}
 
 func (e *InEndpoint) GetClient() InClient { return e.cluster.Current() }
 
@@ -26,45 +74,308 @@ func (e *InEndpoint) ReplaceClient() InClient { return e.cluster.ReplaceClient()
 // Monitor 监控节点的最新区块，以此判断节点的数据新鲜度和可用性
 func (e *InEndpoint) Monitor() { e.cluster.Start() }
 
-// AutoUpdate 自动定期更新可用节点
-func (e *InEndpoint) AutoUpdate() {}
+// UpdateNodes 将集群节点更新为 configs，新增节点通过 Dialer 建立连接，移除的节点在排空后关闭
+func (e *InEndpoint) UpdateNodes(configs []*ClientConfig) error {
+	if e.Dialer == nil {
+		return ErrNoDialer
+	}
+	_, _, err := e.cluster.Reconcile(nodeSpecs(configs, e.Dialer))
+	return err
+}
+
+// AutoUpdate 按 interval 定期通过 load 获取节点列表并更新集群成员
+func (e *InEndpoint) AutoUpdate(load func() ([]*ClientConfig, error), interval time.Duration) {
+	go autoUpdate(e.cluster, e.UpdateNodes, load, interval, e.logger)
+}
+
+// SubscribeToInMsgs 在当前节点上订阅跨入消息，节点切换后自动在新节点上重新订阅
+func (e *InEndpoint) SubscribeToInMsgs(msgs chan relay.InMsg) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	client := e.GetClient()
+	sub := &inSubscription{out: msgs}
+	if h, err := client.LatestHeight(); err == nil && h > 0 {
+		sub.lastHeight = uint64(h)
+	}
+	if err := e.subscribe(client, sub); err != nil {
+		return err
+	}
+	e.sub = sub
+	return nil
+}
+
+// subscribe 在 client 上建立订阅并将消息转发到 sub.out，调用方需持有 e.mu
+func (e *InEndpoint) subscribe(client InClient, sub *inSubscription) error {
+	in := make(chan relay.InMsg, cap(sub.out))
+	if err := client.SubscribeToInMsgs(in); err != nil {
+		return err
+	}
+	if sub.stop != nil {
+		close(sub.stop)
+	}
+	sub.stop = make(chan struct{})
+	e.cluster.Subscribed()
+
+	go e.forward(sub, in, e.cluster.NameOf(client), sub.stop)
+	return nil
+}
+
+// forward 将节点订阅的消息转发给上层，并记录订阅心跳、最高区块和观察到消息的节点
+func (e *InEndpoint) forward(sub *inSubscription, in chan relay.InMsg, node string, stop <-chan struct{}) {
+	for {
+		select {
+		case msg, ok := <-in:
+			if !ok {
+				// 节点关闭了订阅通道，由健康检查发现订阅失活后切换节点
+				return
+			}
+			msg.Observer = node
+			e.cluster.Heartbeat()
+			e.mu.Lock()
+			sub.lastHeight = max(sub.lastHeight, msg.Height)
+			e.mu.Unlock()
+
+			select {
+			case sub.out <- msg:
+			case <-stop:
+				return
+			}
+		case <-stop:
+			// 旧节点的订阅协程可能仍在写入，继续读取并丢弃以免其阻塞，节点关闭后订阅随之结束
+			go func() {
+				for range in {
+				}
+			}()
+			return
+		}
+	}
+}
+
+// resubscribe 在集群切换节点后于新节点上重新订阅，并在后台从最后转发的区块开始回补，
+// 避免消费缓慢时阻塞节点切换。回补产生的重复消息由队列按 nonce 去重
+func (e *InEndpoint) resubscribe(_, next InClient) {
+	e.mu.Lock()
+	sub := e.sub
+	if sub == nil {
+		e.mu.Unlock()
+		return
+	}
+	from := sub.lastHeight
+	if err := e.subscribe(next, sub); err != nil {
+		e.mu.Unlock()
+		e.logger.Error("failed to resubscribe on new client", map[string]any{"error": err})
+		e.cluster.HandleError(context.Background(), err, map[string]interface{}{
+			"operation": "SubscribeToInMsgs",
+		})
+		return
+	}
+	stop := sub.stop
+	e.mu.Unlock()
 
-// 通过委托给集群中的当前客户端来实现 relay.InEndpoint
-func (e *InEndpoint) SubscribeToInMsgs(contract string, c <-chan *relay.InMsg) error {
-	return e.GetClient().SubscribeToInMsgs(contract, c)
+	go e.backfill(next, sub, from, stop)
+}
+
+// backfill 将 next 上 from 之后的消息补发给上层，订阅再次切换（stop 关闭）时放弃
+func (e *InEndpoint) backfill(next InClient, sub *inSubscription, from uint64, stop <-chan struct{}) {
+	to, err := next.LatestHeight()
+	if err != nil || from == 0 || uint64(to) < from {
+		return
+	}
+	msgs, err := next.FilterInMsgs(from, uint64(to))
+	if err != nil {
+		e.logger.Error("failed to backfill messages after resubscription", map[string]any{
+			"from":  from,
+			"to":    to,
+			"error": err,
+		})
+		return
+	}
+	node := e.cluster.NameOf(next)
+	for _, msg := range msgs {
+		msg.Observer = node
+		select {
+		case sub.out <- msg:
+		case <-stop:
+			return
+		}
+	}
+	e.logger.Info("resubscribed on new client", map[string]any{
+		"from":       from,
+		"to":         to,
+		"backfilled": len(msgs),
+	})
 }
 
 func (e *InEndpoint) ProcessOutMsgs(msgs <-chan *relay.OutMsg) error {
 	return e.GetClient().ProcessOutMsgs(msgs)
 }
 
+// PauseContract 通过当前节点调用源端跨链合约 pause()
+func (e *InEndpoint) PauseContract(ctx context.Context, key signer.Signer) (string, error) {
+	pauser, ok := e.GetClient().(relay.ContractPauser)
+	if !ok {
+		return "", ErrPauseUnsupported
+	}
+	return pauser.PauseContract(ctx, key)
+}
+
+// GetSequence 返回源端合约已处理的跨出消息序号及其所在区块
+func (e *InEndpoint) GetSequence() (uint64, uint64) {
+	return e.GetClient().GetSequence()
+}
+
+// GetNonce 返回源端账户的交易 nonce
+func (e *InEndpoint) GetNonce(address string) uint64 {
+	return e.GetClient().GetNonce(address)
+}
+
+// FilterInMsgsQuorum 在多个节点上查询区间内的跨入消息，只有达到一致阈值时才返回，
+// 用于确认源端充值确实发生
+func (e *InEndpoint) FilterInMsgsQuorum(ctx context.Context, fromHeight, toHeight uint64) ([]relay.InMsg, error) {
+	return QuorumRead(ctx, e.cluster, e.Quorum.Clamp(e.cluster.Size()),
+		func(ctx context.Context, client InClient) ([]relay.InMsg, error) {
+			return client.FilterInMsgs(fromHeight, toHeight)
+		}, nil)
+}
+
+// Balance 通过当前节点查询源端中继账户的余额，实现 relay.BalanceReader
+func (e *InEndpoint) Balance(ctx context.Context, address string) (*big.Int, error) {
+	reader, ok := e.GetClient().(relay.BalanceReader)
+	if !ok {
+		return nil, ErrBalanceUnsupported
+	}
+	return reader.Balance(ctx, address)
+}
+
+// ReadSource 在观察到消息之外健康得分最高的节点上重新读取源端交易收据，实现 relay.SourceReader。
+// 观察节点未知时（如历史同步的消息）排除当前节点
+func (e *InEndpoint) ReadSource(ctx context.Context, msg relay.InMsg) (*relay.SourceReceipt, error) {
+	observer := msg.Observer
+	if observer == "" {
+		observer = e.cluster.NameOf(e.GetClient())
+	}
+	client, node, ok := e.cluster.Other(observer)
+	if !ok {
+		return nil, fmt.Errorf("%w: observed by %s", ErrNoOtherNode, observer)
+	}
+	reader, ok := client.(relay.SourceReader)
+	if !ok {
+		return nil, ErrSourceUnsupported
+	}
+	receipt, err := reader.ReadSource(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("node %s: %w", node, err)
+	}
+	receipt.Node = node
+	return receipt, nil
+}
+
 // NewInEndpoint 使用客户端和监控间隔构建 InEndpoint
 func NewInEndpoint(config *relay.EndpointConfig, clients []InClient, monitorInterval time.Duration) *InEndpoint {
 	ep := &InEndpoint{
 		Config:  config,
+		Quorum:  DefaultQuorumConfig(),
 		cluster: NewCluster[InClient](clients, monitorInterval),
+		logger:  log.WithComponent("in-endpoint"),
 	}
+	ep.cluster.OnSwitch(ep.resubscribe)
 	ep.cluster.Start()
 	return ep
 }
 
 // OutEndpoint 实现 relay.OutEndpoint 接口，通过 Cluster 统一管理多个节点。
 type OutEndpoint struct {
-	Config  *relay.EndpointConfig `json:"config"`
+	Config  *relay.EndpointConfig                  `json:"config"`
+	Quorum  QuorumConfig                           `json:"quorum"` // 关键查询的多节点一致性参数
+	Dialer  func(*ClientConfig) (OutClient, error) `json:"-"`      // 运行时新增节点时的拨号函数
 	cluster *Cluster[OutClient]
+	logger  *log.Logger
+}
+
+func (e *OutEndpoint) LatestHeight() (int64, error) {
+	start := time.Now()
+	h, err := e.cluster.Current().LatestHeight()
+	e.cluster.Observe(time.Since(start), err)
+	return h, err
 }
 
-func (e *OutEndpoint) LatestHeight() (int64, error) { return e.cluster.Current().LatestHeight() }
+// Status 返回当前终端的可用性状态信息
+func (e *OutEndpoint) Status() map[string]any {
+	return map[string]any{
+		"network": e.Config.Network,
+		"cluster": e.cluster.Status(),
+	}
+}
 
 func (e *OutEndpoint) GetClient() OutClient { return e.cluster.Current() }
 
 func (e *OutEndpoint) ReplaceClient() OutClient { return e.cluster.ReplaceClient() }
 
+// UpdateNodes 将集群节点更新为 configs，新增节点通过 Dialer 建立连接，移除的节点在排空后关闭
+func (e *OutEndpoint) UpdateNodes(configs []*ClientConfig) error {
+	if e.Dialer == nil {
+		return ErrNoDialer
+	}
+	_, _, err := e.cluster.Reconcile(nodeSpecs(configs, e.Dialer))
+	return err
+}
+
+// AutoUpdate 按 interval 定期通过 load 获取节点列表并更新集群成员
+func (e *OutEndpoint) AutoUpdate(load func() ([]*ClientConfig, error), interval time.Duration) {
+	go autoUpdate(e.cluster, e.UpdateNodes, load, interval, e.logger)
+}
+
 // 通过委托给当前客户端来实现 relay.OutEndpoint
-func (e *OutEndpoint) ProcessInMsgs(msgs <-chan *relay.InMsg) error {
+func (e *OutEndpoint) ProcessInMsgs(msgs <-chan *relay.InMsg) (<-chan relay.ProcessError, error) {
 	return e.GetClient().ProcessInMsgs(msgs)
 }
 
+// ProcessBatch 通过当前节点提交批量消息，节点客户端不支持时返回 relay.ErrBatchUnsupported
+func (e *OutEndpoint) ProcessBatch(ctx context.Context, batch *relay.BatchMsg) error {
+	client, ok := e.GetClient().(relay.BatchProcessor)
+	if !ok {
+		return relay.ErrBatchUnsupported
+	}
+	return client.ProcessBatch(ctx, batch)
+}
+
+// PauseContract 通过当前节点调用目标端跨链合约 pause()
+func (e *OutEndpoint) PauseContract(ctx context.Context, key signer.Signer) (string, error) {
+	pauser, ok := e.GetClient().(relay.ContractPauser)
+	if !ok {
+		return "", ErrPauseUnsupported
+	}
+	return pauser.PauseContract(ctx, key)
+}
+
+// EstimateRelayGas 通过当前节点估算中继调用的 gas，节点客户端不支持时返回 relay.ErrNoGasEstimator
+func (e *OutEndpoint) EstimateRelayGas(ctx context.Context, from string, msg relay.InMsg) (uint64, error) {
+	estimator, ok := e.GetClient().(relay.GasEstimator)
+	if !ok {
+		return 0, relay.ErrNoGasEstimator
+	}
+	return estimator.EstimateRelayGas(ctx, from, msg)
+}
+
+// Balance 通过当前节点查询目标端中继账户的余额，实现 relay.BalanceReader
+func (e *OutEndpoint) Balance(ctx context.Context, address string) (*big.Int, error) {
+	reader, ok := e.GetClient().(relay.BalanceReader)
+	if !ok {
+		return nil, ErrBalanceUnsupported
+	}
+	return reader.Balance(ctx, address)
+}
+
+// SuggestGasPrice 通过当前节点查询 gas 价格
+func (e *OutEndpoint) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	estimator, ok := e.GetClient().(relay.GasEstimator)
+	if !ok {
+		return nil, relay.ErrNoGasEstimator
+	}
+	return estimator.SuggestGasPrice(ctx)
+}
+
 func (e *OutEndpoint) SubscribeToOutMsgs(msgs <-chan *relay.OutMsg) error {
 	return e.GetClient().SubscribeToOutMsgs(msgs)
 }
@@ -77,11 +388,46 @@ func (e *OutEndpoint) SubscribeToBatchMsgs() (<-chan relay.BatchMsg, error) {
 	return e.GetClient().SubscribeToBatchMsgs()
 }
 
+// GetSequenceQuorum 在多个节点上查询目标合约已处理的序列号和高度，只有达到一致阈值时才返回
+func (e *OutEndpoint) GetSequenceQuorum(ctx context.Context) (uint64, uint64, error) {
+	type sequence struct {
+		ID     uint64 `json:"id"`
+		Height uint64 `json:"height"`
+	}
+	seq, err := QuorumRead(ctx, e.cluster, e.Quorum.Clamp(e.cluster.Size()),
+		func(ctx context.Context, client OutClient) (sequence, error) {
+			id, height := client.GetSequence()
+			return sequence{ID: id, Height: height}, nil
+		},
+		// 各节点高度不同是正常现象，只比较序列号
+		func(s sequence) (string, error) { return DigestJSON(s.ID) })
+	if err != nil {
+		return 0, 0, err
+	}
+	return seq.ID, seq.Height, nil
+}
+
+// IsProcessed 在多个节点上查询目标合约 isProcessed(id)，只有达到一致阈值时才返回。
+// 查询失败或节点不一致时返回错误，调用方不应提交消息
+func (e *OutEndpoint) IsProcessed(ctx context.Context, id string) (bool, error) {
+	return QuorumRead(ctx, e.cluster, e.Quorum.Clamp(e.cluster.Size()),
+		func(ctx context.Context, client OutClient) (bool, error) {
+			checker, ok := client.(relay.ProcessedChecker)
+			if !ok {
+				return false, ErrProcessedUnsupported
+			}
+			return checker.IsProcessed(ctx, id)
+		},
+		func(processed bool) (string, error) { return DigestJSON(processed) })
+}
+
 // NewOutEndpoint 使用客户端和监控间隔构建 OutEndpoint
 func NewOutEndpoint(config *relay.EndpointConfig, clients []OutClient, monitorInterval time.Duration) *OutEndpoint {
 	ep := &OutEndpoint{
 		Config:  config,
+		Quorum:  DefaultQuorumConfig(),
 		cluster: NewCluster[OutClient](clients, monitorInterval),
+		logger:  log.WithComponent("out-endpoint"),
 	}
 	ep.cluster.Start()
 	return ep
@@ -0,0 +1,139 @@
+package chain
+
+import (
+	"math"
+	"time"
+)
+
+// HealthConfig 定义节点健康评分的参数
+type HealthConfig struct {
+	LatencyAlpha    float64       // 延迟 EWMA 平滑系数 (0,1]
+	ErrorAlpha      float64       // 错误率 EWMA 平滑系数 (0,1]
+	MaxHeightLag    int64         // 落后该区块数时高度得分为 0
+	MaxLatency      time.Duration // 延迟达到该值时延迟得分为 0
+	SubscriptionTTL time.Duration // 超过该时间未收到订阅事件视为订阅失活
+	SwitchMargin    float64       // 候选节点分数需高出当前节点的最小差值，用于防止抖动
+
+	HeightWeight       float64 // 高度得分权重
+	LatencyWeight      float64 // 延迟得分权重
+	ErrorWeight        float64 // 错误率得分权重
+	SubscriptionWeight float64 // 订阅活性得分权重
+}
+
+// DefaultHealthConfig 返回默认的健康评分参数
+func DefaultHealthConfig() HealthConfig {
+	return HealthConfig{
+		LatencyAlpha:    0.3,
+		ErrorAlpha:      0.2,
+		MaxHeightLag:    20,
+		MaxLatency:      5 * time.Second,
+		SubscriptionTTL: time.Minute,
+		SwitchMargin:    0.1,
+
+		HeightWeight:       0.4,
+		LatencyWeight:      0.25,
+		ErrorWeight:        0.25,
+		SubscriptionWeight: 0.1,
+	}
+}
+
+// nodeHealth 记录单个节点的健康指标
+type nodeHealth struct {
+	height    int64         // 最近一次探测到的高度
+	latency   time.Duration // 请求延迟的 EWMA
+	errorRate float64       // 错误率的 EWMA
+	lastErr   error         // 最近一次请求错误
+	reachable bool          // 最近一次探测是否成功
+
+	subscribed bool      // 是否存在活跃订阅
+	lastEvent  time.Time // 最近一次收到订阅事件的时间
+}
+
+// observe 记录一次请求的延迟和结果
+func (h *nodeHealth) observe(cfg HealthConfig, latency time.Duration, err error) {
+	if h.latency == 0 {
+		h.latency = latency
+	} else {
+		h.latency = time.Duration(cfg.LatencyAlpha*float64(latency) + (1-cfg.LatencyAlpha)*float64(h.latency))
+	}
+	h.observeResult(cfg, err)
+}
+
+// observeResult 只记录请求结果，用于没有可靠延迟数据的错误上报
+func (h *nodeHealth) observeResult(cfg HealthConfig, err error) {
+	sample := 0.0
+	if err != nil {
+		sample = 1
+	}
+	h.errorRate = cfg.ErrorAlpha*sample + (1-cfg.ErrorAlpha)*h.errorRate
+	h.lastErr = err
+}
+
+// subscriptionAlive 判断订阅是否仍然活跃，未订阅的节点视为活跃
+func (h *nodeHealth) subscriptionAlive(cfg HealthConfig, now time.Time) bool {
+	if !h.subscribed {
+		return true
+	}
+	return now.Sub(h.lastEvent) <= cfg.SubscriptionTTL
+}
+
+// score 计算节点的综合健康得分，范围为 [0, 1]
+func (h *nodeHealth) score(cfg HealthConfig, bestHeight int64, now time.Time) float64 {
+	if !h.reachable {
+		return 0
+	}
+
+	heightScore := 1.0
+	if lag := bestHeight - h.height; lag > 0 && cfg.MaxHeightLag > 0 {
+		heightScore = 1 - math.Min(float64(lag)/float64(cfg.MaxHeightLag), 1)
+	}
+
+	latencyScore := 1.0
+	if cfg.MaxLatency > 0 {
+		latencyScore = 1 - math.Min(float64(h.latency)/float64(cfg.MaxLatency), 1)
+	}
+
+	errorScore := 1 - h.errorRate
+
+	subScore := 0.0
+	if h.subscriptionAlive(cfg, now) {
+		subScore = 1
+	}
+
+	total := cfg.HeightWeight + cfg.LatencyWeight + cfg.ErrorWeight + cfg.SubscriptionWeight
+	if total <= 0 {
+		return heightScore
+	}
+
+	return (cfg.HeightWeight*heightScore +
+		cfg.LatencyWeight*latencyScore +
+		cfg.ErrorWeight*errorScore +
+		cfg.SubscriptionWeight*subScore) / total
+}
+
+// NodeStatus 是单个节点健康状态的快照
+type NodeStatus struct {
+	Index             int           `json:"index"`
+	Name              string        `json:"name"`
+	Current           bool          `json:"current"`
+	Height            int64         `json:"height"`
+	HeightLag         int64         `json:"height_lag"`
+	Latency           time.Duration `json:"latency"`
+	ErrorRate         float64       `json:"error_rate"`
+	Reachable         bool          `json:"reachable"`
+	SubscriptionAlive bool          `json:"subscription_alive"`
+	Score             float64       `json:"score"`
+	LastError         string        `json:"last_error,omitempty"`
+
+	Breaker          string    `json:"breaker"`
+	Trips            int       `json:"trips"`
+	QuarantinedUntil time.Time `json:"quarantined_until,omitempty"`
+}
+
+// ClusterStatus 是集群状态的快照
+type ClusterStatus struct {
+	Current   int          `json:"current"` // 当前节点下标，-1 表示无可用节点
+	Height    int64        `json:"height"`  // 集群内已知最高高度
+	Nodes     []NodeStatus `json:"nodes"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
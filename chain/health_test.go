@@ -0,0 +1,130 @@
+package chain
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeNode 是测试用的节点客户端
+type fakeNode struct {
+	name string
+
+	mu     sync.Mutex
+	height int64
+	err    error
+}
+
+func (n *fakeNode) Name() string { return n.name }
+
+func (n *fakeNode) LatestHeight() (int64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.height, n.err
+}
+
+func (n *fakeNode) fail(err error) {
+	n.mu.Lock()
+	n.err = err
+	n.mu.Unlock()
+}
+
+func TestNodeHealthObserve(t *testing.T) {
+	cfg := HealthConfig{LatencyAlpha: 0.5, ErrorAlpha: 0.5}
+	errNode := errors.New("node error")
+
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		errs      []error
+		latency   time.Duration
+		errorRate float64
+	}{
+		{
+			name:      "first sample sets latency",
+			latencies: []time.Duration{100 * time.Millisecond},
+			errs:      []error{nil},
+			latency:   100 * time.Millisecond,
+			errorRate: 0,
+		},
+		{
+			name:      "latency decays towards new samples",
+			latencies: []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond},
+			errs:      []error{nil, nil, nil},
+			latency:   250 * time.Millisecond,
+			errorRate: 0,
+		},
+		{
+			name:      "error rate rises with failures",
+			latencies: []time.Duration{time.Second, time.Second},
+			errs:      []error{errNode, errNode},
+			latency:   time.Second,
+			errorRate: 0.75,
+		},
+		{
+			name:      "error rate decays after recovery",
+			latencies: []time.Duration{time.Second, time.Second, time.Second, time.Second},
+			errs:      []error{errNode, nil, nil, nil},
+			latency:   time.Second,
+			errorRate: 0.0625,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h nodeHealth
+			for i, latency := range tt.latencies {
+				h.observe(cfg, latency, tt.errs[i])
+			}
+			if h.latency != tt.latency {
+				t.Errorf("Expected latency %s, got %s", tt.latency, h.latency)
+			}
+			if math.Abs(h.errorRate-tt.errorRate) > 1e-9 {
+				t.Errorf("Expected error rate %v, got %v", tt.errorRate, h.errorRate)
+			}
+		})
+	}
+}
+
+func TestNodeHealthScore(t *testing.T) {
+	cfg := DefaultHealthConfig()
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		health nodeHealth
+		score  float64
+	}{
+		{"unreachable", nodeHealth{height: 100}, 0},
+		{"healthy", nodeHealth{reachable: true, height: 100}, 1},
+		{"lagging", nodeHealth{reachable: true, height: 90}, 1 - cfg.HeightWeight/2},
+		{"errors", nodeHealth{reachable: true, height: 100, errorRate: 1}, 1 - cfg.ErrorWeight},
+		{"stale subscription", nodeHealth{reachable: true, height: 100, subscribed: true, lastEvent: now.Add(-2 * cfg.SubscriptionTTL)}, 1 - cfg.SubscriptionWeight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if score := tt.health.score(cfg, 100, now); math.Abs(score-tt.score) > 1e-9 {
+				t.Errorf("Expected score %v, got %v", tt.score, score)
+			}
+		})
+	}
+}
+
+func TestClusterSwitchClearsSubscription(t *testing.T) {
+	nodes := []*fakeNode{{name: "a", height: 100}, {name: "b", height: 100}}
+	c := NewCluster(nodes, time.Minute)
+	c.Subscribed()
+	prev := c.Current()
+
+	prev.fail(errors.New("connection refused"))
+	c.recomputeBest()
+	if c.Current() == prev {
+		t.Fatalf("Expected failover away from %s", prev.name)
+	}
+	for _, m := range c.members {
+		if m.client == prev && m.health.subscribed {
+			t.Error("Expected subscription flag to be cleared on the previous node")
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+)
+
+// DefaultDrainTimeout 是被移除节点在关闭前的等待时间，留给进行中的请求完成
+const DefaultDrainTimeout = 30 * time.Second
+
+// NodeSpec 描述一个期望存在于集群中的节点
+type NodeSpec[T Client] struct {
+	Name   string            // 节点名称，集群内唯一
+	Config string            // 节点配置的摘要，同名节点的配置变化时重新建立连接
+	Dial   func() (T, error) // 建立节点连接
+}
+
+// SwitchFunc 在集群当前节点发生变化时被调用
+type SwitchFunc[T Client] func(prev, next T)
+
+// closer 由需要释放连接的客户端实现，如 bsc.Client
+type closer interface {
+	Close()
+}
+
+// errCloser 由 Close 返回错误的客户端实现
+type errCloser interface {
+	Close() error
+}
+
+// OnSwitch registers a callback invoked after the current client changes.
+func (c *Cluster[T]) OnSwitch(fn SwitchFunc[T]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onSwitch = append(c.onSwitch, fn)
+}
+
+// SetDrainTimeout sets how long removed clients are kept open before being closed.
+func (c *Cluster[T]) SetDrainTimeout(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drainTimeout = d
+}
+
+// Reconcile updates the cluster membership to match specs. Nodes are matched by
+// name and config: new nodes are dialed and added, nodes that are no longer
+// listed are removed from selection, drained for the drain timeout and then
+// closed. A node whose config changed is redialed and replaces the old member,
+// which is drained like a removed node. Unchanged nodes keep their health and
+// breaker state; nodes passed to NewCluster adopt the config of the first spec
+// with their name. Dial failures are returned but do not prevent the remaining
+// changes from being applied. Concurrent calls are serialised.
+func (c *Cluster[T]) Reconcile(specs []NodeSpec[T]) (added, removed []string, err error) {
+	c.reconcileMu.Lock()
+	defer c.reconcileMu.Unlock()
+
+	c.mu.RLock()
+	existing := make(map[string]*member[T], len(c.members))
+	for _, m := range c.members {
+		existing[m.name] = m
+	}
+	c.mu.RUnlock()
+
+	var (
+		members []*member[T]
+		kept    = make(map[*member[T]]bool, len(specs))
+		errs    []error
+		wanted  = make(map[string]bool, len(specs))
+	)
+	for _, spec := range specs {
+		if wanted[spec.Name] {
+			errs = append(errs, fmt.Errorf("duplicate node %q", spec.Name))
+			continue
+		}
+		wanted[spec.Name] = true
+
+		m, ok := existing[spec.Name]
+		if ok && (m.config == "" || m.config == spec.Config) {
+			members = append(members, m)
+			kept[m] = true
+			continue
+		}
+
+		client, dialErr := spec.Dial()
+		if dialErr != nil {
+			errs = append(errs, fmt.Errorf("dial node %q: %w", spec.Name, dialErr))
+			if ok {
+				// 新配置无法连接时保留旧连接，下次更新重试
+				members = append(members, m)
+				kept[m] = true
+			}
+			continue
+		}
+		members = append(members, &member[T]{client: client, name: spec.Name, config: spec.Config})
+		added = append(added, spec.Name)
+	}
+
+	c.mu.Lock()
+	var drained []*member[T]
+	for _, m := range c.members {
+		if !kept[m] {
+			drained = append(drained, m)
+			removed = append(removed, m.name)
+		}
+	}
+	for _, m := range members {
+		if kept[m] && m.config == "" {
+			m.config = specConfig(specs, m.name)
+		}
+	}
+	c.members = members
+	currentRemoved := c.current != nil && !kept[c.current]
+	drainTimeout := c.drainTimeout
+	c.mu.Unlock()
+
+	if len(added) > 0 || len(removed) > 0 {
+		c.logger.Info("cluster membership updated", map[string]any{
+			"added":   added,
+			"removed": removed,
+		})
+	}
+
+	// 当前节点被移除时立即切换，避免新请求继续发往该节点
+	if currentRemoved || len(added) > 0 {
+		c.recomputeBest()
+	}
+
+	for _, m := range drained {
+		m := m
+		time.AfterFunc(drainTimeout, func() { c.closeMember(m) })
+	}
+
+	return added, removed, errors.Join(errs...)
+}
+
+// specConfig 返回 specs 中名为 name 的节点配置
+func specConfig[T Client](specs []NodeSpec[T], name string) string {
+	for _, spec := range specs {
+		if spec.Name == name {
+			return spec.Config
+		}
+	}
+	return ""
+}
+
+// closeMember closes a removed member's connections if the client supports it.
+func (c *Cluster[T]) closeMember(m *member[T]) {
+	switch cl := any(m.client).(type) {
+	case closer:
+		cl.Close()
+	case errCloser:
+		if err := cl.Close(); err != nil {
+			c.logger.Warn("failed to close removed node", map[string]any{
+				"node":  m.name,
+				"error": err,
+			})
+			return
+		}
+	default:
+		return
+	}
+	c.logger.Info("removed node closed", map[string]any{"node": m.name})
+}
+
+// nodeSpecs 将节点配置转换为 NodeSpec，使用 dial 建立连接
+func nodeSpecs[T Client](configs []*ClientConfig, dial func(*ClientConfig) (T, error)) []NodeSpec[T] {
+	specs := make([]NodeSpec[T], 0, len(configs))
+	for _, cfg := range configs {
+		cfg := cfg
+		specs = append(specs, NodeSpec[T]{
+			Name:   cfg.Name,
+			Config: fmt.Sprintf("grpc=%s rpc=%s ws=%s", cfg.GRPCURL, cfg.RPCURL, cfg.WSURL),
+			Dial:   func() (T, error) { return dial(cfg) },
+		})
+	}
+	return specs
+}
+
+// autoUpdate 定期加载节点列表并通过 update 应用，直到集群停止
+func autoUpdate[T Client](
+	c *Cluster[T],
+	update func([]*ClientConfig) error,
+	load func() ([]*ClientConfig, error),
+	interval time.Duration,
+	logger *log.Logger,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			configs, err := load()
+			if err != nil {
+				logger.Warn("failed to load node list", map[string]any{"error": err})
+				continue
+			}
+			if err := update(configs); err != nil {
+				logger.Warn("failed to update nodes", map[string]any{"error": err})
+			}
+		case <-c.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package chain
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closingNode 是记录是否被关闭的测试节点
+type closingNode struct {
+	fakeNode
+	closed atomic.Bool
+}
+
+func (n *closingNode) Close() { n.closed.Store(true) }
+
+func spec(n *closingNode) NodeSpec[*closingNode] {
+	return NodeSpec[*closingNode]{Name: n.name, Dial: func() (*closingNode, error) { return n, nil }}
+}
+
+func newClosingNode(name string) *closingNode {
+	return &closingNode{fakeNode: fakeNode{name: name, height: 100}}
+}
+
+func TestClusterReconcile(t *testing.T) {
+	a, b, c := newClosingNode("a"), newClosingNode("b"), newClosingNode("c")
+	cluster := NewCluster([]*closingNode{a, b}, time.Minute)
+	cluster.SetDrainTimeout(10 * time.Millisecond)
+
+	var switches atomic.Int32
+	cluster.OnSwitch(func(prev, next *closingNode) { switches.Add(1) })
+
+	// 添加节点，已有节点保留健康状态
+	cluster.Subscribed()
+	current := cluster.Current()
+	added, removed, err := cluster.Reconcile([]NodeSpec[*closingNode]{spec(a), spec(b), spec(c)})
+	if err != nil || len(added) != 1 || added[0] != "c" || len(removed) != 0 {
+		t.Fatalf("Expected c to be added, got %v, %v, %v", added, removed, err)
+	}
+	if len(cluster.Clients()) != 3 || cluster.Current() != current {
+		t.Fatalf("Expected current node %s to be kept, got %s", current.name, cluster.Current().name)
+	}
+	for _, m := range cluster.members {
+		if m.client == current && !m.health.subscribed {
+			t.Error("Expected existing node to keep its health state")
+		}
+	}
+
+	// 移除当前节点时立即切换，排空后关闭
+	kept := []NodeSpec[*closingNode]{spec(c)}
+	for _, n := range []*closingNode{a, b} {
+		if n != current {
+			kept = append(kept, spec(n))
+		}
+	}
+	added, removed, err = cluster.Reconcile(kept)
+	if err != nil || len(added) != 0 || len(removed) != 1 || removed[0] != current.name {
+		t.Fatalf("Expected %s to be removed, got %v, %v, %v", current.name, added, removed, err)
+	}
+	if cur := cluster.Current(); cur == current || cur == nil {
+		t.Fatalf("Expected switch away from removed node, got %v", cur)
+	}
+	if switches.Load() != 1 {
+		t.Errorf("Expected one switch callback, got %d", switches.Load())
+	}
+	if current.closed.Load() {
+		t.Error("Expected removed node to stay open while draining")
+	}
+	deadline := time.Now().Add(time.Second)
+	for !current.closed.Load() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	for _, n := range []*closingNode{a, b, c} {
+		if n.closed.Load() != (n == current) {
+			t.Errorf("Expected only the removed node to be closed after draining, %s closed=%v", n.name, n.closed.Load())
+		}
+	}
+}
+
+func TestClusterReconcileErrors(t *testing.T) {
+	a, b := newClosingNode("a"), newClosingNode("b")
+	cluster := NewCluster([]*closingNode{a}, time.Minute)
+	errDial := errors.New("dial failed")
+
+	broken := NodeSpec[*closingNode]{Name: "broken", Dial: func() (*closingNode, error) { return nil, errDial }}
+	added, removed, err := cluster.Reconcile([]NodeSpec[*closingNode]{spec(a), spec(a), broken, spec(b)})
+	if !errors.Is(err, errDial) {
+		t.Fatalf("Expected dial error, got %v", err)
+	}
+	// 连接失败和重复的节点不影响其余变更
+	if len(added) != 1 || added[0] != "b" || len(removed) != 0 || len(cluster.Clients()) != 2 {
+		t.Errorf("Expected only b to be added, got %v, %v, %d clients", added, removed, len(cluster.Clients()))
+	}
+}
+
+func TestClusterReconcileReplacesChangedNode(t *testing.T) {
+	a, b, b2 := newClosingNode("a"), newClosingNode("b"), newClosingNode("b")
+	cluster := NewCluster([]*closingNode{a, b}, time.Minute)
+	cluster.SetDrainTimeout(10 * time.Millisecond)
+
+	withConfig := func(n *closingNode, config string) NodeSpec[*closingNode] {
+		s := spec(n)
+		s.Config = config
+		return s
+	}
+
+	// 初始节点沿用首次更新的配置，不重新连接
+	added, removed, err := cluster.Reconcile([]NodeSpec[*closingNode]{withConfig(a, "rpc=a"), withConfig(b, "rpc=b")})
+	if err != nil || len(added) != 0 || len(removed) != 0 {
+		t.Fatalf("Expected no changes, got %v, %v, %v", added, removed, err)
+	}
+
+	// 同名节点的配置变化时重新连接并替换
+	added, removed, err = cluster.Reconcile([]NodeSpec[*closingNode]{withConfig(a, "rpc=a"), withConfig(b2, "rpc=b2")})
+	if err != nil || len(added) != 1 || added[0] != "b" || len(removed) != 1 || removed[0] != "b" {
+		t.Fatalf("Expected b to be replaced, got %v, %v, %v", added, removed, err)
+	}
+	clients := cluster.Clients()
+	if len(clients) != 2 || clients[1] != b2 {
+		t.Fatalf("Expected the redialed client to replace b, got %v", clients)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !b.closed.Load() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !b.closed.Load() || a.closed.Load() || b2.closed.Load() {
+		t.Errorf("Expected only the replaced client to be closed, a=%v b=%v b2=%v", a.closed.Load(), b.closed.Load(), b2.closed.Load())
+	}
+}
+
+func TestClusterReconcileConcurrent(t *testing.T) {
+	a := newClosingNode("a")
+	cluster := NewCluster([]*closingNode{a}, time.Minute)
+
+	var dials atomic.Int32
+	c := NodeSpec[*closingNode]{Name: "c", Dial: func() (*closingNode, error) {
+		dials.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return newClosingNode("c"), nil
+	}}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cluster.Reconcile([]NodeSpec[*closingNode]{spec(a), c})
+		}()
+	}
+	wg.Wait()
+
+	// 并发更新只连接一次新节点
+	if dials.Load() != 1 || len(cluster.Clients()) != 2 {
+		t.Errorf("Expected c to be dialed once, got %d dials and %d clients", dials.Load(), len(cluster.Clients()))
+	}
+}
@@ -0,0 +1,195 @@
+package chain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrQuorumNotReached 参与查询的节点中成功返回的数量不足
+	ErrQuorumNotReached = errors.New("quorum not reached")
+	// ErrQuorumInconsistent 节点返回的结果不一致，无法达到一致阈值
+	ErrQuorumInconsistent = errors.New("quorum results inconsistent")
+)
+
+// QuorumConfig 定义多节点一致性查询参数
+type QuorumConfig struct {
+	Size      int           // 参与查询的节点数 K，0 表示全部可用节点
+	Threshold int           // 结果一致的最少节点数
+	Timeout   time.Duration // 整体查询超时
+}
+
+// DefaultQuorumConfig 返回默认的一致性查询参数：3 个节点中 2 个一致
+func DefaultQuorumConfig() QuorumConfig {
+	return QuorumConfig{
+		Size:      3,
+		Threshold: 2,
+		Timeout:   10 * time.Second,
+	}
+}
+
+// Clamp 将参与节点数和一致阈值限制在集群配置的节点总数以内，
+// 使节点少于默认参数的网络（如只配置一个节点）仍能完成一致性查询。
+// 按配置的节点总数而不是可用节点数限制，节点被隔离时查询仍会失败
+func (c QuorumConfig) Clamp(members int) QuorumConfig {
+	if members <= 0 {
+		return c
+	}
+	if c.Size > members {
+		c.Size = members
+	}
+	if c.Threshold > members {
+		c.Threshold = members
+	}
+	return c
+}
+
+// QuorumError 描述一次未达成一致的查询
+type QuorumError struct {
+	Required  int            // 需要一致的节点数
+	Queried   int            // 参与查询的节点数
+	Responded int            // 成功返回的节点数
+	Results   map[string]int // 结果摘要 -> 返回该结果的节点数
+	Errors    map[string]error
+	err       error
+}
+
+func (e *QuorumError) Error() string {
+	return fmt.Sprintf("%v: required %d of %d, responded %d, distinct results %d",
+		e.err, e.Required, e.Queried, e.Responded, len(e.Results))
+}
+
+func (e *QuorumError) Unwrap() error { return e.err }
+
+// DigestJSON 以 JSON 编码的哈希作为结果摘要，适用于日志集合、收据状态、存储值等可序列化结果
+func DigestJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// quorumResult 是单个节点的查询结果
+type quorumResult[R any] struct {
+	node   string
+	value  R
+	digest string
+	err    error
+}
+
+// candidates 返回按健康得分排序的前 k 个未隔离节点，k <= 0 时返回全部
+func (c *Cluster[T]) candidates(k int) []*member[T] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	var out []*member[T]
+	for _, m := range c.members {
+		if m.breaker.state == BreakerClosed {
+			out = append(out, m)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return c.scoreOf(out[i], now) > c.scoreOf(out[j], now)
+	})
+	if k > 0 && k < len(out) {
+		out = out[:k]
+	}
+	return out
+}
+
+// QuorumRead 在集群中 K 个节点上并发执行只读查询，仅当至少 Threshold 个节点
+// 返回相同结果时才返回该结果，用于防范单个服务商被攻破或数据落后。
+// digest 为结果生成比较用的摘要，为 nil 时使用 DigestJSON。
+func QuorumRead[T Client, R any](
+	ctx context.Context,
+	c *Cluster[T],
+	cfg QuorumConfig,
+	read func(ctx context.Context, client T) (R, error),
+	digest func(R) (string, error),
+) (R, error) {
+	var zero R
+	if digest == nil {
+		digest = func(v R) (string, error) { return DigestJSON(v) }
+	}
+
+	members := c.candidates(cfg.Size)
+	qerr := &QuorumError{
+		Required: cfg.Threshold,
+		Queried:  len(members),
+		Results:  make(map[string]int),
+		Errors:   make(map[string]error),
+		err:      ErrQuorumNotReached,
+	}
+	if cfg.Threshold <= 0 || len(members) < cfg.Threshold {
+		return zero, qerr
+	}
+
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan quorumResult[R], len(members))
+	for _, m := range members {
+		go func(m *member[T]) {
+			r := quorumResult[R]{node: m.name}
+			r.value, r.err = read(ctx, m.client)
+			if r.err == nil {
+				r.digest, r.err = digest(r.value)
+			}
+			results <- r
+		}(m)
+	}
+
+	nodes := make(map[string][]string) // 结果摘要 -> 节点
+	for pending := len(members); pending > 0; pending-- {
+		r := <-results
+		if r.err != nil {
+			qerr.Errors[r.node] = r.err
+		} else {
+			qerr.Responded++
+			qerr.Results[r.digest]++
+			nodes[r.digest] = append(nodes[r.digest], r.node)
+			if qerr.Results[r.digest] >= cfg.Threshold {
+				if len(qerr.Results) > 1 {
+					c.logger.Warn("quorum reached with dissenting nodes", map[string]any{
+						"agreed":  nodes[r.digest],
+						"results": nodes,
+					})
+				}
+				return r.value, nil
+			}
+		}
+
+		// 剩余节点即使全部一致也无法达到阈值时提前结束
+		best := 0
+		for _, n := range qerr.Results {
+			best = max(best, n)
+		}
+		if best+pending-1 < cfg.Threshold {
+			break
+		}
+	}
+
+	if len(qerr.Results) > 1 {
+		qerr.err = ErrQuorumInconsistent
+		c.logger.Error("quorum inconsistency detected", map[string]any{
+			"required": cfg.Threshold,
+			"results":  nodes,
+			"errors":   qerr.Errors,
+		})
+	}
+	return zero, qerr
+}
@@ -0,0 +1,79 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuorumRead(t *testing.T) {
+	errRead := errors.New("read failed")
+
+	tests := []struct {
+		name      string
+		values    map[string]string // 节点 -> 返回值，"" 表示查询失败
+		threshold int
+		want      string
+		err       error
+	}{
+		{"all agree", map[string]string{"a": "x", "b": "x", "c": "x"}, 2, "x", nil},
+		{"agree with dissent", map[string]string{"a": "x", "b": "y", "c": "x"}, 2, "x", nil},
+		{"agree with failure", map[string]string{"a": "x", "b": "", "c": "x"}, 2, "x", nil},
+		{"disagreement", map[string]string{"a": "x", "b": "y", "c": "z"}, 2, "", ErrQuorumInconsistent},
+		{"too many failures", map[string]string{"a": "x", "b": "", "c": ""}, 2, "", ErrQuorumNotReached},
+		{"too few members", map[string]string{"a": "x", "b": "x"}, 3, "", ErrQuorumNotReached},
+		{"zero threshold", map[string]string{"a": "x"}, 0, "", ErrQuorumNotReached},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var nodes []*fakeNode
+			for _, name := range []string{"a", "b", "c"} {
+				if _, ok := tt.values[name]; ok {
+					nodes = append(nodes, &fakeNode{name: name, height: 100})
+				}
+			}
+			c := NewCluster(nodes, time.Minute)
+			cfg := QuorumConfig{Size: 3, Threshold: tt.threshold, Timeout: time.Second}
+
+			got, err := QuorumRead(context.Background(), c, cfg, func(ctx context.Context, n *fakeNode) (string, error) {
+				if v := tt.values[n.name]; v != "" {
+					return v, nil
+				}
+				return "", errRead
+			}, nil)
+			if tt.err != nil {
+				var qerr *QuorumError
+				if !errors.Is(err, tt.err) || !errors.As(err, &qerr) {
+					t.Fatalf("Expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Expected %q, got %q, %v", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestQuorumClampSingleNode(t *testing.T) {
+	c := NewCluster([]*fakeNode{{name: "a", height: 100}}, time.Minute)
+	read := func(ctx context.Context, n *fakeNode) (bool, error) { return true, nil }
+
+	// 默认参数要求 3 个节点中 2 个一致，单节点网络无法满足
+	if _, err := QuorumRead(context.Background(), c, DefaultQuorumConfig(), read, nil); !errors.Is(err, ErrQuorumNotReached) {
+		t.Fatalf("Expected %v without clamping, got %v", ErrQuorumNotReached, err)
+	}
+	cfg := DefaultQuorumConfig().Clamp(c.Size())
+	if cfg.Size != 1 || cfg.Threshold != 1 {
+		t.Fatalf("Expected 1 of 1 after clamping, got %d of %d", cfg.Threshold, cfg.Size)
+	}
+	if ok, err := QuorumRead(context.Background(), c, cfg, read, nil); err != nil || !ok {
+		t.Fatalf("Expected single node read to succeed, got %v, %v", ok, err)
+	}
+
+	// 节点数足够时保持原参数
+	if got := DefaultQuorumConfig().Clamp(5); got != DefaultQuorumConfig() {
+		t.Errorf("Expected config unchanged, got %+v", got)
+	}
+}
@@ -0,0 +1,126 @@
+package action
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/st-chain/me-bridge/server"
+	"github.com/urfave/cli/v2"
+)
+
+// APIFlags 是访问运行中桥服务 API 的命令共用的参数
+var APIFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "api",
+		Usage:   "Base URL of the bridge API",
+		Value:   "http://127.0.0.1:8080",
+		EnvVars: []string{"BRIDGE_API"},
+	},
+	&cli.StringFlag{
+		Name:    "operator",
+		Usage:   "Operator name recorded in the audit log",
+		EnvVars: []string{"BRIDGE_OPERATOR"},
+	},
+	&cli.StringFlag{
+		Name:    "token",
+		Usage:   "API token authenticating the operator",
+		EnvVars: []string{"BRIDGE_API_TOKEN"},
+	},
+}
+
+// apiClient 是桥服务 API 的 HTTP 客户端
+type apiClient struct {
+	base     string
+	operator string
+	token    string
+	header   http.Header // 附加的请求头，如运维签名
+	http     *http.Client
+}
+
+func newAPIClient(ctx *cli.Context) *apiClient {
+	return &apiClient{
+		base:     strings.TrimRight(ctx.String("api"), "/"),
+		operator: ctx.String("operator"),
+		token:    ctx.String("token"),
+		header:   make(http.Header),
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do 发送请求，body 非 nil 时编码为 JSON，响应非 2xx 时返回服务端的错误信息
+func (c *apiClient) do(method, path string, body any) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.base+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.operator != "" {
+		req.Header.Set(server.OperatorHeader, c.operator)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("%s %s: %s (%d)", method, path, apiErr.Error, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	return data, nil
+}
+
+// printJSON 以缩进格式输出 JSON 响应
+func printJSON(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return err
+	}
+	fmt.Println(out.String())
+	return nil
+}
+
+// pathID 转义作为路径参数的 ID
+func pathID(ctx *cli.Context) (string, error) {
+	id := ctx.Args().First()
+	if id == "" {
+		return "", fmt.Errorf("missing id argument")
+	}
+	return url.PathEscape(id), nil
+}
@@ -0,0 +1,40 @@
+package action
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/urfave/cli/v2"
+)
+
+// BridgeStatusAction 处理 bridge status 命令，未指定跨链桥时列出全部
+func BridgeStatusAction(ctx *cli.Context) error {
+	path := "/bridges"
+	if name := ctx.Args().First(); name != "" {
+		path = "/bridges/" + url.PathEscape(name) + "/control"
+	}
+	data, err := newAPIClient(ctx).do(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	return printJSON(data)
+}
+
+// BridgeControlAction 处理 bridge 下的暂停、恢复和紧急停止命令，操作名即子命令名
+func BridgeControlAction(ctx *cli.Context) error {
+	name, err := pathID(ctx)
+	if err != nil {
+		return err
+	}
+	body := map[string]string{
+		"action": ctx.Command.Name,
+		"reason": ctx.String("reason"),
+	}
+	data, err := newAPIClient(ctx).do(http.MethodPost, "/bridges/"+name+"/control", body)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Bridge %s: %s applied\n", ctx.Args().First(), ctx.Command.Name)
+	return printJSON(data)
+}
@@ -0,0 +1,95 @@
+package action
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/st-chain/me-bridge/types"
+	"github.com/urfave/cli/v2"
+)
+
+// DeadLetterListAction 处理 deadletter list 命令
+func DeadLetterListAction(ctx *cli.Context) error {
+	path := "/deadletters"
+	if status := ctx.String("status"); status != "" {
+		path += "?status=" + url.QueryEscape(status)
+	}
+	data, err := newAPIClient(ctx).do(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	return printJSON(data)
+}
+
+// DeadLetterShowAction 处理 deadletter show 命令，--audit 时同时输出审计日志
+func DeadLetterShowAction(ctx *cli.Context) error {
+	id, err := pathID(ctx)
+	if err != nil {
+		return err
+	}
+	client := newAPIClient(ctx)
+	data, err := client.do(http.MethodGet, "/deadletters/"+id, nil)
+	if err != nil {
+		return err
+	}
+	if err := printJSON(data); err != nil {
+		return err
+	}
+	if !ctx.Bool("audit") {
+		return nil
+	}
+	data, err = client.do(http.MethodGet, "/deadletters/"+id+"/audit", nil)
+	if err != nil {
+		return err
+	}
+	return printJSON(data)
+}
+
+// DeadLetterFeeAction 处理 deadletter fee 命令，未指定任何参数时恢复默认手续费策略
+func DeadLetterFeeAction(ctx *cli.Context) error {
+	id, err := pathID(ctx)
+	if err != nil {
+		return err
+	}
+	var policy *types.FeePolicy
+	if ctx.IsSet("gas-price") || ctx.IsSet("gas-limit") || ctx.IsSet("max-fee") {
+		policy = &types.FeePolicy{
+			GasPrice: ctx.String("gas-price"),
+			GasLimit: ctx.Uint64("gas-limit"),
+			MaxFee:   ctx.String("max-fee"),
+		}
+	}
+	if _, err := newAPIClient(ctx).do(http.MethodPut, "/deadletters/"+id+"/fee", policy); err != nil {
+		return err
+	}
+	fmt.Printf("Fee policy of dead letter %s updated\n", ctx.Args().First())
+	return nil
+}
+
+// DeadLetterReplayAction 处理 deadletter replay 命令
+func DeadLetterReplayAction(ctx *cli.Context) error {
+	id, err := pathID(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := newAPIClient(ctx).do(http.MethodPost, "/deadletters/"+id+"/replay", nil); err != nil {
+		return err
+	}
+	fmt.Printf("Dead letter %s replayed\n", ctx.Args().First())
+	return nil
+}
+
+// DeadLetterDiscardAction 处理 deadletter discard 命令
+func DeadLetterDiscardAction(ctx *cli.Context) error {
+	id, err := pathID(ctx)
+	if err != nil {
+		return err
+	}
+	body := map[string]string{"reason": ctx.String("reason")}
+	if _, err := newAPIClient(ctx).do(http.MethodPost, "/deadletters/"+id+"/discard", body); err != nil {
+		return err
+	}
+	fmt.Printf("Dead letter %s discarded\n", ctx.Args().First())
+	return nil
+}
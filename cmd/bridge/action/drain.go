@@ -0,0 +1,72 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/st-chain/me-bridge/server"
+	"github.com/urfave/cli/v2"
+)
+
+// drainPollInterval 是等待排空结束时查询进度的间隔
+const drainPollInterval = 2 * time.Second
+
+// DrainAction 处理 drain 命令：触发运行中服务的排空，--wait 时等待排空结束并输出结果
+func DrainAction(ctx *cli.Context) error {
+	client := newAPIClient(ctx)
+	body := map[string]int64{"timeout": int64(ctx.Duration("timeout").Seconds())}
+	data, err := client.do(http.MethodPost, "/drain", body)
+	if err != nil {
+		return err
+	}
+
+	var report server.DrainReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return err
+	}
+	fmt.Printf("Draining started, deadline %s\n", report.Deadline.Format(time.RFC3339))
+	if !ctx.Bool("wait") {
+		return nil
+	}
+
+	for !report.Done() {
+		time.Sleep(drainPollInterval)
+		if data, err = client.do(http.MethodGet, "/drain", nil); err != nil {
+			// 服务在排空结束后退出，此时无法再查询
+			return fmt.Errorf("lost connection before drain finished: %w", err)
+		}
+		if err := json.Unmarshal(data, &report); err != nil {
+			return err
+		}
+	}
+	printDrainReport(&report)
+	return nil
+}
+
+// printDrainReport 输出排空结果
+func printDrainReport(report *server.DrainReport) {
+	if report == nil {
+		return
+	}
+	duration := time.Duration(0)
+	if report.FinishedAt != nil {
+		duration = report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond)
+	}
+	fmt.Printf("Drain finished in %s\n", duration)
+	for _, t := range report.Tunnels {
+		status := "ok"
+		if t.TimedOut {
+			status = "timed out"
+		}
+		fmt.Printf("  %-24s %-10s completed=%d pending=%d queued=%d persisted=%t\n",
+			t.Path, status, t.Completed, len(t.Pending), t.Queued, t.Persisted)
+		if len(t.Pending) > 0 {
+			fmt.Printf("  %-24s pending nonces: %v\n", "", t.Pending)
+		}
+	}
+	if report.Error != "" {
+		fmt.Printf("  error: %s\n", report.Error)
+	}
+}
@@ -0,0 +1,109 @@
+package action
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/st-chain/me-bridge/relay"
+	"github.com/st-chain/me-bridge/server"
+	"github.com/urfave/cli/v2"
+)
+
+// HoldListAction 处理 hold list 命令
+func HoldListAction(ctx *cli.Context) error {
+	path := "/holds"
+	if status := ctx.String("status"); status != "" {
+		path += "?status=" + url.QueryEscape(status)
+	}
+	data, err := newAPIClient(ctx).do(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	return printJSON(data)
+}
+
+// HoldShowAction 处理 hold show 命令，--audit 时同时输出审计日志
+func HoldShowAction(ctx *cli.Context) error {
+	id, err := pathID(ctx)
+	if err != nil {
+		return err
+	}
+	client := newAPIClient(ctx)
+	data, err := client.do(http.MethodGet, "/holds/"+id, nil)
+	if err != nil {
+		return err
+	}
+	if err := printJSON(data); err != nil {
+		return err
+	}
+	if !ctx.Bool("audit") {
+		return nil
+	}
+	data, err = client.do(http.MethodGet, "/holds/"+id+"/audit", nil)
+	if err != nil {
+		return err
+	}
+	return printJSON(data)
+}
+
+// HoldApproveAction 处理 hold approve 命令，输出批准进度
+func HoldApproveAction(ctx *cli.Context) error {
+	id, err := pathID(ctx)
+	if err != nil {
+		return err
+	}
+	client := newAPIClient(ctx)
+	if err := signOperator(ctx, client, relay.AuditHoldApprove); err != nil {
+		return err
+	}
+	data, err := client.do(http.MethodPost, "/holds/"+id+"/approve", nil)
+	if err != nil {
+		return err
+	}
+	return printJSON(data)
+}
+
+// HoldRejectAction 处理 hold reject 命令
+func HoldRejectAction(ctx *cli.Context) error {
+	id, err := pathID(ctx)
+	if err != nil {
+		return err
+	}
+	client := newAPIClient(ctx)
+	if err := signOperator(ctx, client, relay.AuditHoldReject); err != nil {
+		return err
+	}
+	body := map[string]string{"reason": ctx.String("reason")}
+	if _, err := client.do(http.MethodPost, "/holds/"+id+"/reject", body); err != nil {
+		return err
+	}
+	fmt.Printf("Held message %s rejected\n", ctx.Args().First())
+	return nil
+}
+
+// signOperator 指定 --signing-key 时用私钥对审批消息签名，以签名方式认证运维
+func signOperator(ctx *cli.Context, client *apiClient, action string) error {
+	keyFile := ctx.String("signing-key")
+	if keyFile == "" {
+		return nil
+	}
+	key, err := crypto.LoadECDSA(keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load signing key: %w", err)
+	}
+	ts := time.Now().Unix()
+	msg := server.OperatorMessage(action, ctx.Args().First(), ts)
+	sig, err := crypto.Sign(accounts.TextHash([]byte(msg)), key)
+	if err != nil {
+		return err
+	}
+	client.header.Set(server.SignatureHeader, hexutil.Encode(sig))
+	client.header.Set(server.TimestampHeader, strconv.FormatInt(ts, 10))
+	return nil
+}
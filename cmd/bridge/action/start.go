@@ -1,10 +1,16 @@
 package action
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/relay"
 	"github.com/st-chain/me-bridge/server"
 	"github.com/urfave/cli/v2"
 	"gopkg.in/yaml.v3"
@@ -35,7 +41,10 @@ func StartAction(ctx *cli.Context) error {
 	log.Infof("Configuration %s loaded successfully", configPath)
 
 	// 创建并启动服务器
-	srv := server.NewServerWithConfig(serverConfig)
+	srv, err := server.NewServerWithConfig(serverConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
 
 	log.Info("Starting me-bridge server...")
 	if err := srv.Start(); err != nil {
@@ -43,5 +52,55 @@ func StartAction(ctx *cli.Context) error {
 	}
 
 	log.Info("me-bridge server started successfully")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if serverConfig.API != nil {
+		api := server.NewAPI(srv, serverConfig.API)
+		if err := api.Start(); err != nil {
+			return fmt.Errorf("failed to start api server: %w", err)
+		}
+		defer api.Stop(context.Background())
+	}
+
+	// 配置文件修改后自动重新加载节点列表
+	go srv.WatchConfig(ctx, configPath, configWatchInterval)
+
+	// 定期将超过审批期限的挂起消息记为过期
+	if srv.Holds != nil {
+		go srv.Holds.Run(ctx, relay.DefaultHoldExpireInterval)
+	}
+
+	// 定期对账各跨链桥的锁定量、释放量和账本
+	srv.RunReconcilers(ctx)
+
+	// 定期检查中继账户余额，余额不足时暂停提交并告警
+	srv.RunBalanceMonitors(ctx)
+
+	// 定期查询各链的 gas 价格，价格过高时推迟非紧急消息
+	srv.RunGasPolicies(ctx)
+
+	// 收到 SIGTERM/SIGINT 或通过 API 触发排空后，等待在途交易完成再退出
+	select {
+	case <-ctx.Done():
+		// 恢复默认信号处理，再次收到信号时直接退出
+		stop()
+		log.Info("Draining me-bridge server...")
+		if _, err := srv.Drain(0); err != nil && !errors.Is(err, server.ErrDraining) {
+			return err
+		}
+		<-srv.Drained()
+	case <-srv.Drained():
+	}
+
+	report := srv.DrainReport()
+	printDrainReport(report)
+	if report.Error != "" {
+		return errors.New(report.Error)
+	}
 	return nil
 }
+
+// configWatchInterval 是检查配置文件变化的间隔
+const configWatchInterval = 10 * time.Second
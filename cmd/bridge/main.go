@@ -8,6 +8,23 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+// controlFlags 是跨链桥控制命令的参数
+var controlFlags = append([]cli.Flag{
+	&cli.StringFlag{
+		Name:  "reason",
+		Usage: "Reason recorded with the control state and audit log",
+	},
+}, action.APIFlags...)
+
+// holdFlags 是审批挂起消息命令的参数
+var holdFlags = append([]cli.Flag{
+	&cli.StringFlag{
+		Name:    "signing-key",
+		Usage:   "File with the hex private key used to sign the approval instead of an API token",
+		EnvVars: []string{"BRIDGE_SIGNING_KEY"},
+	},
+}, action.APIFlags...)
+
 func main() {
 	app := &cli.App{
 		Name:  "bridge",
@@ -26,6 +43,76 @@ func main() {
 				},
 				Action: action.StartAction,
 			},
+			{
+				Name:  "drain",
+				Usage: "Stop accepting new messages and shut down after in-flight transactions finish",
+				Flags: append([]cli.Flag{
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Usage: "Maximum time to wait for in-flight transactions (0 uses the server default)",
+					},
+					&cli.BoolFlag{
+						Name:  "wait",
+						Usage: "Wait for the drain to finish and print the summary",
+					},
+				}, action.APIFlags...),
+				Action: action.DrainAction,
+			},
+			{
+				Name:  "bridge",
+				Usage: "Pause, resume or emergency-stop a bridge",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "status",
+						Usage:     "Show the control state of bridges",
+						ArgsUsage: "[bridge]",
+						Flags:     action.APIFlags,
+						Action:    action.BridgeStatusAction,
+					},
+					{
+						Name:      "pause-inbound",
+						Usage:     "Stop accepting new messages on a bridge",
+						ArgsUsage: "<bridge>",
+						Flags:     controlFlags,
+						Action:    action.BridgeControlAction,
+					},
+					{
+						Name:      "resume-inbound",
+						Usage:     "Resume accepting new messages on a bridge",
+						ArgsUsage: "<bridge>",
+						Flags:     controlFlags,
+						Action:    action.BridgeControlAction,
+					},
+					{
+						Name:      "pause-outbound",
+						Usage:     "Stop submitting transactions on a bridge",
+						ArgsUsage: "<bridge>",
+						Flags:     controlFlags,
+						Action:    action.BridgeControlAction,
+					},
+					{
+						Name:      "resume-outbound",
+						Usage:     "Resume submitting transactions on a bridge",
+						ArgsUsage: "<bridge>",
+						Flags:     controlFlags,
+						Action:    action.BridgeControlAction,
+					},
+					{
+						Name:      "emergency-stop",
+						Usage:     "Pause both directions and call pause() on the bridge contracts",
+						ArgsUsage: "<bridge>",
+						Flags:     controlFlags,
+						Action:    action.BridgeControlAction,
+					},
+					{
+						Name:      "resume",
+						Usage:     "Resume both directions and clear an emergency stop",
+						ArgsUsage: "<bridge>",
+						Flags:     controlFlags,
+						Action:    action.BridgeControlAction,
+					},
+				},
+			},
 			{
 				Name:  "config",
 				Usage: "Configuration management",
@@ -49,6 +136,125 @@ func main() {
 					},
 				},
 			},
+			{
+				Name:    "deadletter",
+				Aliases: []string{"dlq"},
+				Usage:   "Inspect and handle dead-lettered messages",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "List dead letters",
+						Flags: append([]cli.Flag{
+							&cli.StringFlag{
+								Name:  "status",
+								Usage: "Filter by status (pending, replayed, discarded)",
+							},
+						}, action.APIFlags...),
+						Action: action.DeadLetterListAction,
+					},
+					{
+						Name:      "show",
+						Usage:     "Show a dead letter",
+						ArgsUsage: "<id>",
+						Flags: append([]cli.Flag{
+							&cli.BoolFlag{
+								Name:  "audit",
+								Usage: "Also print the audit trail",
+							},
+						}, action.APIFlags...),
+						Action: action.DeadLetterShowAction,
+					},
+					{
+						Name:      "fee",
+						Usage:     "Set the fee policy used when replaying a dead letter",
+						ArgsUsage: "<id>",
+						Flags: append([]cli.Flag{
+							&cli.StringFlag{
+								Name:  "gas-price",
+								Usage: "Fixed gas price in wei",
+							},
+							&cli.Uint64Flag{
+								Name:  "gas-limit",
+								Usage: "Fixed gas limit",
+							},
+							&cli.StringFlag{
+								Name:  "max-fee",
+								Usage: "Maximum fee in wei",
+							},
+						}, action.APIFlags...),
+						Action: action.DeadLetterFeeAction,
+					},
+					{
+						Name:      "replay",
+						Usage:     "Replay a dead letter through its tunnel",
+						ArgsUsage: "<id>",
+						Flags:     action.APIFlags,
+						Action:    action.DeadLetterReplayAction,
+					},
+					{
+						Name:      "discard",
+						Usage:     "Permanently discard a dead letter",
+						ArgsUsage: "<id>",
+						Flags: append([]cli.Flag{
+							&cli.StringFlag{
+								Name:     "reason",
+								Usage:    "Reason recorded in the audit log",
+								Required: true,
+							},
+						}, action.APIFlags...),
+						Action: action.DeadLetterDiscardAction,
+					},
+				},
+			},
+			{
+				Name:  "hold",
+				Usage: "Inspect, approve and reject messages held for manual approval",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "List held messages",
+						Flags: append([]cli.Flag{
+							&cli.StringFlag{
+								Name:  "status",
+								Usage: "Filter by status (pending, approved, rejected, expired)",
+							},
+						}, action.APIFlags...),
+						Action: action.HoldListAction,
+					},
+					{
+						Name:      "show",
+						Usage:     "Show a held message and its approvals",
+						ArgsUsage: "<id>",
+						Flags: append([]cli.Flag{
+							&cli.BoolFlag{
+								Name:  "audit",
+								Usage: "Also print the audit trail",
+							},
+						}, action.APIFlags...),
+						Action: action.HoldShowAction,
+					},
+					{
+						Name:      "approve",
+						Usage:     "Approve a held message; it is relayed once enough operators approve",
+						ArgsUsage: "<id>",
+						Flags:     holdFlags,
+						Action:    action.HoldApproveAction,
+					},
+					{
+						Name:      "reject",
+						Usage:     "Reject a held message so it is never relayed",
+						ArgsUsage: "<id>",
+						Flags: append([]cli.Flag{
+							&cli.StringFlag{
+								Name:     "reason",
+								Usage:    "Reason recorded in the audit log",
+								Required: true,
+							},
+						}, holdFlags...),
+						Action: action.HoldRejectAction,
+					},
+				},
+			},
 		},
 	}
 
@@ -5,6 +5,15 @@ type APIConfig struct {
 	IP      string `yaml:"ip" json:"ip"`           // 服务器IP地址
 	Port    int32  `yaml:"port" json:"port"`       // 服务器端口
 	Timeout int32  `yaml:"timeout" json:"timeout"` // 超时时间（秒）
+
+	Operators []OperatorConfig `yaml:"operators" json:"operators"` // 可执行运维操作的运维，为空时拒绝所有运维操作
+}
+
+// OperatorConfig 定义一名运维的认证方式，至少配置一种
+type OperatorConfig struct {
+	Name      string `yaml:"name" json:"name"`             // 写入审计日志的运维名称
+	TokenHash string `yaml:"token_hash" json:"token_hash"` // API 令牌的 SHA-256（十六进制），请求携带 Authorization: Bearer <令牌>
+	Address   string `yaml:"address" json:"address"`       // 签名地址，请求携带对审批消息的 EIP-191 签名
 }
 
 // SignerConfig 定义签名器配置
@@ -29,6 +38,27 @@ type NetworkConfig struct {
 	MaxRetries    int32          `yaml:"max_retries" json:"max_retries"`       // 最大重试次数
 	RetryInterval int64          `yaml:"retry_interval" json:"retry_interval"` // 重试间隔（毫秒）
 	ClientConfigs []ClientConfig `yaml:"target_configs" json:"target_configs"` // 目标节点配置列表
+
+	Gas    *GasPolicyConfig `yaml:"gas" json:"gas"`       // 提交到该链的 gas 价格策略，为空时按节点建议的价格提交
+	Quorum *QuorumConfig    `yaml:"quorum" json:"quorum"` // 关键查询的多节点一致性参数，为空时 3 个节点中 2 个一致
+}
+
+// QuorumConfig 定义一条链上关键查询（消息是否已处理、已处理序号、源端事件）的多节点一致性参数。
+// 配置的节点少于 size 或 threshold 时按节点总数执行，单节点网络即只查询该节点
+type QuorumConfig struct {
+	Size      int   `yaml:"size" json:"size"`           // 参与查询的节点数，为 0 时查询全部可用节点
+	Threshold int   `yaml:"threshold" json:"threshold"` // 结果一致的最少节点数
+	Timeout   int64 `yaml:"timeout" json:"timeout"`     // 整体查询超时（毫秒），为 0 时使用默认值
+}
+
+// GasPolicyConfig 定义一条链的 gas 价格策略：价格或成本过高时推迟非紧急的跨入消息，推迟超过 max_delay 后照常提交
+type GasPolicyConfig struct {
+	MaxGasPrice string `yaml:"max_gas_price" json:"max_gas_price"` // gas 价格上限（wei），为空时不限制
+	MaxFeeBps   uint64 `yaml:"max_fee_bps" json:"max_fee_bps"`     // 一次中继的 gas 成本占消息金额的上限（基点），成本来源为跨链桥的 fee 配置，为 0 时不限制
+	UrgentValue string `yaml:"urgent_value" json:"urgent_value"`   // 金额（目标端最小单位）不低于该值的消息不推迟，为空时只有运维批准的消息不推迟
+	MaxDelay    int64  `yaml:"max_delay" json:"max_delay"`         // 消息最长推迟时间（秒），为 0 时使用默认值
+	Interval    int64  `yaml:"interval" json:"interval"`           // 查询 gas 价格的间隔（秒），为 0 时使用默认值
+	History     int    `yaml:"history" json:"history"`             // 保留的 gas 价格记录数，为 0 时使用默认值
 }
 
 // ClientConfig 定义目标节点配置
@@ -45,6 +75,26 @@ type EndpointConfig struct {
 	ConfirmBlocks   int32        `yaml:"confirm_blocks" json:"confirm_blocks"`     // 确认块数
 	ContractAddress string       `yaml:"contract_address" json:"contract_address"` // 合约地址
 	Signer          SignerConfig `yaml:"signer" json:"signer"`                     // 签名配置
+
+	Balance *BalanceConfig `yaml:"balance" json:"balance"` // 提交交易的中继账户余额监控，为空时不监控
+}
+
+// BalanceConfig 定义中继账户的余额监控，金额为原生代币（BNB、ETH、TRX）最小单位的十进制字符串
+type BalanceConfig struct {
+	Account   string `yaml:"account" json:"account"`       // 提交交易的中继账户地址
+	Warning   string `yaml:"warning" json:"warning"`       // 低于该余额时告警，为空时不告警
+	Critical  string `yaml:"critical" json:"critical"`     // 低于该余额时暂停提交并告警
+	MinRunway int64  `yaml:"min_runway" json:"min_runway"` // 预计可用时间低于该值（秒）时告警，为 0 时不检查
+	Window    int64  `yaml:"window" json:"window"`         // 估算消耗速度的窗口（秒），为 0 时使用默认值
+	Interval  int64  `yaml:"interval" json:"interval"`     // 查询余额的间隔（秒），为 0 时使用默认值
+}
+
+// BatchConfig 定义跨入消息批量提交的聚合条件，任一条件满足即提交
+type BatchConfig struct {
+	MaxCount   int32  `yaml:"max_count" json:"max_count"`     // 批次最大消息数
+	MaxValue   string `yaml:"max_value" json:"max_value"`     // 批次金额上限（最小单位，十进制）
+	Window     int64  `yaml:"window" json:"window"`           // 批次最长等待时间（毫秒）
+	MaxRetries int32  `yaml:"max_retries" json:"max_retries"` // 连接错误时整批重试次数
 }
 
 // RelayConfig 定义跨链桥配置
@@ -52,6 +102,109 @@ type RelayConfig struct {
 	Name   string         `yaml:"name" json:"name"`     // 桥名称
 	Source EndpointConfig `yaml:"source" json:"source"` // 源端点配置
 	Target EndpointConfig `yaml:"target" json:"target"` // 目标端点配置
+	Batch  *BatchConfig   `yaml:"batch" json:"batch"`   // 批量提交配置，为空时逐条提交
+	Fee    *FeeConfig     `yaml:"fee" json:"fee"`       // 手续费配置，为空时不收取手续费
+	Tokens []TokenConfig  `yaml:"tokens" json:"tokens"` // 代币登记表，为空时不换算金额
+
+	RateLimits []RateLimitConfig `yaml:"rate_limits" json:"rate_limits"` // 滚动窗口限流，为空时不限流
+	Screening  []ScreenerConfig  `yaml:"screening" json:"screening"`     // 发送和接收地址筛查，依次检查，为空时不筛查
+	Reconcile  *ReconcileConfig  `yaml:"reconcile" json:"reconcile"`     // 偿付能力对账，为空时不对账
+
+	VerifySource bool `yaml:"verify_source" json:"verify_source"` // 签名前在观察节点之外的源端节点上核对事件，要求的确认数为 source.confirm_blocks
+}
+
+// ReconcileConfig 定义偿付能力对账：比较源端托管合约的锁定量、目标端的释放量和中继器账本
+type ReconcileConfig struct {
+	Interval     int64                  `yaml:"interval" json:"interval"`             // 对账间隔（秒），为 0 时使用默认值
+	SourceRPCURL string                 `yaml:"source_rpc_url" json:"source_rpc_url"` // 读取源端锁定量的 RPC 地址
+	TargetRPCURL string                 `yaml:"target_rpc_url" json:"target_rpc_url"` // 读取目标端释放量的 RPC 地址
+	Escrow       string                 `yaml:"escrow" json:"escrow"`                 // 源端托管合约地址，为空时使用 source.contract_address
+	Mode         string                 `yaml:"mode" json:"mode"`                     // supply（目标端铸造）或 escrow（目标端从托管合约释放）
+	TargetEscrow string                 `yaml:"target_escrow" json:"target_escrow"`   // escrow 模式下目标端托管合约地址，为空时使用 target.contract_address
+	AutoPause    string                 `yaml:"auto_pause" json:"auto_pause"`         // 发现差额时的控制操作：pause-inbound, pause-outbound, emergency-stop，为空时只告警
+	Tokens       []ReconcileTokenConfig `yaml:"tokens" json:"tokens"`                 // 各代币的容差和基线，未列出的代币容差和基线为 0
+}
+
+// ReconcileTokenConfig 定义一个代币的对账参数，金额为目标端最小单位
+type ReconcileTokenConfig struct {
+	Symbol    string `yaml:"symbol" json:"symbol"`       // 代币登记表中的代币符号
+	Tolerance string `yaml:"tolerance" json:"tolerance"` // 允许的差额
+	Baseline  string `yaml:"baseline" json:"baseline"`   // 开始中继前已有的总发行量（supply）或托管合约的初始余额（escrow）
+}
+
+// ScreenerConfig 定义一个地址筛查器，被拦截的消息挂起等待人工审批
+type ScreenerConfig struct {
+	Type     string            `yaml:"type" json:"type"`         // denylist, http
+	Path     string            `yaml:"path" json:"path"`         // denylist：黑名单文件，每行一个地址，# 之后为拦截原因
+	Interval int64             `yaml:"interval" json:"interval"` // denylist：检查文件修改的间隔（秒），为 0 时使用默认值
+	URL      string            `yaml:"url" json:"url"`           // http：筛查服务地址
+	Timeout  int64             `yaml:"timeout" json:"timeout"`   // http：请求超时（毫秒）
+	Headers  map[string]string `yaml:"headers" json:"headers"`   // http：附加请求头，如 API 密钥
+}
+
+// RateLimitConfig 定义一条滚动窗口限流规则，超出的消息挂起等待人工审批
+type RateLimitConfig struct {
+	Scope    string `yaml:"scope" json:"scope"`         // bridge, token, receiver
+	Token    string `yaml:"token" json:"token"`         // 只对该目标端代币生效，为空时对所有代币生效
+	Window   int64  `yaml:"window" json:"window"`       // 窗口长度（秒）
+	MaxValue string `yaml:"max_value" json:"max_value"` // 窗口内累计金额上限（目标端代币最小单位），为空时不限制
+	MaxCount uint64 `yaml:"max_count" json:"max_count"` // 窗口内消息数上限，为 0 时不限制
+}
+
+// TokenConfig 定义跨链代币在源端和目标端的对应关系
+type TokenConfig struct {
+	Symbol         string `yaml:"symbol" json:"symbol"`                   // 代币符号
+	Source         string `yaml:"source" json:"source"`                   // 源端代币地址
+	Target         string `yaml:"target" json:"target"`                   // 目标端代币地址
+	SourceDecimals uint8  `yaml:"source_decimals" json:"source_decimals"` // 源端精度
+	TargetDecimals uint8  `yaml:"target_decimals" json:"target_decimals"` // 目标端精度
+	Min            string `yaml:"min" json:"min"`                         // 单笔最小金额（源端最小单位），为空时不限制
+	Max            string `yaml:"max" json:"max"`                         // 单笔最大金额（源端最小单位），为空时不限制
+
+	ApprovalThreshold string `yaml:"approval_threshold" json:"approval_threshold"` // 超过该金额（源端最小单位）需人工审批，为空时不需要
+}
+
+// FeeConfig 定义跨链手续费，金额均为目标端代币最小单位的十进制字符串
+type FeeConfig struct {
+	Model     string          `yaml:"model" json:"model"`           // flat, percentage, tiered, gas
+	Mode      string          `yaml:"mode" json:"mode"`             // deduct（从金额中扣除，默认）, required（源端事件须携带手续费）
+	Flat      string          `yaml:"flat" json:"flat"`             // flat：固定手续费
+	Bps       uint64          `yaml:"bps" json:"bps"`               // percentage：比例（基点，1 bps = 0.01%）
+	Min       string          `yaml:"min" json:"min"`               // percentage：手续费下限
+	Max       string          `yaml:"max" json:"max"`               // percentage：手续费上限
+	Tiers     []FeeTierConfig `yaml:"tiers" json:"tiers"`           // tiered：金额档位
+	GasLimit  uint64          `yaml:"gas_limit" json:"gas_limit"`   // gas：目标端交易 gas 上限
+	GasPrice  string          `yaml:"gas_price" json:"gas_price"`   // gas：gas 价格（wei）
+	MarginBps uint64          `yaml:"margin_bps" json:"margin_bps"` // gas：在 gas 成本上的加成（基点）
+	Quote     *FeeQuoteConfig `yaml:"quote" json:"quote"`           // gas：实时报价，为空时按 gas_limit × gas_price 计算
+}
+
+// FeeQuoteConfig 定义 gas 成本报价：估算目标端 gas，按价格源折算成跨链代币。
+// 配置报价时 gas_limit 作为估算失败时的默认值
+type FeeQuoteConfig struct {
+	Relayer        string            `yaml:"relayer" json:"relayer"`                 // 估算 gas 时使用的中继账户地址
+	NativeDecimals uint8             `yaml:"native_decimals" json:"native_decimals"` // 目标链原生代币精度，为 0 时默认 18
+	TokenDecimals  uint8             `yaml:"token_decimals" json:"token_decimals"`   // 跨链代币精度，为 0 时默认 18
+	TTL            int64             `yaml:"ttl" json:"ttl"`                         // 报价缓存时间（秒）
+	MaxPriceAge    int64             `yaml:"max_price_age" json:"max_price_age"`     // 价格最长有效期（秒），为 0 时不检查
+	Price          PriceSourceConfig `yaml:"price" json:"price"`                     // 价格源
+}
+
+// PriceSourceConfig 定义原生代币兑跨链代币的价格源
+type PriceSourceConfig struct {
+	Type       string `yaml:"type" json:"type"`             // static, chainlink, http
+	Price      string `yaml:"price" json:"price"`           // static：1 个原生代币折合的跨链代币数量，如 "612.35"
+	RPCURL     string `yaml:"rpc_url" json:"rpc_url"`       // chainlink：聚合器所在链的 RPC 地址
+	Aggregator string `yaml:"aggregator" json:"aggregator"` // chainlink：聚合器合约地址
+	URL        string `yaml:"url" json:"url"`               // http：价格服务地址
+	Timeout    int64  `yaml:"timeout" json:"timeout"`       // http：请求超时（毫秒）
+}
+
+// FeeTierConfig 定义分档手续费的一档，手续费 = flat + 金额 × bps / 10000
+type FeeTierConfig struct {
+	UpTo string `yaml:"up_to" json:"up_to"` // 档位金额上限（含），为空时不设上限
+	Flat string `yaml:"flat" json:"flat"`   // 固定部分
+	Bps  uint64 `yaml:"bps" json:"bps"`     // 比例部分（基点）
 }
 
 // PostgresConfig 定义 PostgreSQL 数据库配置
@@ -66,6 +219,12 @@ type PostgresConfig struct {
 	MaxIdleConns int32  `yaml:"max_idle_conns" json:"max_idle_conns"` // 最大空闲连接数
 }
 
+// QueueConfig 定义跨链消息队列的持久化方式
+type QueueConfig struct {
+	Type    string `yaml:"type" json:"type"`         // memory, postgres, wal
+	WALPath string `yaml:"wal_path" json:"wal_path"` // 日志文件路径（type 为 wal 时）
+}
+
 // TraceConfig 定义链路追踪配置
 type TraceConfig struct {
 	Host       string  `yaml:"host" json:"host"`               // Jaeger 服务地址
@@ -86,6 +245,26 @@ type ServerConfig struct {
 	Networks []*NetworkConfig `yaml:"chains" json:"chains"`     // 区块链配置列表
 	Relays   []*RelayConfig   `yaml:"bridges" json:"bridges"`   // 中继器配置列表
 	Postgres *PostgresConfig  `yaml:"postgres" json:"postgres"` // PostgreSQL 配置
+	Queue    *QueueConfig     `yaml:"queue" json:"queue"`       // 消息队列持久化配置
 	Logger   *LogConfig       `yaml:"logger" json:"logger"`     // 日志配置
 	Trace    *TraceConfig     `yaml:"trace" json:"trace"`       // 链路追踪配置
+
+	DrainTimeout int64 `yaml:"drain_timeout" json:"drain_timeout"` // 停止时等待在途交易完成的最长时间（秒），0 表示默认 120 秒
+
+	Holds  *HoldConfig   `yaml:"holds" json:"holds"`   // 人工审批配置
+	Alerts []AlertConfig `yaml:"alerts" json:"alerts"` // 告警渠道，为空时只写入日志
+}
+
+// AlertConfig 定义一个告警渠道
+type AlertConfig struct {
+	Type    string            `yaml:"type" json:"type"`       // log, webhook
+	URL     string            `yaml:"url" json:"url"`         // webhook：告警网关地址，告警以 JSON POST
+	Timeout int64             `yaml:"timeout" json:"timeout"` // webhook：请求超时（毫秒）
+	Headers map[string]string `yaml:"headers" json:"headers"` // webhook：附加请求头
+}
+
+// HoldConfig 定义挂起消息的人工审批规则
+type HoldConfig struct {
+	Approvals int   `yaml:"approvals" json:"approvals"` // 放行所需的不同运维批准数，为 0 时默认 1
+	TTL       int64 `yaml:"ttl" json:"ttl"`             // 审批期限（秒），为 0 时不过期
 }
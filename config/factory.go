@@ -1,30 +1,209 @@
 package server
 
 import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/ethclient"
+
 	"github.com/st-chain/me-bridge/chain"
+	"github.com/st-chain/me-bridge/chain/bsc"
+	"github.com/st-chain/me-bridge/db"
 	"github.com/st-chain/me-bridge/relay"
 	"github.com/st-chain/me-bridge/server"
+	"github.com/st-chain/me-bridge/types"
 )
 
-func NewServerWithConfig(config *ServerConfig) *server.Server {
+// components 是所有跨链桥共用的组件，由 NewServerWithConfig 创建一次
+type components struct {
+	store       db.Store               // 持久化存储，为 nil 时队列和限流用量只保存在内存中
+	states      *relay.StateMachine    // 为 nil 时只依赖链上检查去重
+	deadLetters *relay.DeadLetterQueue // 为 nil 时失败的消息只记录日志
+	holds       *relay.HoldQueue       // 为 nil 时需要审批的消息进入死信
+	gas         map[string]*relay.GasPolicy
+	networks    map[string]*NetworkConfig // 网络名称 -> 节点配置
+}
+
+// defaultMonitorInterval 是终端检查节点健康状态的间隔
+const defaultMonitorInterval = 30 * time.Second
+
+func NewServerWithConfig(config *ServerConfig) (*server.Server, error) {
+	store, err := NewStoreWithConfig(config.Queue, config.Postgres)
+	if err != nil {
+		return nil, err
+	}
+	shared := &components{
+		store:    store,
+		gas:      make(map[string]*relay.GasPolicy),
+		networks: make(map[string]*NetworkConfig),
+	}
+	if store != nil {
+		shared.states = relay.NewStateMachine(store)
+		shared.deadLetters = relay.NewDeadLetterQueue(store)
+	}
+	for _, netConfig := range config.Networks {
+		shared.networks[netConfig.Name] = netConfig
+	}
+	var operators int
+	if config.API != nil {
+		operators = len(config.API.Operators)
+	}
+	if shared.holds, err = NewHoldQueueWithConfig(config.Holds, operators, store); err != nil {
+		return nil, err
+	}
+
 	for _, netConfig := range config.Networks {
-		chain.ClientsBuilder(netConfig.Name, netConfig.ClientConfigs)
+		if netConfig.Gas == nil {
+			continue
+		}
+		// gas 价格只影响提交到该链的消息，通过以该链为目标端的跨链桥查询
+		var target *EndpointConfig
+		for _, relayConfig := range config.Relays {
+			if relayConfig.Target.Network == netConfig.Name {
+				target = &relayConfig.Target
+				break
+			}
+		}
+		if target == nil {
+			return nil, fmt.Errorf("network %s has a gas policy but no bridge targets it", netConfig.Name)
+		}
+		prices, err := NewOutEndpointWithConfig(netConfig, target)
+		if err != nil {
+			return nil, err
+		}
+		policy, err := NewGasPolicyWithConfig(netConfig.Name, netConfig.Gas, prices)
+		if err != nil {
+			return nil, err
+		}
+		shared.gas[netConfig.Name] = policy
 	}
 
 	relays := make(map[string]*relay.Relay)
+	var balances []*relay.BalanceMonitor
 	for _, relayConfig := range config.Relays {
-		relay := NewRelayWithConfig(relayConfig)
+		relay, err := NewRelayWithConfig(relayConfig, shared)
+		if err != nil {
+			return nil, fmt.Errorf("relay %s: %w", relayConfig.Name, err)
+		}
 		relays[relayConfig.Name] = relay
+		if relay.SourceFunds != nil {
+			balances = append(balances, relay.SourceFunds)
+		}
+		if relay.TargetFunds != nil {
+			balances = append(balances, relay.TargetFunds)
+		}
+	}
+
+	alerter, err := NewAlerterWithConfig(config.Alerts)
+	if err != nil {
+		return nil, err
 	}
 
 	return &server.Server{
-		Relays: relays,
+		Relays:       relays,
+		DeadLetters:  shared.deadLetters,
+		Holds:        shared.holds,
+		Messages:     shared.states,
+		Controls:     store,
+		Audit:        store,
+		Balances:     balances,
+		Gas:          shared.gas,
+		Alerter:      alerter,
+		DrainTimeout: time.Duration(config.DrainTimeout) * time.Second,
+	}, nil
+}
+
+// NewStoreWithConfig 根据队列配置打开持久化存储，type 为空或 memory 时返回 nil（只保存在内存中）
+func NewStoreWithConfig(config *QueueConfig, postgres *PostgresConfig) (db.Store, error) {
+	if config == nil {
+		return nil, nil
+	}
+	switch config.Type {
+	case "", "memory":
+		return nil, nil
+	case "wal":
+		if config.WALPath == "" {
+			return nil, fmt.Errorf("queue wal_path is required")
+		}
+		store, err := db.OpenWALStore(config.WALPath)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "postgres":
+		if postgres == nil {
+			return nil, fmt.Errorf("queue type postgres requires the postgres block")
+		}
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			postgres.Host, postgres.Port, postgres.User, postgres.Password, postgres.Database)
+		conn, err := db.OpenPostgres(dsn, int(postgres.MaxOpenConns), int(postgres.MaxIdleConns))
+		if err != nil {
+			return nil, err
+		}
+		return db.NewPostgresStore(conn, time.Duration(postgres.Timeout)*time.Millisecond), nil
+	default:
+		return nil, fmt.Errorf("unknown queue type %q", config.Type)
 	}
 }
 
-func NewRelayWithConfig(config *RelayConfig) *relay.Relay {
-	source := NewInEndpointWithConfig(config.Source)
-	target := NewOutEndpointWithConfig(config.Target)
+// NewRelayWithConfig 根据配置创建跨链桥，shared 为所有跨链桥共用的存储、状态机、死信队列和 gas 价格策略
+func NewRelayWithConfig(config *RelayConfig, shared *components) (*relay.Relay, error) {
+	source, err := NewInEndpointWithConfig(shared.networks[config.Source.Network], &config.Source)
+	if err != nil {
+		return nil, err
+	}
+	target, err := NewOutEndpointWithConfig(shared.networks[config.Target.Network], &config.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := NewTokenRegistryWithConfig(config.Tokens)
+	if err != nil {
+		return nil, err
+	}
+	var quoter *relay.FeeQuoter
+	if config.Fee != nil && config.Fee.Quote != nil {
+		q, err := NewFeeQuoterWithConfig(config.Fee.Quote, config.Fee.GasLimit, target)
+		if err != nil {
+			return nil, err
+		}
+		quoter = q
+	}
+	feeCalculator, err := NewFeeCalculatorWithConfig(config.Fee, quoter)
+	if err != nil {
+		return nil, err
+	}
+	limits, err := NewRateLimiterWithConfig(config.Name, config.RateLimits, shared.store)
+	if err != nil {
+		return nil, err
+	}
+	screener, err := NewScreenerWithConfig(config.Screening)
+	if err != nil {
+		return nil, err
+	}
+	reconciler, err := NewReconcilerWithConfig(config, tokens, shared.store)
+	if err != nil {
+		return nil, err
+	}
+	reader, _ := any(source).(relay.SourceReader)
+	verifier, err := NewSourceVerifierWithConfig(config, reader)
+	if err != nil {
+		return nil, err
+	}
+	sourceReader, _ := any(source).(relay.BalanceReader)
+	sourceFunds, err := NewBalanceMonitorWithConfig(config.Source.Network, config.Source.Balance, sourceReader)
+	if err != nil {
+		return nil, err
+	}
+	targetReader, _ := any(target).(relay.BalanceReader)
+	targetFunds, err := NewBalanceMonitorWithConfig(config.Target.Network, config.Target.Balance, targetReader)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Relay{
 		Source:   source,
@@ -32,12 +211,533 @@ func NewRelayWithConfig(config *RelayConfig) *relay.Relay {
 		InChan:   make(chan *Message, 1000),
 		BackChan: make(chan *Message, 1000),
 
-		FeeCalculator: &FeeCalculator{},
+		FeeCalculator: feeCalculator,
+		Tokens:        tokens,
+		Limits:        limits,
+		Screener:      screener,
+		Reconciler:    reconciler,
+		Verifier:      verifier,
+		SourceFunds:   sourceFunds,
+		TargetFunds:   targetFunds,
+		Gas:           shared.gas[config.Target.Network],
+
+		Store:       shared.store,
+		States:      shared.states,
+		DeadLetters: shared.deadLetters,
+		Holds:       shared.holds,
+	}, nil
+}
+
+// NewFeeCalculatorWithConfig 根据手续费配置创建 FeeCalculator，未配置时返回 nil（不收取手续费）。
+// gas 模型在 quoter 不为 nil 时使用实时报价，否则按固定的 gas_limit × gas_price 计算
+func NewFeeCalculatorWithConfig(config *FeeConfig, quoter *relay.FeeQuoter) (*relay.FeeCalculator, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	fc := &relay.FeeCalculator{
+		Model:     relay.FeeModel(config.Model),
+		Mode:      relay.FeeMode(config.Mode),
+		Bps:       config.Bps,
+		MarginBps: config.MarginBps,
+	}
+	var err error
+	if fc.Flat, err = parseAmount("flat", config.Flat); err != nil {
+		return nil, err
+	}
+	if fc.Min, err = parseAmount("min", config.Min); err != nil {
+		return nil, err
+	}
+	if fc.Max, err = parseAmount("max", config.Max); err != nil {
+		return nil, err
+	}
+	for i, tierConfig := range config.Tiers {
+		tier := relay.FeeTier{Bps: tierConfig.Bps}
+		if tier.UpTo, err = parseAmount(fmt.Sprintf("tiers[%d].up_to", i), tierConfig.UpTo); err != nil {
+			return nil, err
+		}
+		if tier.Flat, err = parseAmount(fmt.Sprintf("tiers[%d].flat", i), tierConfig.Flat); err != nil {
+			return nil, err
+		}
+		fc.Tiers = append(fc.Tiers, tier)
+	}
+	if fc.Model == relay.FeeModelGas {
+		if quoter != nil {
+			fc.Gas = quoter
+		} else {
+			gasPrice, err := parseAmount("gas_price", config.GasPrice)
+			if err != nil {
+				return nil, err
+			}
+			fc.Gas = relay.StaticGasCost{GasLimit: config.GasLimit, GasPrice: gasPrice}
+		}
+	}
+
+	if err := fc.Validate(); err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+// NewFeeQuoterWithConfig 根据报价配置创建 FeeQuoter，estimator 为目标端，gasLimit 为估算失败时的默认值
+func NewFeeQuoterWithConfig(config *FeeQuoteConfig, gasLimit uint64, estimator relay.GasEstimator) (*relay.FeeQuoter, error) {
+	prices, err := NewPriceSourceWithConfig(&config.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	quoter := relay.NewFeeQuoter(estimator, prices)
+	quoter.From = config.Relayer
+	quoter.Sample = relay.InMsg{Receiver: config.Relayer, Amount: "1"}
+	quoter.GasLimit = gasLimit
+	quoter.MaxPriceAge = time.Duration(config.MaxPriceAge) * time.Second
+	if config.NativeDecimals > 0 {
+		quoter.NativeDecimals = config.NativeDecimals
+	}
+	if config.TokenDecimals > 0 {
+		quoter.TokenDecimals = config.TokenDecimals
+	}
+	if config.TTL > 0 {
+		quoter.TTL = time.Duration(config.TTL) * time.Second
+	}
+	return quoter, nil
+}
+
+// NewPriceSourceWithConfig 根据价格源配置创建 PriceSource
+func NewPriceSourceWithConfig(config *PriceSourceConfig) (relay.PriceSource, error) {
+	switch config.Type {
+	case "static":
+		answer, decimals, err := relay.ParsePrice(config.Price)
+		if err != nil {
+			return nil, err
+		}
+		return relay.StaticPrice{Answer: answer, Decimals: decimals}, nil
+	case "chainlink":
+		if !common.IsHexAddress(config.Aggregator) {
+			return nil, fmt.Errorf("%w: invalid aggregator %q", relay.ErrInvalidFeeConfig, config.Aggregator)
+		}
+		client, err := ethclient.Dial(config.RPCURL)
+		if err != nil {
+			return nil, err
+		}
+		return relay.NewChainlinkPrice(client, common.HexToAddress(config.Aggregator)), nil
+	case "http":
+		if config.URL == "" {
+			return nil, fmt.Errorf("%w: price url is required", relay.ErrInvalidFeeConfig)
+		}
+		return &relay.HTTPPrice{
+			URL:    config.URL,
+			Client: &http.Client{Timeout: time.Duration(config.Timeout) * time.Millisecond},
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown price source %q", relay.ErrInvalidFeeConfig, config.Type)
+	}
+}
+
+// NewTokenRegistryWithConfig 根据代币配置创建代币登记表，未配置时返回 nil（不换算金额）
+func NewTokenRegistryWithConfig(configs []TokenConfig) (*relay.TokenRegistry, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	tokens := make([]relay.Token, 0, len(configs))
+	for _, config := range configs {
+		minAmount, err := parseAmount(config.Symbol+".min", config.Min)
+		if err != nil {
+			return nil, err
+		}
+		maxAmount, err := parseAmount(config.Symbol+".max", config.Max)
+		if err != nil {
+			return nil, err
+		}
+		threshold, err := parseAmount(config.Symbol+".approval_threshold", config.ApprovalThreshold)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, relay.Token{
+			Symbol:            config.Symbol,
+			Source:            config.Source,
+			Target:            config.Target,
+			SourceDecimals:    config.SourceDecimals,
+			TargetDecimals:    config.TargetDecimals,
+			Min:               minAmount,
+			Max:               maxAmount,
+			ApprovalThreshold: threshold,
+		})
+	}
+	return relay.NewTokenRegistry(tokens)
+}
+
+// NewRateLimiterWithConfig 根据限流配置创建跨链桥 name 的限流器，未配置时返回 nil（不限流）。
+// store 为 nil 时用量只保存在内存中
+func NewRateLimiterWithConfig(name string, configs []RateLimitConfig, store types.RateLimitStore) (*relay.RateLimiter, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	limits := make([]relay.RateLimit, 0, len(configs))
+	for i, config := range configs {
+		maxValue, err := parseAmount(fmt.Sprintf("rate_limits[%d].max_value", i), config.MaxValue)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, relay.RateLimit{
+			Scope:    relay.LimitScope(config.Scope),
+			Token:    config.Token,
+			Window:   time.Duration(config.Window) * time.Second,
+			MaxValue: maxValue,
+			MaxCount: config.MaxCount,
+		})
+	}
+	return relay.NewRateLimiter(name, limits, store)
+}
+
+// NewHoldQueueWithConfig 根据审批配置创建挂起队列，未配置时返回 nil（需要审批的消息进入死信）。
+// operators 为可审批的运维数（M-of-N 中的 N），必须不少于放行所需的批准数；挂起消息需要持久化存储
+func NewHoldQueueWithConfig(config *HoldConfig, operators int, store types.HoldStore) (*relay.HoldQueue, error) {
+	if config == nil {
+		return nil, nil
+	}
+	if config.Approvals < 0 || max(config.Approvals, 1) > operators {
+		return nil, fmt.Errorf("invalid holds.approvals %d for %d operators", config.Approvals, operators)
+	}
+	if store == nil {
+		return nil, fmt.Errorf("holds require a persistent queue store")
+	}
+	holds := relay.NewHoldQueue(store)
+	holds.Approvals = config.Approvals
+	holds.TTL = time.Duration(config.TTL) * time.Second
+	return holds, nil
+}
+
+// NewScreenerWithConfig 根据筛查配置创建地址筛查器，未配置时返回 nil（不筛查）
+func NewScreenerWithConfig(configs []ScreenerConfig) (relay.Screener, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	screeners := make(relay.Screeners, 0, len(configs))
+	for _, config := range configs {
+		switch config.Type {
+		case "denylist":
+			denylist, err := relay.NewDenylist(config.Path)
+			if err != nil {
+				return nil, err
+			}
+			if config.Interval > 0 {
+				denylist.Interval = time.Duration(config.Interval) * time.Second
+			}
+			screeners = append(screeners, denylist)
+		case "http":
+			if config.URL == "" {
+				return nil, fmt.Errorf("%w: screening url is required", relay.ErrInvalidScreeningConfig)
+			}
+			header := make(http.Header)
+			for key, value := range config.Headers {
+				header.Set(key, value)
+			}
+			screeners = append(screeners, &relay.HTTPScreener{
+				URL:    config.URL,
+				Header: header,
+				Client: &http.Client{Timeout: time.Duration(config.Timeout) * time.Millisecond},
+			})
+		default:
+			return nil, fmt.Errorf("%w: unknown screener %q", relay.ErrInvalidScreeningConfig, config.Type)
+		}
+	}
+	return screeners, nil
+}
+
+// NewReconcilerWithConfig 根据对账配置创建跨链桥的对账器，未配置时返回 nil（不对账）。
+// 对账代币登记表中的全部代币，ledger 为 nil 时不与账本比较
+func NewReconcilerWithConfig(config *RelayConfig, tokens *relay.TokenRegistry, ledger types.LedgerStore) (*relay.Reconciler, error) {
+	rc := config.Reconcile
+	if rc == nil {
+		return nil, nil
+	}
+	switch server.ControlAction(rc.AutoPause) {
+	case "", server.ActionPauseInbound, server.ActionPauseOutbound, server.ActionEmergencyStop:
+	default:
+		return nil, fmt.Errorf("%w: invalid auto_pause %q", relay.ErrInvalidReconcileConfig, rc.AutoPause)
+	}
+
+	known := make(map[string]bool)
+	for _, token := range tokens.Tokens() {
+		known[token.Symbol] = true
+	}
+	params := make(map[string]ReconcileTokenConfig, len(rc.Tokens))
+	for _, t := range rc.Tokens {
+		if !known[t.Symbol] {
+			return nil, fmt.Errorf("%w: unknown token %q", relay.ErrInvalidReconcileConfig, t.Symbol)
+		}
+		params[t.Symbol] = t
+	}
+	var list []relay.ReconcileToken
+	for _, token := range tokens.Tokens() {
+		p := params[token.Symbol]
+		tolerance, err := parseAmount(token.Symbol+".tolerance", p.Tolerance)
+		if err != nil {
+			return nil, err
+		}
+		baseline, err := parseAmount(token.Symbol+".baseline", p.Baseline)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, relay.ReconcileToken{Token: token, Tolerance: tolerance, Baseline: baseline})
+	}
+
+	escrow := rc.Escrow
+	if escrow == "" {
+		escrow = config.Source.ContractAddress
+	}
+	if !common.IsHexAddress(escrow) {
+		return nil, fmt.Errorf("%w: invalid escrow %q", relay.ErrInvalidReconcileConfig, escrow)
+	}
+	sourceClient, err := ethclient.Dial(rc.SourceRPCURL)
+	if err != nil {
+		return nil, err
+	}
+	targetClient, err := ethclient.Dial(rc.TargetRPCURL)
+	if err != nil {
+		return nil, err
+	}
+	locked := &relay.ERC20Balance{Caller: sourceClient, Holder: common.HexToAddress(escrow)}
+
+	mode := relay.ReleaseMode(rc.Mode)
+	if mode == "" {
+		mode = relay.ReleaseSupply
+	}
+	var released relay.AmountReader = &relay.ERC20Supply{Caller: targetClient}
+	if mode == relay.ReleaseEscrow {
+		holder := rc.TargetEscrow
+		if holder == "" {
+			holder = config.Target.ContractAddress
+		}
+		if !common.IsHexAddress(holder) {
+			return nil, fmt.Errorf("%w: invalid target_escrow %q", relay.ErrInvalidReconcileConfig, holder)
+		}
+		released = &relay.ERC20Balance{Caller: targetClient, Holder: common.HexToAddress(holder)}
+	}
+
+	reconciler, err := relay.NewReconciler(config.Name, list, locked, released, mode)
+	if err != nil {
+		return nil, err
+	}
+	reconciler.Ledger = ledger
+	if rc.Interval > 0 {
+		reconciler.Interval = time.Duration(rc.Interval) * time.Second
+	}
+	return reconciler, nil
+}
+
+// NewSourceVerifierWithConfig 根据配置创建源端事件核对器，未开启时返回 nil（不核对）。
+// 要求的确认数为 source.confirm_blocks，源端终端须支持在其他节点上重新读取交易收据
+func NewSourceVerifierWithConfig(config *RelayConfig, reader relay.SourceReader) (*relay.SourceVerifier, error) {
+	if !config.VerifySource {
+		return nil, nil
+	}
+	if reader == nil {
+		return nil, fmt.Errorf("relay %s: source endpoint %s cannot read receipts", config.Name, config.Source.Network)
+	}
+	if config.Source.ConfirmBlocks < 0 {
+		return nil, fmt.Errorf("relay %s: invalid source confirm_blocks %d", config.Name, config.Source.ConfirmBlocks)
+	}
+	return relay.NewSourceVerifier(reader, uint64(config.Source.ConfirmBlocks)), nil
+}
+
+// NewBalanceMonitorWithConfig 根据配置创建 network 上中继账户的余额监控，未配置时返回 nil（不监控）
+func NewBalanceMonitorWithConfig(network string, config *BalanceConfig, reader relay.BalanceReader) (*relay.BalanceMonitor, error) {
+	if config == nil {
+		return nil, nil
+	}
+	if reader == nil {
+		return nil, fmt.Errorf("%w: %s endpoint cannot read balances", relay.ErrInvalidBalanceConfig, network)
+	}
+	warning, err := parseAmount("balance warning", config.Warning)
+	if err != nil {
+		return nil, err
+	}
+	critical, err := parseAmount("balance critical", config.Critical)
+	if err != nil {
+		return nil, err
+	}
+	monitor, err := relay.NewBalanceMonitor(network, config.Account, reader, warning, critical)
+	if err != nil {
+		return nil, err
+	}
+	monitor.MinRunway = time.Duration(config.MinRunway) * time.Second
+	if config.Window > 0 {
+		monitor.Window = time.Duration(config.Window) * time.Second
+	}
+	if config.Interval > 0 {
+		monitor.Interval = time.Duration(config.Interval) * time.Second
+	}
+	return monitor, nil
+}
+
+// NewGasPolicyWithConfig 根据配置创建 network 的 gas 价格策略，prices 为该链的 gas 价格来源
+func NewGasPolicyWithConfig(network string, config *GasPolicyConfig, prices relay.GasEstimator) (*relay.GasPolicy, error) {
+	maxGasPrice, err := parseAmount("max gas price", config.MaxGasPrice)
+	if err != nil {
+		return nil, err
 	}
+	urgentValue, err := parseAmount("urgent value", config.UrgentValue)
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxDelay < 0 || config.Interval < 0 || config.History < 0 {
+		return nil, fmt.Errorf("%w: %s: max_delay, interval and history must not be negative", relay.ErrInvalidGasPolicy, network)
+	}
+	policy, err := relay.NewGasPolicy(network, prices, maxGasPrice, config.MaxFeeBps)
+	if err != nil {
+		return nil, err
+	}
+	policy.UrgentValue = urgentValue
+	if config.MaxDelay > 0 {
+		policy.MaxDelay = time.Duration(config.MaxDelay) * time.Second
+	}
+	if config.Interval > 0 {
+		policy.Interval = time.Duration(config.Interval) * time.Second
+	}
+	if config.History > 0 {
+		policy.HistorySize = config.History
+	}
+	return policy, nil
+}
+
+// NewAlerterWithConfig 根据告警配置创建告警渠道，未配置时只写入日志
+func NewAlerterWithConfig(configs []AlertConfig) (relay.Alerter, error) {
+	if len(configs) == 0 {
+		return relay.LogAlerter{}, nil
+	}
+	alerters := make(relay.Alerters, 0, len(configs))
+	for _, config := range configs {
+		switch config.Type {
+		case "log":
+			alerters = append(alerters, relay.LogAlerter{})
+		case "webhook":
+			if config.URL == "" {
+				return nil, fmt.Errorf("alert webhook url is required")
+			}
+			header := make(http.Header)
+			for key, value := range config.Headers {
+				header.Set(key, value)
+			}
+			alerters = append(alerters, &relay.WebhookAlerter{
+				URL:    config.URL,
+				Header: header,
+				Client: &http.Client{Timeout: time.Duration(config.Timeout) * time.Millisecond},
+			})
+		default:
+			return nil, fmt.Errorf("unknown alert type %q", config.Type)
+		}
+	}
+	return alerters, nil
+}
+
+// parseAmount 解析十进制或 0x 开头的十六进制金额，空字符串返回 nil
+func parseAmount(field, s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, ok := math.ParseBig256(s)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s %q", field, s)
+	}
+	return v, nil
+}
+
+// NewInEndpointWithConfig 根据跨链桥的源端配置创建终端，节点来自 network 的配置，
+// 节点客户端以 contract_address 为跨链合约
+func NewInEndpointWithConfig(network *NetworkConfig, config *EndpointConfig) (*chain.InEndpoint, error) {
+	dial, quorum, err := newClientDialer(network, config)
+	if err != nil {
+		return nil, err
+	}
+	inDial := func(node *chain.ClientConfig) (chain.InClient, error) { return dial(node) }
+	clients, err := dialClients(network, inDial)
+	if err != nil {
+		return nil, err
+	}
+	ep := chain.NewInEndpoint(&relay.EndpointConfig{Network: config.Network}, clients, defaultMonitorInterval)
+	ep.Quorum = quorum
+	ep.Dialer = inDial
+	return ep, nil
 }
 
-func NewInEndpointWithConfig(config *EndpointConfig) *relay.InEndpoiont {
-	return &relay.InEndpoiont{
-		Clients: ClientsProvider(config.Network),
+// NewOutEndpointWithConfig 根据跨链桥的目标端配置创建终端，节点来自 network 的配置，
+// 节点客户端以 contract_address 为跨链合约
+func NewOutEndpointWithConfig(network *NetworkConfig, config *EndpointConfig) (*chain.OutEndpoint, error) {
+	dial, quorum, err := newClientDialer(network, config)
+	if err != nil {
+		return nil, err
+	}
+	outDial := func(node *chain.ClientConfig) (chain.OutClient, error) { return dial(node) }
+	clients, err := dialClients(network, outDial)
+	if err != nil {
+		return nil, err
+	}
+	ep := chain.NewOutEndpoint(&relay.EndpointConfig{Network: config.Network}, clients, defaultMonitorInterval)
+	ep.Quorum = quorum
+	ep.Dialer = outDial
+	return ep, nil
+}
+
+// newClientDialer 返回连接 network 节点的函数和该网络的一致性查询参数。
+// 合约地址无效或为零地址时返回错误，避免启动后合约查询和调用都发往零地址
+func newClientDialer(network *NetworkConfig, config *EndpointConfig) (func(*chain.ClientConfig) (*bsc.Client, error), chain.QuorumConfig, error) {
+	if network == nil {
+		return nil, chain.QuorumConfig{}, fmt.Errorf("network %q is not configured", config.Network)
+	}
+	contract := common.HexToAddress(config.ContractAddress)
+	if !common.IsHexAddress(config.ContractAddress) || contract == (common.Address{}) {
+		return nil, chain.QuorumConfig{}, fmt.Errorf("%s contract_address %q is invalid", config.Network, config.ContractAddress)
+	}
+	quorum, err := NewQuorumConfigWithConfig(network.Quorum)
+	if err != nil {
+		return nil, chain.QuorumConfig{}, fmt.Errorf("network %s: %w", network.Name, err)
+	}
+
+	netConfig := &chain.NetworkConfig{
+		Name:          network.Name,
+		ChainID:       network.ChainID,
+		MaxConns:      network.MaxConns,
+		Timeout:       network.Timeout,
+		MaxRetries:    network.MaxRetries,
+		RetryInterval: network.RetryInterval,
+	}
+	return func(node *chain.ClientConfig) (*bsc.Client, error) {
+		return bsc.NewClient(netConfig, node, contract)
+	}, quorum, nil
+}
+
+// dialClients 连接网络配置中的所有节点，任一节点连接失败时返回错误
+func dialClients[T any](network *NetworkConfig, dial func(*chain.ClientConfig) (T, error)) ([]T, error) {
+	clients := make([]T, 0, len(network.ClientConfigs))
+	for _, node := range network.ClientConfigs {
+		client, err := dial(&chain.ClientConfig{Name: node.Name, GRPCURL: node.GRPCURL, RPCURL: node.RPCURL, WSURL: node.WSURL})
+		if err != nil {
+			return nil, fmt.Errorf("network %s node %s: %w", network.Name, node.Name, err)
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+// NewQuorumConfigWithConfig 根据配置创建一致性查询参数，未配置时返回默认值（3 个节点中 2 个一致）。
+// 终端按配置的节点总数限制 size 和 threshold，单节点网络不需要单独配置
+func NewQuorumConfigWithConfig(config *QuorumConfig) (chain.QuorumConfig, error) {
+	quorum := chain.DefaultQuorumConfig()
+	if config == nil {
+		return quorum, nil
+	}
+	if config.Threshold <= 0 {
+		return quorum, fmt.Errorf("quorum threshold must be positive")
+	}
+	if config.Size != 0 && config.Size < config.Threshold {
+		return quorum, fmt.Errorf("quorum size %d is less than threshold %d", config.Size, config.Threshold)
+	}
+	quorum.Size = config.Size
+	quorum.Threshold = config.Threshold
+	if config.Timeout > 0 {
+		quorum.Timeout = time.Duration(config.Timeout) * time.Millisecond
 	}
+	return quorum, nil
 }
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+var _ types.ControlStore = (*PostgresStore)(nil)
+
+const controlColumns = `bridge, inbound_paused, outbound_paused, emergency_stop, reason, operator, updated_at`
+
+func scanControl(row interface{ Scan(...any) error }) (*types.BridgeControl, error) {
+	var c types.BridgeControl
+	if err := row.Scan(&c.Bridge, &c.InboundPaused, &c.OutboundPaused, &c.EmergencyStop,
+		&c.Reason, &c.Operator, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetControl 返回跨链桥的控制状态，不存在时返回 nil
+func (s *PostgresStore) GetControl(bridge string) (*types.BridgeControl, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	c, err := scanControl(s.db.QueryRowContext(ctx,
+		`SELECT `+controlColumns+` FROM relay_bridge_controls WHERE bridge = $1`, bridge))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return c, err
+}
+
+// SaveControl 写入或覆盖跨链桥的控制状态
+func (s *PostgresStore) SaveControl(c types.BridgeControl) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO relay_bridge_controls (`+controlColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (bridge) DO UPDATE SET
+			inbound_paused = EXCLUDED.inbound_paused,
+			outbound_paused = EXCLUDED.outbound_paused,
+			emergency_stop = EXCLUDED.emergency_stop,
+			reason = EXCLUDED.reason,
+			operator = EXCLUDED.operator,
+			updated_at = EXCLUDED.updated_at`,
+		c.Bridge, c.InboundPaused, c.OutboundPaused, c.EmergencyStop, c.Reason, c.Operator, c.UpdatedAt)
+	return err
+}
+
+// ListControls 按名称返回所有已记录的控制状态
+func (s *PostgresStore) ListControls() ([]types.BridgeControl, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+controlColumns+` FROM relay_bridge_controls ORDER BY bridge`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []types.BridgeControl
+	for rows.Next() {
+		c, err := scanControl(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+var _ types.DeadLetterStore = (*PostgresStore)(nil)
+
+// SaveDeadLetter 写入或覆盖死信
+func (s *PostgresStore) SaveDeadLetter(dl types.DeadLetter) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	errs, err := json.Marshal(dl.Errors)
+	if err != nil {
+		return err
+	}
+	var fee []byte
+	if dl.FeePolicy != nil {
+		if fee, err = json.Marshal(dl.FeePolicy); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO relay_dead_letters
+			(id, queue, nonce, payload, errors, attempts, last_tx_hash, fee_policy, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id) DO UPDATE SET
+			payload = EXCLUDED.payload,
+			errors = EXCLUDED.errors,
+			attempts = EXCLUDED.attempts,
+			last_tx_hash = EXCLUDED.last_tx_hash,
+			fee_policy = EXCLUDED.fee_policy,
+			status = EXCLUDED.status,
+			updated_at = EXCLUDED.updated_at`,
+		dl.ID, dl.Queue, dl.Nonce, []byte(dl.Payload), errs, dl.Attempts, dl.LastTxHash, fee,
+		dl.Status, dl.CreatedAt, dl.UpdatedAt)
+	return err
+}
+
+const deadLetterColumns = `id, queue, nonce, payload, errors, attempts, last_tx_hash, fee_policy, status, created_at, updated_at`
+
+func scanDeadLetter(row interface{ Scan(...any) error }) (*types.DeadLetter, error) {
+	var (
+		dl      types.DeadLetter
+		payload []byte
+		errs    []byte
+		fee     []byte
+	)
+	if err := row.Scan(&dl.ID, &dl.Queue, &dl.Nonce, &payload, &errs, &dl.Attempts, &dl.LastTxHash,
+		&fee, &dl.Status, &dl.CreatedAt, &dl.UpdatedAt); err != nil {
+		return nil, err
+	}
+	dl.Payload = payload
+	if err := json.Unmarshal(errs, &dl.Errors); err != nil {
+		return nil, err
+	}
+	if fee != nil {
+		dl.FeePolicy = &types.FeePolicy{}
+		if err := json.Unmarshal(fee, dl.FeePolicy); err != nil {
+			return nil, err
+		}
+	}
+	return &dl, nil
+}
+
+// GetDeadLetter 返回死信，不存在时返回 nil
+func (s *PostgresStore) GetDeadLetter(id string) (*types.DeadLetter, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	dl, err := scanDeadLetter(s.db.QueryRowContext(ctx,
+		`SELECT `+deadLetterColumns+` FROM relay_dead_letters WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return dl, err
+}
+
+// ListDeadLetters 按创建时间返回指定状态的死信，status 为空时返回全部
+func (s *PostgresStore) ListDeadLetters(status types.DeadLetterStatus) ([]types.DeadLetter, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+deadLetterColumns+` FROM relay_dead_letters
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []types.DeadLetter
+	for rows.Next() {
+		dl, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *dl)
+	}
+	return out, rows.Err()
+}
+
+// AppendAudit 追加一条审计日志
+func (s *PostgresStore) AppendAudit(entry types.AuditEntry) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO relay_audit_log (time, operator, action, target, detail, result)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		entry.Time, entry.Operator, entry.Action, entry.Target, entry.Detail, entry.Result)
+	return err
+}
+
+// ListAudit 按时间返回操作对象的审计日志，target 为空时返回全部
+func (s *PostgresStore) ListAudit(target string) ([]types.AuditEntry, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT time, operator, action, target, detail, result FROM relay_audit_log
+		WHERE $1 = '' OR target = $1
+		ORDER BY id`, target)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []types.AuditEntry
+	for rows.Next() {
+		var e types.AuditEntry
+		if err := rows.Scan(&e.Time, &e.Operator, &e.Action, &e.Target, &e.Detail, &e.Result); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+var _ types.HoldStore = (*PostgresStore)(nil)
+
+const holdColumns = `id, queue, nonce, payload, reason, status, required, approvals, expires_at, created_at, updated_at`
+
+func scanHold(row interface{ Scan(...any) error }) (*types.HeldMessage, error) {
+	var (
+		h         types.HeldMessage
+		payload   []byte
+		approvals []byte
+		expiresAt sql.NullTime
+	)
+	if err := row.Scan(&h.ID, &h.Queue, &h.Nonce, &payload, &h.Reason, &h.Status, &h.Required, &approvals,
+		&expiresAt, &h.CreatedAt, &h.UpdatedAt); err != nil {
+		return nil, err
+	}
+	h.Payload = payload
+	if err := json.Unmarshal(approvals, &h.Approvals); err != nil {
+		return nil, err
+	}
+	h.ExpiresAt = expiresAt.Time
+	return &h, nil
+}
+
+// SaveHold 写入或覆盖挂起消息
+func (s *PostgresStore) SaveHold(h types.HeldMessage) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	approvals := []types.HoldApproval{}
+	if h.Approvals != nil {
+		approvals = h.Approvals
+	}
+	data, err := json.Marshal(approvals)
+	if err != nil {
+		return err
+	}
+	expiresAt := sql.NullTime{Time: h.ExpiresAt, Valid: !h.ExpiresAt.IsZero()}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO relay_held_messages (`+holdColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id) DO UPDATE SET
+			payload = EXCLUDED.payload,
+			reason = EXCLUDED.reason,
+			status = EXCLUDED.status,
+			required = EXCLUDED.required,
+			approvals = EXCLUDED.approvals,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = EXCLUDED.updated_at`,
+		h.ID, h.Queue, h.Nonce, []byte(h.Payload), h.Reason, h.Status, h.Required, data, expiresAt,
+		h.CreatedAt, h.UpdatedAt)
+	return err
+}
+
+// GetHold 返回挂起消息，不存在时返回 nil
+func (s *PostgresStore) GetHold(id string) (*types.HeldMessage, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	h, err := scanHold(s.db.QueryRowContext(ctx, `SELECT `+holdColumns+` FROM relay_held_messages WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return h, err
+}
+
+// ListHolds 按创建时间返回指定状态的挂起消息，status 为空时返回全部
+func (s *PostgresStore) ListHolds(status types.HoldStatus) ([]types.HeldMessage, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+holdColumns+` FROM relay_held_messages
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []types.HeldMessage
+	for rows.Next() {
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *h)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+var _ types.LedgerStore = (*PostgresStore)(nil)
+
+// SaveLedgerEntry 写入或覆盖账本记录
+func (s *PostgresStore) SaveLedgerEntry(e types.LedgerEntry) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO relay_ledger (id, bridge, token, amount, completed, updated_at)
+		VALUES ($1, $2, $3, $4::NUMERIC, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			bridge = EXCLUDED.bridge,
+			token = EXCLUDED.token,
+			amount = EXCLUDED.amount,
+			completed = EXCLUDED.completed,
+			updated_at = EXCLUDED.updated_at`,
+		e.ID, e.Bridge, e.Token, e.Amount, e.Completed, e.UpdatedAt)
+	return err
+}
+
+// GetLedgerEntry 返回账本记录，不存在时返回 nil
+func (s *PostgresStore) GetLedgerEntry(id string) (*types.LedgerEntry, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	var e types.LedgerEntry
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, bridge, token, amount::TEXT, completed, updated_at
+		FROM relay_ledger WHERE id = $1`, id).
+		Scan(&e.ID, &e.Bridge, &e.Token, &e.Amount, &e.Completed, &e.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// LedgerTotals 按代币返回跨链桥已完成消息和在途消息的累计金额
+func (s *PostgresStore) LedgerTotals(bridge string) ([]types.LedgerTotal, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT bridge, token,
+			COALESCE(SUM(amount) FILTER (WHERE completed), 0)::TEXT,
+			COUNT(*) FILTER (WHERE completed),
+			COALESCE(SUM(amount) FILTER (WHERE NOT completed), 0)::TEXT
+		FROM relay_ledger
+		WHERE bridge = $1
+		GROUP BY bridge, token
+		ORDER BY token`, bridge)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []types.LedgerTotal
+	for rows.Next() {
+		var t types.LedgerTotal
+		if err := rows.Scan(&t.Bridge, &t.Token, &t.Amount, &t.Count, &t.InFlight); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+var (
+	_ types.MessageStore = (*PostgresStore)(nil)
+	_ types.StateStore   = (*PostgresStore)(nil)
+)
+
+// PostgresStore 是基于 PostgreSQL 的持久化消息队列存储，适用于多实例部署
+type PostgresStore struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// NewPostgresStore 使用已迁移的数据库连接创建消息存储
+func NewPostgresStore(db *sql.DB, timeout time.Duration) *PostgresStore {
+	return &PostgresStore{db: db, timeout: timeout}
+}
+
+func (s *PostgresStore) ctx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.timeout)
+}
+
+// Append 持久化一条消息，已存在的 nonce 保持不变
+func (s *PostgresStore) Append(queue string, nonce uint64, payload []byte) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO relay_messages (queue, nonce, payload)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (queue, nonce) DO NOTHING`,
+		queue, nonce, payload)
+	return err
+}
+
+// Ack 确认消息
+func (s *PostgresStore) Ack(queue string, nonce uint64) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE relay_messages SET acked = TRUE, acked_at = NOW()
+		WHERE queue = $1 AND nonce = $2 AND NOT acked`,
+		queue, nonce)
+	return err
+}
+
+// Unacked 返回所有未确认的消息并增加投递次数
+func (s *PostgresStore) Unacked(queue string) ([]types.StoredMessage, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE relay_messages SET attempts = attempts + 1
+		WHERE queue = $1 AND NOT acked
+		RETURNING nonce, payload, attempts, created_at`,
+		queue)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var msgs []types.StoredMessage
+	for rows.Next() {
+		var m types.StoredMessage
+		if err := rows.Scan(&m.Nonce, &m.Payload, &m.Attempts, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sortByNonce(msgs)
+	return msgs, nil
+}
+
+// LatestNonce 返回队列中的最大 nonce
+func (s *PostgresStore) LatestNonce(queue string) (uint64, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	var nonce sql.NullInt64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT MAX(nonce) FROM relay_messages WHERE queue = $1`, queue).Scan(&nonce)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(nonce.Int64), nil
+}
+
+// GetRecord 返回消息的处理记录，不存在时返回 nil
+func (s *PostgresStore) GetRecord(id string) (*types.MessageRecord, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	rec := types.MessageRecord{ID: id}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT queue, nonce, state, tx_hash, updated_at
+		FROM relay_message_states WHERE id = $1`, id).
+		Scan(&rec.Queue, &rec.Nonce, &rec.State, &rec.TxHash, &rec.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// SaveRecord 写入或更新消息的处理记录，交易哈希为空时保留已记录的哈希
+func (s *PostgresStore) SaveRecord(rec types.MessageRecord) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO relay_message_states (id, queue, nonce, state, tx_hash, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (id) DO UPDATE SET
+			state = EXCLUDED.state,
+			tx_hash = COALESCE(NULLIF(EXCLUDED.tx_hash, ''), relay_message_states.tx_hash),
+			updated_at = NOW()`,
+		rec.ID, rec.Queue, rec.Nonce, rec.State, rec.TxHash)
+	return err
+}
+
+// SaveTransition 在同一事务中更新处理记录并追加状态转换
+func (s *PostgresStore) SaveTransition(rec types.MessageRecord, tr types.MessageTransition) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO relay_message_states (id, queue, nonce, state, tx_hash, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			state = EXCLUDED.state,
+			tx_hash = COALESCE(NULLIF(EXCLUDED.tx_hash, ''), relay_message_states.tx_hash),
+			updated_at = EXCLUDED.updated_at`,
+		rec.ID, rec.Queue, rec.Nonce, rec.State, rec.TxHash, tr.At); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO relay_message_transitions (id, queue, nonce, from_state, to_state, tx_hash, detail, at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		tr.ID, tr.Queue, tr.Nonce, tr.From, tr.To, tr.TxHash, tr.Detail, tr.At); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListTransitions 按时间返回消息的状态转换
+func (s *PostgresStore) ListTransitions(id string) ([]types.MessageTransition, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, queue, nonce, from_state, to_state, tx_hash, detail, at
+		FROM relay_message_transitions WHERE id = $1 ORDER BY seq`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []types.MessageTransition
+	for rows.Next() {
+		var tr types.MessageTransition
+		if err := rows.Scan(&tr.ID, &tr.Queue, &tr.Nonce, &tr.From, &tr.To, &tr.TxHash, &tr.Detail, &tr.At); err != nil {
+			return nil, err
+		}
+		out = append(out, tr)
+	}
+	return out, rows.Err()
+}
+
+// Close 关闭数据库连接
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
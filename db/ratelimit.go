@@ -0,0 +1,78 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+var _ types.RateLimitStore = (*PostgresStore)(nil)
+
+// AddUsage 将用量累加到对应的时间桶
+func (s *PostgresStore) AddUsage(u types.RateUsage) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO relay_rate_usage (key, bucket, value, count)
+		VALUES ($1, $2, $3::NUMERIC, $4)
+		ON CONFLICT (key, bucket) DO UPDATE SET
+			value = relay_rate_usage.value + EXCLUDED.value,
+			count = relay_rate_usage.count + EXCLUDED.count`,
+		u.Key, u.Bucket, u.Value, u.Count)
+	return err
+}
+
+// ReleaseUsage 从对应的时间桶扣减用量，不低于 0
+func (s *PostgresStore) ReleaseUsage(u types.RateUsage) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE relay_rate_usage SET
+			value = GREATEST(value - $3::NUMERIC, 0),
+			count = GREATEST(count - $4, 0)
+		WHERE key = $1 AND bucket = $2`,
+		u.Key, u.Bucket, u.Value, u.Count)
+	return err
+}
+
+// ListUsage 返回起点不早于 since 的时间桶
+func (s *PostgresStore) ListUsage(since time.Time) ([]types.RateUsage, error) {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT key, bucket, value::TEXT, count FROM relay_rate_usage
+		WHERE bucket >= $1
+		ORDER BY key, bucket`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []types.RateUsage
+	for rows.Next() {
+		var u types.RateUsage
+		if err := rows.Scan(&u.Key, &u.Bucket, &u.Value, &u.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
+
+// PruneUsage 删除限流对象属于 prefix 且起点早于 before 的时间桶
+func (s *PostgresStore) PruneUsage(prefix string, before time.Time) error {
+	ctx, cancel := s.ctx()
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM relay_rate_usage WHERE (key = $1 OR key LIKE $2) AND bucket < $3`,
+		prefix, likeEscaper.Replace(prefix)+"/%", before)
+	return err
+}
+
+// likeEscaper 转义 LIKE 模式中的通配符，使前缀按字面匹配
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
@@ -0,0 +1,129 @@
+-- 持久化消息队列：已按序接收但尚未在目标端确认的消息
+CREATE TABLE IF NOT EXISTS relay_messages (
+    queue       TEXT        NOT NULL,
+    nonce       BIGINT      NOT NULL,
+    payload     JSONB       NOT NULL,
+    acked       BOOLEAN     NOT NULL DEFAULT FALSE,
+    attempts    INTEGER     NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    acked_at    TIMESTAMPTZ,
+    PRIMARY KEY (queue, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS relay_messages_unacked
+    ON relay_messages (queue, nonce) WHERE NOT acked;
+
+-- 按确定性消息 ID（源链 ID、交易哈希、日志序号）记录的处理状态，防止重复提交
+CREATE TABLE IF NOT EXISTS relay_message_states (
+    id          TEXT        PRIMARY KEY,
+    queue       TEXT        NOT NULL,
+    nonce       BIGINT      NOT NULL,
+    state       TEXT        NOT NULL,
+    tx_hash     TEXT        NOT NULL DEFAULT '',
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS relay_message_states_queue
+    ON relay_message_states (queue, nonce);
+
+-- 消息生命周期中每次状态转换的时间和原因
+CREATE TABLE IF NOT EXISTS relay_message_transitions (
+    seq         BIGSERIAL   PRIMARY KEY,
+    id          TEXT        NOT NULL,
+    queue       TEXT        NOT NULL,
+    nonce       BIGINT      NOT NULL,
+    from_state  TEXT        NOT NULL,
+    to_state    TEXT        NOT NULL,
+    tx_hash     TEXT        NOT NULL DEFAULT '',
+    detail      TEXT        NOT NULL DEFAULT '',
+    at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS relay_message_transitions_id
+    ON relay_message_transitions (id, seq);
+
+-- 重试耗尽或致命错误后等待运维处理的消息
+CREATE TABLE IF NOT EXISTS relay_dead_letters (
+    id            TEXT        PRIMARY KEY,
+    queue         TEXT        NOT NULL,
+    nonce         BIGINT      NOT NULL,
+    payload       JSONB       NOT NULL,
+    errors        JSONB       NOT NULL DEFAULT '[]',
+    attempts      INTEGER     NOT NULL DEFAULT 0,
+    last_tx_hash  TEXT        NOT NULL DEFAULT '',
+    fee_policy    JSONB,
+    status        TEXT        NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS relay_dead_letters_status
+    ON relay_dead_letters (status, created_at);
+
+-- 运维操作审计日志
+CREATE TABLE IF NOT EXISTS relay_audit_log (
+    id        BIGSERIAL   PRIMARY KEY,
+    time      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    operator  TEXT        NOT NULL,
+    action    TEXT        NOT NULL,
+    target    TEXT        NOT NULL,
+    detail    TEXT        NOT NULL DEFAULT '',
+    result    TEXT        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS relay_audit_log_target
+    ON relay_audit_log (target, time);
+
+-- 跨链桥的暂停和紧急停止状态，重启后恢复
+CREATE TABLE IF NOT EXISTS relay_bridge_controls (
+    bridge           TEXT        PRIMARY KEY,
+    inbound_paused   BOOLEAN     NOT NULL DEFAULT FALSE,
+    outbound_paused  BOOLEAN     NOT NULL DEFAULT FALSE,
+    emergency_stop   BOOLEAN     NOT NULL DEFAULT FALSE,
+    reason           TEXT        NOT NULL DEFAULT '',
+    operator         TEXT        NOT NULL DEFAULT '',
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- 限流滚动窗口的时间桶用量，重启后恢复
+CREATE TABLE IF NOT EXISTS relay_rate_usage (
+    key     TEXT           NOT NULL,
+    bucket  TIMESTAMPTZ    NOT NULL,
+    value   NUMERIC(78, 0) NOT NULL DEFAULT 0,
+    count   BIGINT         NOT NULL DEFAULT 0,
+    PRIMARY KEY (key, bucket)
+);
+
+CREATE INDEX IF NOT EXISTS relay_rate_usage_bucket
+    ON relay_rate_usage (bucket);
+
+-- 等待人工审批的挂起消息
+CREATE TABLE IF NOT EXISTS relay_held_messages (
+    id          TEXT        PRIMARY KEY,
+    queue       TEXT        NOT NULL,
+    nonce       BIGINT      NOT NULL,
+    payload     JSONB       NOT NULL,
+    reason      TEXT        NOT NULL DEFAULT '',
+    status      TEXT        NOT NULL,
+    required    INTEGER     NOT NULL DEFAULT 1,
+    approvals   JSONB       NOT NULL DEFAULT '[]',
+    expires_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS relay_held_messages_status
+    ON relay_held_messages (status, created_at);
+
+-- 中继器账本，与链上的锁定量和释放量对账
+CREATE TABLE IF NOT EXISTS relay_ledger (
+    id          TEXT           PRIMARY KEY,
+    bridge      TEXT           NOT NULL,
+    token       TEXT           NOT NULL,
+    amount      NUMERIC(78, 0) NOT NULL,
+    completed   BOOLEAN        NOT NULL DEFAULT FALSE,
+    updated_at  TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS relay_ledger_bridge
+    ON relay_ledger (bridge, token) WHERE completed;
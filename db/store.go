@@ -0,0 +1,20 @@
+package db
+
+import "github.com/st-chain/me-bridge/types"
+
+var (
+	_ Store = (*PostgresStore)(nil)
+	_ Store = (*WALStore)(nil)
+)
+
+// Store 是服务使用的全部持久化接口，由 PostgresStore 和 WALStore 实现。
+// 服务启动时只打开一次，由队列、状态机、死信、挂起、控制、限流和账本共用
+type Store interface {
+	types.MessageStore
+	types.StateStore
+	types.DeadLetterStore
+	types.HoldStore
+	types.ControlStore
+	types.RateLimitStore
+	types.LedgerStore
+}
@@ -0,0 +1,758 @@
+package db
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+var (
+	_ types.MessageStore    = (*WALStore)(nil)
+	_ types.StateStore      = (*WALStore)(nil)
+	_ types.DeadLetterStore = (*WALStore)(nil)
+	_ types.ControlStore    = (*WALStore)(nil)
+	_ types.RateLimitStore  = (*WALStore)(nil)
+	_ types.HoldStore       = (*WALStore)(nil)
+	_ types.LedgerStore     = (*WALStore)(nil)
+)
+
+// DefaultCompactThreshold 是触发日志压缩的最少记录数
+const DefaultCompactThreshold = 10000
+
+// WAL 记录类型
+const (
+	walAppend  = "append"  // 写入消息
+	walAck     = "ack"     // 确认消息
+	walDeliver = "deliver" // 重新投递，投递次数加一
+	walLatest  = "latest"  // 压缩后保留的最大 nonce
+	walState   = "state"   // 消息处理状态
+	walDead    = "dead"    // 死信
+	walAudit   = "audit"   // 运维审计日志
+	walTrans   = "trans"   // 消息状态转换，同时更新处理状态
+	walControl = "control" // 跨链桥控制状态
+	walUsage   = "usage"   // 限流用量，累加到时间桶
+	walRelease = "release" // 撤销的限流用量，从时间桶扣减
+	walPrune   = "prune"   // 删除 Queue 前缀下早于 Time 的限流时间桶
+	walHold    = "hold"    // 挂起消息
+	walLedger  = "ledger"  // 账本记录
+)
+
+// walRecord 是日志文件中的一行
+type walRecord struct {
+	Op       string                   `json:"op"`
+	Queue    string                   `json:"queue"`
+	Nonce    uint64                   `json:"nonce"`
+	Payload  []byte                   `json:"payload,omitempty"`
+	Attempts int                      `json:"attempts,omitempty"`
+	Record   *types.MessageRecord     `json:"record,omitempty"`
+	Dead     *types.DeadLetter        `json:"dead,omitempty"`
+	Audit    *types.AuditEntry        `json:"audit,omitempty"`
+	Trans    *types.MessageTransition `json:"trans,omitempty"`
+	Control  *types.BridgeControl     `json:"control,omitempty"`
+	Usage    *types.RateUsage         `json:"usage,omitempty"`
+	Hold     *types.HeldMessage       `json:"hold,omitempty"`
+	Ledger   *types.LedgerEntry       `json:"ledger,omitempty"`
+	Time     time.Time                `json:"time"`
+}
+
+// walQueue 是单个队列在内存中的状态
+type walQueue struct {
+	latest  uint64
+	unacked map[uint64]*types.StoredMessage
+}
+
+// WALStore 是基于本地追加日志文件的消息存储，适用于单节点部署。
+// 每次写入都会 fsync，启动时重放日志恢复未确认的消息，记录过多时压缩日志。
+type WALStore struct {
+	mu               sync.Mutex
+	path             string
+	file             *os.File
+	queues           map[string]*walQueue
+	states           map[string]*types.MessageRecord
+	dead             map[string]*types.DeadLetter
+	audit            []types.AuditEntry
+	transitions      map[string][]types.MessageTransition
+	controls         map[string]*types.BridgeControl
+	usage            map[usageKey]*types.RateUsage
+	holds            map[string]*types.HeldMessage
+	ledger           map[string]*types.LedgerEntry
+	records          int
+	compactThreshold int
+}
+
+// OpenWALStore 打开或创建日志文件并重放其中的记录
+func OpenWALStore(path string) (*WALStore, error) {
+	s := &WALStore{
+		path:             path,
+		queues:           make(map[string]*walQueue),
+		states:           make(map[string]*types.MessageRecord),
+		dead:             make(map[string]*types.DeadLetter),
+		transitions:      make(map[string][]types.MessageTransition),
+		controls:         make(map[string]*types.BridgeControl),
+		usage:            make(map[usageKey]*types.RateUsage),
+		holds:            make(map[string]*types.HeldMessage),
+		ledger:           make(map[string]*types.LedgerEntry),
+		compactThreshold: DefaultCompactThreshold,
+	}
+
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wal: %w", err)
+	}
+	s.file = file
+	return s, nil
+}
+
+// replay 从日志文件恢复内存状态，末尾不完整的记录（写入时崩溃）会被忽略
+func (s *WALStore) replay() error {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open wal: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// 没有换行结尾的记录说明写入未完成
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read wal: %w", err)
+		}
+
+		var rec walRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("corrupt wal record %d: %w", s.records+1, err)
+		}
+		s.apply(rec)
+		s.records++
+	}
+}
+
+// apply 将一条记录应用到内存状态
+func (s *WALStore) apply(rec walRecord) {
+	switch rec.Op {
+	case walState:
+		if rec.Record != nil {
+			s.states[rec.Record.ID] = rec.Record
+		}
+		return
+	case walDead:
+		if rec.Dead != nil {
+			s.dead[rec.Dead.ID] = rec.Dead
+		}
+		return
+	case walAudit:
+		if rec.Audit != nil {
+			s.audit = append(s.audit, *rec.Audit)
+		}
+		return
+	case walTrans:
+		if rec.Record != nil {
+			s.states[rec.Record.ID] = rec.Record
+		}
+		if rec.Trans != nil {
+			s.transitions[rec.Trans.ID] = append(s.transitions[rec.Trans.ID], *rec.Trans)
+		}
+		return
+	case walControl:
+		if rec.Control != nil {
+			s.controls[rec.Control.Bridge] = rec.Control
+		}
+		return
+	case walUsage:
+		if rec.Usage != nil {
+			s.addUsage(*rec.Usage)
+		}
+		return
+	case walRelease:
+		if rec.Usage != nil {
+			s.releaseUsage(*rec.Usage)
+		}
+		return
+	case walPrune:
+		// 旧版本的记录没有 Queue，按原语义清理全部限流对象
+		for k := range s.usage {
+			if k.bucket < rec.Time.UnixNano() && (rec.Queue == "" || types.UsageKeyHasPrefix(k.key, rec.Queue)) {
+				delete(s.usage, k)
+			}
+		}
+		return
+	case walHold:
+		if rec.Hold != nil {
+			s.holds[rec.Hold.ID] = rec.Hold
+		}
+		return
+	case walLedger:
+		if rec.Ledger != nil {
+			s.ledger[rec.Ledger.ID] = rec.Ledger
+		}
+		return
+	}
+
+	q := s.queue(rec.Queue)
+	switch rec.Op {
+	case walAppend:
+		if _, ok := q.unacked[rec.Nonce]; !ok && rec.Nonce > q.latest {
+			q.unacked[rec.Nonce] = &types.StoredMessage{
+				Nonce:     rec.Nonce,
+				Payload:   rec.Payload,
+				Attempts:  rec.Attempts,
+				CreatedAt: rec.Time,
+			}
+		}
+		q.latest = max(q.latest, rec.Nonce)
+	case walAck:
+		delete(q.unacked, rec.Nonce)
+	case walDeliver:
+		if m, ok := q.unacked[rec.Nonce]; ok {
+			m.Attempts++
+		}
+	case walLatest:
+		q.latest = max(q.latest, rec.Nonce)
+	}
+}
+
+func (s *WALStore) queue(name string) *walQueue {
+	q, ok := s.queues[name]
+	if !ok {
+		q = &walQueue{unacked: make(map[uint64]*types.StoredMessage)}
+		s.queues[name] = q
+	}
+	return q
+}
+
+// write 追加记录并同步到磁盘，调用方需持有 s.mu
+func (s *WALStore) write(recs ...walRecord) error {
+	var buf []byte
+	for _, rec := range recs {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	if _, err := s.file.Write(buf); err != nil {
+		return fmt.Errorf("failed to write wal: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync wal: %w", err)
+	}
+	for _, rec := range recs {
+		s.apply(rec)
+	}
+	s.records += len(recs)
+	return nil
+}
+
+// Append 持久化一条消息，已存在或已确认的 nonce 保持不变
+func (s *WALStore) Append(queue string, nonce uint64, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := s.queue(queue)
+	if _, ok := q.unacked[nonce]; ok || nonce <= q.latest {
+		return nil
+	}
+	return s.write(walRecord{Op: walAppend, Queue: queue, Nonce: nonce, Payload: payload, Time: time.Now()})
+}
+
+// Ack 确认消息，必要时压缩日志
+func (s *WALStore) Ack(queue string, nonce uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.queue(queue).unacked[nonce]; !ok {
+		return nil
+	}
+	if err := s.write(walRecord{Op: walAck, Queue: queue, Nonce: nonce, Time: time.Now()}); err != nil {
+		return err
+	}
+	return s.maybeCompact()
+}
+
+// Unacked 返回所有未确认的消息并增加投递次数
+func (s *WALStore) Unacked(queue string) ([]types.StoredMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := s.queue(queue)
+	recs := make([]walRecord, 0, len(q.unacked))
+	for nonce := range q.unacked {
+		recs = append(recs, walRecord{Op: walDeliver, Queue: queue, Nonce: nonce, Time: time.Now()})
+	}
+	if len(recs) > 0 {
+		if err := s.write(recs...); err != nil {
+			return nil, err
+		}
+	}
+
+	msgs := make([]types.StoredMessage, 0, len(q.unacked))
+	for _, m := range q.unacked {
+		msgs = append(msgs, *m)
+	}
+	sortByNonce(msgs)
+	return msgs, nil
+}
+
+// LatestNonce 返回队列中的最大 nonce
+func (s *WALStore) LatestNonce(queue string) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queue(queue).latest, nil
+}
+
+// GetRecord 返回消息的处理记录，不存在时返回 nil
+func (s *WALStore) GetRecord(id string) (*types.MessageRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.states[id]
+	if !ok {
+		return nil, nil
+	}
+	out := *rec
+	return &out, nil
+}
+
+// SaveRecord 写入或更新消息的处理记录，交易哈希为空时保留已记录的哈希
+func (s *WALStore) SaveRecord(rec types.MessageRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.states[rec.ID]; ok && rec.TxHash == "" {
+		rec.TxHash = prev.TxHash
+	}
+	rec.UpdatedAt = time.Now()
+	return s.write(walRecord{Op: walState, Queue: rec.Queue, Nonce: rec.Nonce, Record: &rec, Time: rec.UpdatedAt})
+}
+
+// SaveTransition 更新处理记录并追加状态转换，两者写入同一条日志记录
+func (s *WALStore) SaveTransition(rec types.MessageRecord, tr types.MessageTransition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.states[rec.ID]; ok && rec.TxHash == "" {
+		rec.TxHash = prev.TxHash
+	}
+	rec.UpdatedAt = tr.At
+	return s.write(walRecord{Op: walTrans, Queue: rec.Queue, Nonce: rec.Nonce, Record: &rec, Trans: &tr, Time: tr.At})
+}
+
+// ListTransitions 按时间返回消息的状态转换
+func (s *WALStore) ListTransitions(id string) ([]types.MessageTransition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]types.MessageTransition(nil), s.transitions[id]...), nil
+}
+
+// SaveDeadLetter 写入或覆盖死信
+func (s *WALStore) SaveDeadLetter(dl types.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(walRecord{Op: walDead, Queue: dl.Queue, Nonce: dl.Nonce, Dead: &dl, Time: time.Now()})
+}
+
+// GetDeadLetter 返回死信，不存在时返回 nil
+func (s *WALStore) GetDeadLetter(id string) (*types.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dl, ok := s.dead[id]
+	if !ok {
+		return nil, nil
+	}
+	out := *dl
+	return &out, nil
+}
+
+// ListDeadLetters 按创建时间返回指定状态的死信，status 为空时返回全部
+func (s *WALStore) ListDeadLetters(status types.DeadLetterStatus) ([]types.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []types.DeadLetter
+	for _, dl := range s.dead {
+		if status == "" || dl.Status == status {
+			out = append(out, *dl)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// AppendAudit 追加一条审计日志
+func (s *WALStore) AppendAudit(entry types.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(walRecord{Op: walAudit, Audit: &entry, Time: entry.Time})
+}
+
+// ListAudit 按时间返回操作对象的审计日志，target 为空时返回全部
+func (s *WALStore) ListAudit(target string) ([]types.AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []types.AuditEntry
+	for _, e := range s.audit {
+		if target == "" || e.Target == target {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// GetControl 返回跨链桥的控制状态，不存在时返回 nil
+func (s *WALStore) GetControl(bridge string) (*types.BridgeControl, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.controls[bridge]
+	if !ok {
+		return nil, nil
+	}
+	out := *c
+	return &out, nil
+}
+
+// SaveControl 写入或覆盖跨链桥的控制状态
+func (s *WALStore) SaveControl(c types.BridgeControl) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(walRecord{Op: walControl, Control: &c, Time: c.UpdatedAt})
+}
+
+// ListControls 按名称返回所有已记录的控制状态
+func (s *WALStore) ListControls() ([]types.BridgeControl, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]types.BridgeControl, 0, len(s.controls))
+	for _, c := range s.controls {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Bridge < out[j].Bridge })
+	return out, nil
+}
+
+// usageKey 按限流对象和时间桶索引用量
+type usageKey struct {
+	key    string
+	bucket int64
+}
+
+// addUsage 将用量累加到内存中的时间桶，调用方需持有 s.mu
+func (s *WALStore) addUsage(u types.RateUsage) {
+	k := usageKey{key: u.Key, bucket: u.Bucket.UnixNano()}
+	cur, ok := s.usage[k]
+	if !ok {
+		cur = &types.RateUsage{Key: u.Key, Bucket: u.Bucket, Value: "0"}
+		s.usage[k] = cur
+	}
+	total, _ := new(big.Int).SetString(cur.Value, 10)
+	if v, ok := new(big.Int).SetString(u.Value, 10); ok && total != nil {
+		cur.Value = total.Add(total, v).String()
+	}
+	cur.Count += u.Count
+}
+
+// releaseUsage 从内存中的时间桶扣减用量，不低于 0，调用方需持有 s.mu
+func (s *WALStore) releaseUsage(u types.RateUsage) {
+	cur, ok := s.usage[usageKey{key: u.Key, bucket: u.Bucket.UnixNano()}]
+	if !ok {
+		return
+	}
+	total, _ := new(big.Int).SetString(cur.Value, 10)
+	if v, ok := new(big.Int).SetString(u.Value, 10); ok && total != nil {
+		if total.Sub(total, v).Sign() < 0 {
+			total.SetInt64(0)
+		}
+		cur.Value = total.String()
+	}
+	cur.Count -= min(cur.Count, u.Count)
+}
+
+// AddUsage 将用量累加到对应的时间桶
+func (s *WALStore) AddUsage(u types.RateUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(walRecord{Op: walUsage, Usage: &u, Time: time.Now()})
+}
+
+// ReleaseUsage 从对应的时间桶扣减用量，不低于 0
+func (s *WALStore) ReleaseUsage(u types.RateUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(walRecord{Op: walRelease, Usage: &u, Time: time.Now()})
+}
+
+// ListUsage 按限流对象和时间返回起点不早于 since 的时间桶
+func (s *WALStore) ListUsage(since time.Time) ([]types.RateUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []types.RateUsage
+	for k, u := range s.usage {
+		if k.bucket >= since.UnixNano() {
+			out = append(out, *u)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Key != out[j].Key {
+			return out[i].Key < out[j].Key
+		}
+		return out[i].Bucket.Before(out[j].Bucket)
+	})
+	return out, nil
+}
+
+// PruneUsage 删除限流对象属于 prefix 且起点早于 before 的时间桶
+func (s *WALStore) PruneUsage(prefix string, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.write(walRecord{Op: walPrune, Queue: prefix, Time: before}); err != nil {
+		return err
+	}
+	return s.maybeCompact()
+}
+
+// SaveHold 写入或覆盖挂起消息
+func (s *WALStore) SaveHold(h types.HeldMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(walRecord{Op: walHold, Queue: h.Queue, Nonce: h.Nonce, Hold: &h, Time: h.UpdatedAt})
+}
+
+// GetHold 返回挂起消息，不存在时返回 nil
+func (s *WALStore) GetHold(id string) (*types.HeldMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.holds[id]
+	if !ok {
+		return nil, nil
+	}
+	out := *h
+	return &out, nil
+}
+
+// ListHolds 按创建时间返回指定状态的挂起消息，status 为空时返回全部
+func (s *WALStore) ListHolds(status types.HoldStatus) ([]types.HeldMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []types.HeldMessage
+	for _, h := range s.holds {
+		if status == "" || h.Status == status {
+			out = append(out, *h)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+// SaveLedgerEntry 写入或覆盖账本记录
+func (s *WALStore) SaveLedgerEntry(e types.LedgerEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(walRecord{Op: walLedger, Queue: e.Bridge, Ledger: &e, Time: e.UpdatedAt})
+}
+
+// GetLedgerEntry 返回账本记录，不存在时返回 nil
+func (s *WALStore) GetLedgerEntry(id string) (*types.LedgerEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.ledger[id]
+	if !ok {
+		return nil, nil
+	}
+	out := *e
+	return &out, nil
+}
+
+// LedgerTotals 按代币返回跨链桥已完成消息和在途消息的累计金额
+func (s *WALStore) LedgerTotals(bridge string) ([]types.LedgerTotal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sums := make(map[string]*big.Int)
+	inflight := make(map[string]*big.Int)
+	counts := make(map[string]uint64)
+	for _, e := range s.ledger {
+		if e.Bridge != bridge {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(e.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid ledger amount %q of %s", e.Amount, e.ID)
+		}
+		if sums[e.Token] == nil {
+			sums[e.Token], inflight[e.Token] = new(big.Int), new(big.Int)
+		}
+		if e.Completed {
+			sums[e.Token].Add(sums[e.Token], amount)
+			counts[e.Token]++
+		} else {
+			inflight[e.Token].Add(inflight[e.Token], amount)
+		}
+	}
+
+	out := make([]types.LedgerTotal, 0, len(sums))
+	for token, sum := range sums {
+		out = append(out, types.LedgerTotal{Bridge: bridge, Token: token, Amount: sum.String(), Count: counts[token], InFlight: inflight[token].String()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Token < out[j].Token })
+	return out, nil
+}
+
+// maybeCompact 在记录数远多于存活消息时重写日志，只保留未确认的消息、各队列的最大 nonce、
+// 消息处理状态和状态转换、死信、审计日志、跨链桥控制状态、限流用量、挂起消息和账本。
+// 调用方需持有 s.mu
+func (s *WALStore) maybeCompact() error {
+	live := 0
+	for _, q := range s.queues {
+		live += len(q.unacked) + 1
+	}
+	live += len(s.states) + len(s.dead) + len(s.audit) + len(s.controls) + len(s.usage) + len(s.holds) + len(s.ledger)
+	for _, trs := range s.transitions {
+		live += len(trs)
+	}
+	if s.records < s.compactThreshold || s.records < 2*live {
+		return nil
+	}
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create compacted wal: %w", err)
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	records := 0
+	now := time.Now()
+	for name, q := range s.queues {
+		// 先写消息再写最大 nonce，重放时 nonce 不大于 latest 的写入会被视为重复
+		for _, m := range q.unacked {
+			rec := walRecord{Op: walAppend, Queue: name, Nonce: m.Nonce, Payload: m.Payload, Attempts: m.Attempts, Time: m.CreatedAt}
+			if err := enc.Encode(rec); err != nil {
+				tmp.Close()
+				return err
+			}
+			records++
+		}
+		if err := enc.Encode(walRecord{Op: walLatest, Queue: name, Nonce: q.latest, Time: now}); err != nil {
+			tmp.Close()
+			return err
+		}
+		records++
+	}
+	for _, rec := range s.states {
+		if err := enc.Encode(walRecord{Op: walState, Queue: rec.Queue, Nonce: rec.Nonce, Record: rec, Time: rec.UpdatedAt}); err != nil {
+			tmp.Close()
+			return err
+		}
+		records++
+	}
+	for _, trs := range s.transitions {
+		// 状态已由 walState 记录恢复，这里只保留转换历史
+		for i := range trs {
+			if err := enc.Encode(walRecord{Op: walTrans, Queue: trs[i].Queue, Nonce: trs[i].Nonce, Trans: &trs[i], Time: trs[i].At}); err != nil {
+				tmp.Close()
+				return err
+			}
+			records++
+		}
+	}
+	for _, dl := range s.dead {
+		if err := enc.Encode(walRecord{Op: walDead, Queue: dl.Queue, Nonce: dl.Nonce, Dead: dl, Time: dl.UpdatedAt}); err != nil {
+			tmp.Close()
+			return err
+		}
+		records++
+	}
+	for _, c := range s.controls {
+		if err := enc.Encode(walRecord{Op: walControl, Control: c, Time: c.UpdatedAt}); err != nil {
+			tmp.Close()
+			return err
+		}
+		records++
+	}
+	for _, u := range s.usage {
+		if err := enc.Encode(walRecord{Op: walUsage, Usage: u, Time: now}); err != nil {
+			tmp.Close()
+			return err
+		}
+		records++
+	}
+	for _, h := range s.holds {
+		if err := enc.Encode(walRecord{Op: walHold, Queue: h.Queue, Nonce: h.Nonce, Hold: h, Time: h.UpdatedAt}); err != nil {
+			tmp.Close()
+			return err
+		}
+		records++
+	}
+	for _, e := range s.ledger {
+		if err := enc.Encode(walRecord{Op: walLedger, Queue: e.Bridge, Ledger: e, Time: e.UpdatedAt}); err != nil {
+			tmp.Close()
+			return err
+		}
+		records++
+	}
+	for i := range s.audit {
+		if err := enc.Encode(walRecord{Op: walAudit, Audit: &s.audit[i], Time: s.audit[i].Time}); err != nil {
+			tmp.Close()
+			return err
+		}
+		records++
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	tmp.Close()
+
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace wal: %w", err)
+	}
+
+	s.file.Close()
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to reopen wal: %w", err)
+	}
+	s.file = file
+	s.records = records
+	return nil
+}
+
+// Close 关闭日志文件
+func (s *WALStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// sortByNonce 按 nonce 升序排序
+func sortByNonce(msgs []types.StoredMessage) {
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].Nonce < msgs[j].Nonce })
+}
@@ -0,0 +1,390 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+func TestWALStoreRedeliversUnackedAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+
+	store, err := OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	for nonce := uint64(1); nonce <= 3; nonce++ {
+		if err := store.Append("bsc->tron", nonce, []byte(`{"nonce":1}`)); err != nil {
+			t.Fatalf("Failed to append: %v", err)
+		}
+	}
+	if err := store.Ack("bsc->tron", 2); err != nil {
+		t.Fatalf("Failed to ack: %v", err)
+	}
+	store.Close()
+
+	store, err = OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer store.Close()
+
+	msgs, err := store.Unacked("bsc->tron")
+	if err != nil {
+		t.Fatalf("Failed to read unacked: %v", err)
+	}
+	if len(msgs) != 2 || msgs[0].Nonce != 1 || msgs[1].Nonce != 3 {
+		t.Fatalf("Expected nonces [1 3], got %+v", msgs)
+	}
+	if msgs[0].Attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", msgs[0].Attempts)
+	}
+
+	latest, _ := store.LatestNonce("bsc->tron")
+	if latest != 3 {
+		t.Errorf("Expected latest nonce 3, got %d", latest)
+	}
+
+	// 已确认的 nonce 不会被重新写入
+	store.Append("bsc->tron", 2, []byte(`{}`))
+	if msgs, _ := store.Unacked("bsc->tron"); len(msgs) != 2 {
+		t.Errorf("Expected acked nonce to stay acked, got %+v", msgs)
+	}
+}
+
+func TestWALStoreIgnoresTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+
+	store, err := OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	store.Append("q", 1, []byte(`{}`))
+	store.Close()
+
+	// 模拟写入时崩溃留下的不完整记录
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	f.WriteString(`{"op":"append","queue":"q","nonce":2`)
+	f.Close()
+
+	store, err = OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen wal with torn tail: %v", err)
+	}
+	defer store.Close()
+
+	if msgs, _ := store.Unacked("q"); len(msgs) != 1 {
+		t.Errorf("Expected 1 unacked message, got %+v", msgs)
+	}
+}
+
+func TestWALStoreCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+
+	store, err := OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	store.compactThreshold = 10
+
+	for nonce := uint64(1); nonce <= 20; nonce++ {
+		store.Append("q", nonce, []byte(`{}`))
+		if nonce != 20 {
+			store.Ack("q", nonce)
+		}
+	}
+	if store.records >= 20 {
+		t.Errorf("Expected wal to be compacted, got %d records", store.records)
+	}
+	store.Close()
+
+	store, err = OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen compacted wal: %v", err)
+	}
+	defer store.Close()
+
+	msgs, _ := store.Unacked("q")
+	if len(msgs) != 1 || msgs[0].Nonce != 20 {
+		t.Errorf("Expected only nonce 20 unacked, got %+v", msgs)
+	}
+	if latest, _ := store.LatestNonce("q"); latest != 20 {
+		t.Errorf("Expected latest nonce 20, got %d", latest)
+	}
+}
+
+func TestWALStorePersistsMessageStates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+
+	store, err := OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	rec := types.MessageRecord{ID: "0xabc", Queue: "bsc->tron", Nonce: 1, State: types.MsgStateSubmitted, TxHash: "0x01"}
+	if err := store.SaveRecord(rec); err != nil {
+		t.Fatalf("Failed to save record: %v", err)
+	}
+	// 未提供交易哈希时保留原哈希
+	rec.State, rec.TxHash = types.MsgStateCompleted, ""
+	if err := store.SaveRecord(rec); err != nil {
+		t.Fatalf("Failed to save record: %v", err)
+	}
+	store.Close()
+
+	store, err = OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer store.Close()
+
+	got, err := store.GetRecord("0xabc")
+	if err != nil || got == nil {
+		t.Fatalf("Expected record, got %v, %v", got, err)
+	}
+	if got.State != types.MsgStateCompleted || got.TxHash != "0x01" || got.Nonce != 1 {
+		t.Errorf("Unexpected record %+v", got)
+	}
+	if missing, _ := store.GetRecord("0xdef"); missing != nil {
+		t.Errorf("Expected no record, got %+v", missing)
+	}
+}
+
+func TestWALStorePersistsTransitions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+
+	store, err := OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	steps := []struct {
+		from, to types.MessageState
+		txHash   string
+	}{
+		{types.MsgStateUnknown, types.MsgStateQueued, ""},
+		{types.MsgStateQueued, types.MsgStateSubmitted, "0x01"},
+		{types.MsgStateSubmitted, types.MsgStateCompleted, ""},
+	}
+	for _, step := range steps {
+		rec := types.MessageRecord{ID: "0xabc", Queue: "bsc->tron", Nonce: 1, State: step.to, TxHash: step.txHash}
+		tr := types.MessageTransition{ID: "0xabc", Queue: "bsc->tron", Nonce: 1, From: step.from, To: step.to, TxHash: step.txHash, At: time.Now()}
+		if err := store.SaveTransition(rec, tr); err != nil {
+			t.Fatalf("Failed to save transition: %v", err)
+		}
+	}
+	store.Close()
+
+	store, err = OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer store.Close()
+
+	got, err := store.GetRecord("0xabc")
+	if err != nil || got == nil {
+		t.Fatalf("Expected record, got %v, %v", got, err)
+	}
+	if got.State != types.MsgStateCompleted || got.TxHash != "0x01" {
+		t.Errorf("Unexpected record %+v", got)
+	}
+	history, err := store.ListTransitions("0xabc")
+	if err != nil {
+		t.Fatalf("Failed to list transitions: %v", err)
+	}
+	if len(history) != len(steps) {
+		t.Fatalf("Expected %d transitions, got %d", len(steps), len(history))
+	}
+	for i, step := range steps {
+		if history[i].From != step.from || history[i].To != step.to {
+			t.Errorf("Unexpected transition %d: %+v", i, history[i])
+		}
+	}
+}
+
+func TestWALStorePersistsDeadLettersAndAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+
+	store, err := OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	now := time.Now()
+	dl := types.DeadLetter{
+		ID: "0xabc", Queue: "bsc->tron", Nonce: 7, Payload: []byte(`{"nonce":7}`),
+		Errors: []string{"max retries exceeded", "execution reverted"}, Attempts: 3,
+		Status: types.DeadLetterPending, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := store.SaveDeadLetter(dl); err != nil {
+		t.Fatalf("Failed to save dead letter: %v", err)
+	}
+	dl.Status = types.DeadLetterDiscarded
+	store.SaveDeadLetter(dl)
+	store.AppendAudit(types.AuditEntry{Time: now, Operator: "alice", Action: "discard", Target: "0xabc", Result: "ok"})
+	store.AppendAudit(types.AuditEntry{Time: now, Operator: "bob", Action: "replay", Target: "0xdef", Result: "ok"})
+	store.Close()
+
+	store, err = OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer store.Close()
+
+	got, _ := store.GetDeadLetter("0xabc")
+	if got == nil || got.Status != types.DeadLetterDiscarded || len(got.Errors) != 2 || got.Attempts != 3 {
+		t.Errorf("Unexpected dead letter %+v", got)
+	}
+	if pending, _ := store.ListDeadLetters(types.DeadLetterPending); len(pending) != 0 {
+		t.Errorf("Expected no pending dead letters, got %+v", pending)
+	}
+	audit, _ := store.ListAudit("0xabc")
+	if len(audit) != 1 || audit[0].Operator != "alice" {
+		t.Errorf("Unexpected audit %+v", audit)
+	}
+	if all, _ := store.ListAudit(""); len(all) != 2 {
+		t.Errorf("Expected 2 audit entries, got %d", len(all))
+	}
+}
+
+func TestWALStorePersistsControls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+
+	store, err := OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	c := types.BridgeControl{Bridge: "bsc-tron", InboundPaused: true, Operator: "alice", UpdatedAt: time.Now()}
+	if err := store.SaveControl(c); err != nil {
+		t.Fatalf("Failed to save control: %v", err)
+	}
+	c.OutboundPaused, c.EmergencyStop = true, true
+	store.SaveControl(c)
+	store.Close()
+
+	store, err = OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer store.Close()
+
+	got, err := store.GetControl("bsc-tron")
+	if err != nil || got == nil {
+		t.Fatalf("Expected control, got %v, %v", got, err)
+	}
+	if !got.InboundPaused || !got.OutboundPaused || !got.EmergencyStop || got.Operator != "alice" {
+		t.Errorf("Unexpected control %+v", got)
+	}
+	if missing, _ := store.GetControl("eth-tron"); missing != nil {
+		t.Errorf("Expected no control, got %+v", missing)
+	}
+	if list, _ := store.ListControls(); len(list) != 1 {
+		t.Errorf("Expected 1 control, got %+v", list)
+	}
+}
+
+func TestWALStorePersistsRateUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+
+	store, err := OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	old := time.Unix(1700000000, 0)
+	recent := old.Add(time.Hour)
+	store.AddUsage(types.RateUsage{Key: "bsc->tron", Bucket: old, Value: "5", Count: 1})
+	store.AddUsage(types.RateUsage{Key: "bsc->tron", Bucket: recent, Value: "115792089237316195423570985008687907853269984665640564039457584007913129639935", Count: 1})
+	store.AddUsage(types.RateUsage{Key: "bsc->tron", Bucket: recent, Value: "1", Count: 2})
+	store.AddUsage(types.RateUsage{Key: "eth->tron/token/usdt", Bucket: old, Value: "7", Count: 1})
+	store.AddUsage(types.RateUsage{Key: "bsc->tron2", Bucket: old, Value: "9", Count: 1})
+	if err := store.PruneUsage("bsc->tron", recent); err != nil {
+		t.Fatalf("Failed to prune usage: %v", err)
+	}
+	store.Close()
+
+	store, err = OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer store.Close()
+
+	usage, err := store.ListUsage(old)
+	if err != nil || len(usage) != 3 {
+		t.Fatalf("Expected 3 buckets, got %+v, %v", usage, err)
+	}
+	// 只清理 bsc->tron 自己的时间桶，其他桥的用量保留
+	if usage[1].Key != "bsc->tron2" || usage[2].Key != "eth->tron/token/usdt" {
+		t.Errorf("Expected other bridges' usage to survive, got %+v", usage)
+	}
+	// 超过 uint256 的累计值不能截断
+	want := "115792089237316195423570985008687907853269984665640564039457584007913129639936"
+	if usage[0].Value != want || usage[0].Count != 3 || !usage[0].Bucket.Equal(recent) {
+		t.Errorf("Unexpected usage %+v", usage[0])
+	}
+}
+
+func TestWALStorePersistsHolds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+
+	store, err := OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	now := time.Now()
+	h := types.HeldMessage{ID: "0x01", Queue: "bsc->tron", Nonce: 1, Payload: []byte(`{"nonce":1}`), Reason: "limit", Status: types.HoldPending, CreatedAt: now, UpdatedAt: now}
+	store.SaveHold(h)
+	store.SaveHold(types.HeldMessage{ID: "0x02", Queue: "bsc->tron", Nonce: 2, Status: types.HoldPending, CreatedAt: now.Add(time.Second)})
+	h.Status = types.HoldApproved
+	store.SaveHold(h)
+	store.Close()
+
+	store, err = OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer store.Close()
+
+	got, err := store.GetHold("0x01")
+	if err != nil || got == nil || got.Status != types.HoldApproved || string(got.Payload) != `{"nonce":1}` {
+		t.Fatalf("Unexpected hold %+v, %v", got, err)
+	}
+	if missing, _ := store.GetHold("0x03"); missing != nil {
+		t.Errorf("Expected no hold, got %+v", missing)
+	}
+	if pending, _ := store.ListHolds(types.HoldPending); len(pending) != 1 || pending[0].ID != "0x02" {
+		t.Errorf("Expected 1 pending hold, got %+v", pending)
+	}
+	if all, _ := store.ListHolds(""); len(all) != 2 || all[0].ID != "0x01" {
+		t.Errorf("Expected 2 holds by creation time, got %+v", all)
+	}
+}
+
+func TestWALStorePersistsLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.wal")
+
+	store, err := OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	now := time.Now()
+	store.SaveLedgerEntry(types.LedgerEntry{ID: "0x01", Bridge: "bsc->tron", Token: "0xb", Amount: "100", UpdatedAt: now})
+	store.SaveLedgerEntry(types.LedgerEntry{ID: "0x02", Bridge: "bsc->tron", Token: "0xb", Amount: "50", Completed: true, UpdatedAt: now})
+	store.SaveLedgerEntry(types.LedgerEntry{ID: "0x03", Bridge: "tron->bsc", Token: "0xb", Amount: "7", Completed: true, UpdatedAt: now})
+	store.SaveLedgerEntry(types.LedgerEntry{ID: "0x01", Bridge: "bsc->tron", Token: "0xb", Amount: "100", Completed: true, UpdatedAt: now})
+	store.SaveLedgerEntry(types.LedgerEntry{ID: "0x04", Bridge: "bsc->tron", Token: "0xb", Amount: "20", UpdatedAt: now})
+	store.Close()
+
+	store, err = OpenWALStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer store.Close()
+
+	if got, err := store.GetLedgerEntry("0x01"); err != nil || got == nil || !got.Completed {
+		t.Fatalf("Unexpected ledger entry %+v, %v", got, err)
+	}
+	totals, err := store.LedgerTotals("bsc->tron")
+	if err != nil || len(totals) != 1 || totals[0].Amount != "150" || totals[0].Count != 2 || totals[0].InFlight != "20" {
+		t.Fatalf("Unexpected ledger totals %+v, %v", totals, err)
+	}
+}
@@ -0,0 +1,105 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+)
+
+// AlertSeverity 告警级别
+type AlertSeverity string
+
+const (
+	AlertWarning  AlertSeverity = "warning"  // 需要关注，尚不影响资金安全
+	AlertCritical AlertSeverity = "critical" // 需要立即处理
+)
+
+// Alert 是需要运维关注的异常
+type Alert struct {
+	Time     time.Time      `json:"time"`
+	Severity AlertSeverity  `json:"severity"`
+	Source   string         `json:"source"`           // 发出告警的组件，如 reconciler
+	Bridge   string         `json:"bridge,omitempty"` // 相关的跨链桥
+	Message  string         `json:"message"`
+	Fields   map[string]any `json:"fields,omitempty"`
+}
+
+// Alerter 发送告警
+type Alerter interface {
+	Alert(ctx context.Context, alert Alert) error
+}
+
+// Alerters 将告警发送到所有渠道，单个渠道失败不影响其他渠道
+type Alerters []Alerter
+
+func (a Alerters) Alert(ctx context.Context, alert Alert) error {
+	var errs []error
+	for _, alerter := range a {
+		if err := alerter.Alert(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogAlerter 将告警写入日志
+type LogAlerter struct{}
+
+func (LogAlerter) Alert(ctx context.Context, alert Alert) error {
+	fields := map[string]any{
+		"severity": alert.Severity,
+		"source":   alert.Source,
+		"bridge":   alert.Bridge,
+	}
+	for k, v := range alert.Fields {
+		fields[k] = v
+	}
+	logger := log.WithComponent("alert")
+	if alert.Severity == AlertCritical {
+		logger.Error(alert.Message, fields)
+	} else {
+		logger.Warn(alert.Message, fields)
+	}
+	return nil
+}
+
+// WebhookAlerter 将告警以 JSON POST 到 URL，如告警网关或 IM 机器人
+type WebhookAlerter struct {
+	URL    string
+	Header http.Header // 附加的请求头，如网关的认证信息
+	Client *http.Client
+}
+
+func (w *WebhookAlerter) Alert(ctx context.Context, alert Alert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for key, values := range w.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("alert webhook %s: unexpected status %s", w.URL, resp.Status)
+	}
+	return nil
+}
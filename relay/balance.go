@@ -0,0 +1,273 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+)
+
+// ErrInvalidBalanceConfig 余额监控参数无效
+var ErrInvalidBalanceConfig = errors.New("invalid balance monitor config")
+
+// 余额监控的默认参数
+const (
+	DefaultBalanceInterval = time.Minute    // 查询余额的间隔
+	DefaultSpendWindow     = 24 * time.Hour // 估算消耗速度的窗口
+)
+
+// BalanceReader 查询账户的原生代币余额（BNB、ETH、TRX 等，最小单位）
+type BalanceReader interface {
+	Balance(ctx context.Context, address string) (*big.Int, error)
+}
+
+// BalanceLevel 账户余额的告警级别
+type BalanceLevel string
+
+const (
+	BalanceUnknown  BalanceLevel = "unknown"  // 尚未成功查询余额
+	BalanceOK       BalanceLevel = "ok"       // 余额充足
+	BalanceWarning  BalanceLevel = "warning"  // 低于告警阈值或预计可用时间不足，需要充值
+	BalanceCritical BalanceLevel = "critical" // 低于严重阈值或已出现余额不足的交易，暂停提交
+)
+
+// BalanceStatus 是一次余额检查的结果
+type BalanceStatus struct {
+	Network   string        `json:"network"`
+	Account   string        `json:"account"`
+	Level     BalanceLevel  `json:"level"`
+	Balance   *big.Int      `json:"balance,omitempty"`
+	Spent     *big.Int      `json:"spent,omitempty"`  // 窗口内的消耗，不计充值
+	Runway    time.Duration `json:"runway,omitempty"` // 按窗口内的消耗速度估算的可用时间，没有消耗时为 0
+	Paused    bool          `json:"paused"`           // 是否已暂停提交
+	CheckedAt time.Time     `json:"checked_at"`
+	Error     string        `json:"error,omitempty"` // 查询失败的原因，失败时保留上次的级别和暂停状态
+}
+
+// balanceSample 是一次成功查询到的余额
+type balanceSample struct {
+	at      time.Time
+	balance *big.Int
+}
+
+// BalanceMonitor 定期查询中继账户在一条链上的余额，低于阈值时告警，低于严重阈值时暂停使用该账户提交交易，
+// 避免交易因余额不足反复失败。提交交易的 Tunnel 通过 Funds 引用监控器
+type BalanceMonitor struct {
+	Network   string
+	Account   string
+	Reader    BalanceReader
+	Warning   *big.Int      // 低于该余额时告警，为 nil 时不告警
+	Critical  *big.Int      // 低于该余额时暂停提交
+	MinRunway time.Duration // 预计可用时间低于该值时告警，为 0 时不检查
+	Window    time.Duration // 估算消耗速度的窗口
+	Interval  time.Duration
+	Alerter   Alerter
+
+	gate   *Gate
+	logger *log.Logger
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	samples   []balanceSample
+	level     BalanceLevel // 上次检查的级别，级别变化时告警
+	exhausted *big.Int     // 交易余额不足时的余额，余额高于该值（已充值）后才恢复提交
+	last      *BalanceStatus
+}
+
+// NewBalanceMonitor 创建 network 上账户 account 的余额监控，检查阈值
+func NewBalanceMonitor(network, account string, reader BalanceReader, warning, critical *big.Int) (*BalanceMonitor, error) {
+	if account == "" || reader == nil {
+		return nil, fmt.Errorf("%w: %s: account and reader are required", ErrInvalidBalanceConfig, network)
+	}
+	if critical == nil || critical.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %s: critical threshold is required and must not be negative", ErrInvalidBalanceConfig, network)
+	}
+	if warning != nil && warning.Cmp(critical) < 0 {
+		return nil, fmt.Errorf("%w: %s: warning threshold %s below critical %s", ErrInvalidBalanceConfig, network, warning, critical)
+	}
+	return &BalanceMonitor{
+		Network:  network,
+		Account:  account,
+		Reader:   reader,
+		Warning:  warning,
+		Critical: critical,
+		Window:   DefaultSpendWindow,
+		Interval: DefaultBalanceInterval,
+		Alerter:  LogAlerter{},
+		gate:     &Gate{},
+		logger:   log.WithComponent("balance-monitor"),
+		now:      time.Now,
+		level:    BalanceUnknown,
+	}, nil
+}
+
+// funds 返回余额不足时暂停的开关，m 为 nil 时返回 nil（始终打开）
+func (m *BalanceMonitor) funds() *Gate {
+	if m == nil {
+		return nil
+	}
+	return m.gate
+}
+
+// Last 返回最近一次检查的结果，尚未检查时返回 nil
+func (m *BalanceMonitor) Last() *BalanceStatus {
+	if m == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.last
+}
+
+// Run 每隔 Interval 检查一次余额，直到 ctx 取消
+func (m *BalanceMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		m.Check(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check 查询一次余额，更新消耗速度和暂停状态，级别变化时告警
+func (m *BalanceMonitor) Check(ctx context.Context) BalanceStatus {
+	balance, err := m.Reader.Balance(ctx, m.Account)
+	now := m.now()
+
+	m.mu.Lock()
+	status := BalanceStatus{Network: m.Network, Account: m.Account, Level: m.level, CheckedAt: now}
+	if err != nil {
+		status.Error = err.Error()
+		status.Paused = m.gate.Paused()
+		m.last = &status
+		m.mu.Unlock()
+		m.logger.Warn("failed to read relayer balance", map[string]any{"network": m.Network, "account": m.Account, "error": err})
+		m.alert(ctx, AlertWarning, "relayer balance check failed", status)
+		return status
+	}
+
+	m.record(now, balance)
+	status.Balance = balance
+	status.Spent, status.Runway = m.spend(balance)
+	if m.exhausted != nil && balance.Cmp(m.exhausted) > 0 {
+		m.exhausted = nil
+	}
+
+	switch {
+	case balance.Cmp(m.Critical) < 0 || m.exhausted != nil:
+		status.Level = BalanceCritical
+	case m.Warning != nil && balance.Cmp(m.Warning) < 0,
+		m.MinRunway > 0 && status.Runway > 0 && status.Runway < m.MinRunway:
+		status.Level = BalanceWarning
+	default:
+		status.Level = BalanceOK
+	}
+	status.Paused = status.Level == BalanceCritical
+	m.gate.Set(status.Paused)
+	previous := m.level
+	m.level = status.Level
+	m.last = &status
+	m.mu.Unlock()
+
+	if status.Level == previous {
+		return status
+	}
+	switch status.Level {
+	case BalanceCritical:
+		m.alert(ctx, AlertCritical, "relayer balance critical, submission paused", status)
+	case BalanceWarning:
+		m.alert(ctx, AlertWarning, "relayer balance low", status)
+	default:
+		if previous != BalanceUnknown {
+			m.alert(ctx, AlertWarning, "relayer balance recovered", status)
+		}
+	}
+	return status
+}
+
+// Exhausted 在交易因余额不足失败时调用：立即暂停提交并告警，余额高于当前余额（已充值）后由 Check 恢复
+func (m *BalanceMonitor) Exhausted(ctx context.Context, cause error) {
+	if m == nil {
+		return
+	}
+	balance, err := m.Reader.Balance(ctx, m.Account)
+
+	m.mu.Lock()
+	if err != nil {
+		// 无法查询时以上次的余额为准，没有记录时以严重阈值为准
+		balance = new(big.Int).Set(m.Critical)
+		if n := len(m.samples); n > 0 {
+			balance.Set(m.samples[n-1].balance)
+		}
+	}
+	m.exhausted = balance
+	m.level = BalanceCritical
+	m.gate.Set(true)
+	status := BalanceStatus{Network: m.Network, Account: m.Account, Level: BalanceCritical, Balance: balance, Paused: true, CheckedAt: m.now(), Error: cause.Error()}
+	m.last = &status
+	m.mu.Unlock()
+
+	m.logger.Error("relayer account out of funds, submission paused", map[string]any{"network": m.Network, "account": m.Account, "balance": balance, "error": cause})
+	m.alert(ctx, AlertCritical, "relayer account out of funds, submission paused", status)
+}
+
+// record 记录一次余额并丢弃窗口之外的样本，调用方需持有 m.mu
+func (m *BalanceMonitor) record(now time.Time, balance *big.Int) {
+	m.samples = append(m.samples, balanceSample{at: now, balance: new(big.Int).Set(balance)})
+	cutoff := now.Add(-m.Window)
+	drop := 0
+	for drop < len(m.samples)-1 && m.samples[drop].at.Before(cutoff) {
+		drop++
+	}
+	m.samples = m.samples[drop:]
+}
+
+// spend 返回窗口内的消耗和按消耗速度估算的可用时间，余额增加（充值）的区间不计入消耗，调用方需持有 m.mu
+func (m *BalanceMonitor) spend(balance *big.Int) (*big.Int, time.Duration) {
+	spent := new(big.Int)
+	for i := 1; i < len(m.samples); i++ {
+		if diff := new(big.Int).Sub(m.samples[i-1].balance, m.samples[i].balance); diff.Sign() > 0 {
+			spent.Add(spent, diff)
+		}
+	}
+	if len(m.samples) < 2 || spent.Sign() == 0 {
+		return spent, 0
+	}
+	elapsed := m.samples[len(m.samples)-1].at.Sub(m.samples[0].at)
+	// runway = balance / (spent / elapsed)
+	runway := new(big.Int).Mul(balance, big.NewInt(int64(elapsed)))
+	runway.Quo(runway, spent)
+	if !runway.IsInt64() {
+		return spent, time.Duration(math.MaxInt64)
+	}
+	return spent, time.Duration(runway.Int64())
+}
+
+func (m *BalanceMonitor) alert(ctx context.Context, severity AlertSeverity, message string, status BalanceStatus) {
+	if m.Alerter == nil {
+		return
+	}
+	fields := map[string]any{
+		"network": status.Network,
+		"account": status.Account,
+		"level":   status.Level,
+		"balance": status.Balance,
+		"runway":  status.Runway.String(),
+	}
+	if status.Error != "" {
+		fields["error"] = status.Error
+	}
+	alert := Alert{Time: status.CheckedAt, Severity: severity, Source: "balance-monitor", Message: message, Fields: fields}
+	if err := m.Alerter.Alert(ctx, alert); err != nil {
+		m.logger.Error("failed to send alert", map[string]any{"network": m.Network, "error": err})
+	}
+}
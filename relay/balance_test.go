@@ -0,0 +1,131 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// fakeBalance 返回可修改的余额
+type fakeBalance struct {
+	balance *big.Int
+	err     error
+}
+
+func (b *fakeBalance) Balance(ctx context.Context, address string) (*big.Int, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return new(big.Int).Set(b.balance), nil
+}
+
+func TestBalanceMonitorThresholds(t *testing.T) {
+	reader := &fakeBalance{balance: big.NewInt(1000)}
+	if _, err := NewBalanceMonitor("bsc", "0xrelayer", reader, big.NewInt(10), big.NewInt(100)); !errors.Is(err, ErrInvalidBalanceConfig) {
+		t.Fatalf("Expected ErrInvalidBalanceConfig for warning below critical, got %v", err)
+	}
+	monitor, err := NewBalanceMonitor("bsc", "0xrelayer", reader, big.NewInt(500), big.NewInt(100))
+	if err != nil {
+		t.Fatalf("NewBalanceMonitor failed: %v", err)
+	}
+	alerts := &recordingAlerter{}
+	monitor.Alerter = alerts
+	monitor.MinRunway = 24 * time.Hour
+	now := time.Now()
+	monitor.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if status := monitor.Check(ctx); status.Level != BalanceOK || status.Runway != 0 || len(*alerts) != 0 {
+		t.Fatalf("Expected ok without runway, got %+v, %+v", status, *alerts)
+	}
+
+	// 每小时消耗 100，剩余 900 可用 9 小时，低于最短可用时间
+	now = now.Add(time.Hour)
+	reader.balance = big.NewInt(900)
+	status := monitor.Check(ctx)
+	if status.Level != BalanceWarning || status.Runway != 9*time.Hour || status.Spent.Int64() != 100 || status.Paused {
+		t.Fatalf("Expected warning with 9h runway, got %+v", status)
+	}
+
+	// 低于严重阈值时暂停提交，级别不变时不重复告警
+	now = now.Add(time.Hour)
+	reader.balance = big.NewInt(50)
+	monitor.Check(ctx)
+	now = now.Add(time.Minute)
+	if status := monitor.Check(ctx); status.Level != BalanceCritical || !status.Paused || !monitor.funds().Paused() {
+		t.Fatalf("Expected critical and paused, got %+v", status)
+	}
+	if len(*alerts) != 2 || (*alerts)[1].Severity != AlertCritical {
+		t.Fatalf("Expected warning and critical alerts, got %+v", *alerts)
+	}
+
+	// 充值不计入消耗，恢复后重新提交
+	now = now.Add(time.Minute)
+	reader.balance = big.NewInt(100_000)
+	status = monitor.Check(ctx)
+	if status.Level != BalanceOK || status.Paused || monitor.funds().Paused() || status.Spent.Int64() != 950 {
+		t.Fatalf("Expected recovery, got %+v", status)
+	}
+	if last := (*alerts)[len(*alerts)-1]; last.Message != "relayer balance recovered" {
+		t.Errorf("Expected recovery alert, got %+v", last)
+	}
+
+	// 查询失败时保留上次的级别和暂停状态
+	reader.err = errors.New("connection refused")
+	if status := monitor.Check(ctx); status.Error == "" || status.Level != BalanceOK || status.Paused {
+		t.Errorf("Expected previous level on read failure, got %+v", status)
+	}
+}
+
+func TestBalanceMonitorExhausted(t *testing.T) {
+	reader := &fakeBalance{balance: big.NewInt(1000)}
+	monitor, _ := NewBalanceMonitor("bsc", "0xrelayer", reader, nil, big.NewInt(100))
+	monitor.Alerter = nil
+	ctx := context.Background()
+
+	// 余额高于阈值但不足以支付 gas 时，交易失败后暂停，直到充值
+	monitor.Exhausted(ctx, ErrInsufficientFunds)
+	if status := monitor.Check(ctx); status.Level != BalanceCritical || !status.Paused {
+		t.Fatalf("Expected exhausted account to stay paused, got %+v", status)
+	}
+	reader.balance = big.NewInt(2000)
+	if status := monitor.Check(ctx); status.Level != BalanceOK || status.Paused {
+		t.Fatalf("Expected top-up to resume submission, got %+v", status)
+	}
+}
+
+func TestInTunnelRequeuesOnInsufficientFunds(t *testing.T) {
+	states := memStates{}
+	tunnel := NewInTunnel(&fakeSource{}, &fakeTarget{}, fakeSigner{}, nil)
+	tunnel.States = NewStateMachine(states)
+	tunnel.Queue = NewQueue[InMsg](0, 16)
+	tunnel.DeadLetters = NewDeadLetterQueue(newMemDeadLetters())
+	tunnel.Funds, _ = NewBalanceMonitor("bsc", "0xrelayer", &fakeBalance{balance: big.NewInt(1000)}, nil, big.NewInt(100))
+	tunnel.Funds.Alerter = nil
+	ctx := context.Background()
+
+	msg := InMsg{Nonce: 1, ChainID: "56", TxHash: testTxHash, Amount: "100"}
+	tunnel.fail(ctx, ProcessError{Msg: msg, Err: ErrInsufficientFunds})
+	if !tunnel.Funds.funds().Paused() {
+		t.Fatal("Expected submission to be paused")
+	}
+	select {
+	case replayed := <-tunnel.replays:
+		if replayed.Nonce != 1 {
+			t.Fatalf("Unexpected replayed message %+v", replayed)
+		}
+	default:
+		t.Fatal("Expected message to be requeued")
+	}
+	if dead, _ := tunnel.DeadLetters.List(""); len(dead) != 0 {
+		t.Fatalf("Expected no dead letters, got %+v", dead)
+	}
+
+	// 其他失败仍进入死信
+	tunnel.fail(ctx, ProcessError{Msg: msg, Err: ErrProcessingFailed})
+	if dead, _ := tunnel.DeadLetters.List(""); len(dead) != 1 {
+		t.Fatalf("Expected one dead letter, got %+v", dead)
+	}
+}
@@ -0,0 +1,231 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/types"
+)
+
+var (
+	// ErrBatchUnsupported 目标端不支持批量提交
+	ErrBatchUnsupported = errors.New("batch processing not supported")
+	// ErrSubmissionPending 交易已广播但在等待时间内未打包，仍可能上链，不能以新的交易重新提交
+	ErrSubmissionPending = errors.New("submission pending")
+)
+
+func init() {
+	types.RegisterClass(ErrBatchUnsupported, types.ClassFatal)
+	types.RegisterClass(ErrSubmissionPending, types.ClassRetryable)
+}
+
+// BatchProcessor 由支持批量合约方法的目标端实现
+type BatchProcessor interface {
+	// ProcessBatch 通过批量合约方法提交一批跨入消息，批次整体成功或失败
+	ProcessBatch(ctx context.Context, batch *BatchMsg) error
+}
+
+// BatchConfig 定义跨入消息的聚合条件，任一条件满足即提交批次
+type BatchConfig struct {
+	MaxCount   int           // 批次最大消息数
+	MaxValue   *big.Int      // 批次金额上限，为 nil 时不限制
+	Window     time.Duration // 批次中第一条消息的最长等待时间
+	MaxRetries int           // 交易广播前发生连接类错误时整批重试的次数
+}
+
+// DefaultBatchConfig 返回默认的聚合条件
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		MaxCount:   100,
+		Window:     3 * time.Second,
+		MaxRetries: 3,
+	}
+}
+
+// BatchMetrics 是批量提交计数器的快照
+type BatchMetrics struct {
+	Batches  uint64 `json:"batches"`  // 成功提交的批次数
+	Messages uint64 `json:"messages"` // 成功提交的消息数
+	Splits   uint64 `json:"splits"`   // 因失败拆分的次数
+	Failed   uint64 `json:"failed"`   // 拆分到单条后仍失败的消息数
+}
+
+// Batcher 将按 nonce 顺序到达的跨入消息聚合为批次提交。
+// 批次失败时：连接类错误按退避整批重试，其他错误将批次对半拆分后分别重试，
+// 拆分到单条仍失败的消息交给 OnFailed 处理。
+type Batcher struct {
+	Config    BatchConfig
+	Processor BatchProcessor
+	Backoff   Backoff
+	OnFailed  func(msg InMsg, err error)
+
+	mu      sync.Mutex
+	metrics BatchMetrics
+	logger  *log.Logger
+}
+
+func NewBatcher(config BatchConfig, processor BatchProcessor) *Batcher {
+	return &Batcher{
+		Config:    config,
+		Processor: processor,
+		Backoff:   DefaultBackoff(),
+		logger:    log.WithComponent("batcher"),
+	}
+}
+
+// Run 从 msgs 读取消息并按聚合条件提交批次，直到 ctx 取消或 msgs 关闭。
+// 整批重试耗尽时返回错误，未提交的消息由持久化队列在重启后重新投递
+func (b *Batcher) Run(ctx context.Context, msgs <-chan InMsg) error {
+	var (
+		pending []InMsg
+		total   = new(big.Int)
+		timer   *time.Timer
+		timeout <-chan time.Time
+	)
+
+	flush := func() error {
+		if timer != nil {
+			timer.Stop()
+			timer, timeout = nil, nil
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		batch := NewBatchMsg(pending)
+		pending, total = nil, new(big.Int)
+		return b.submit(ctx, &batch)
+	}
+
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return flush()
+			}
+			// 批次内 nonce 必须连续，且只包含同一种目标端代币；运维指定了手续费策略的消息单独提交，
+			// 策略只作用于该消息
+			if last := len(pending) - 1; last >= 0 && (msg.Nonce != pending[last].Nonce+1 || msg.TargetToken != pending[last].TargetToken ||
+				msg.FeePolicy != nil || pending[last].FeePolicy != nil) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+
+			pending = append(pending, msg)
+			if v, err := msg.Value(); err == nil {
+				total.Add(total, v)
+			}
+			if len(pending) == 1 && b.Config.Window > 0 {
+				timer = time.NewTimer(b.Config.Window)
+				timeout = timer.C
+			}
+			if b.full(len(pending), total) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		case <-timeout:
+			if err := flush(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil
+		}
+	}
+}
+
+// full 判断批次是否满足提交条件
+func (b *Batcher) full(count int, total *big.Int) bool {
+	if b.Config.MaxCount > 0 && count >= b.Config.MaxCount {
+		return true
+	}
+	return b.Config.MaxValue != nil && total.Cmp(b.Config.MaxValue) >= 0
+}
+
+// submit 提交批次，失败时重试或拆分。交易已广播但未确认（ErrSubmissionPending）时不重试也不拆分，
+// 批次仍可能上链，由确认循环根据目标端序号完成
+func (b *Batcher) submit(ctx context.Context, batch *BatchMsg) error {
+	for attempt := 0; ; attempt++ {
+		err := b.Processor.ProcessBatch(ctx, batch)
+		if err == nil {
+			b.mu.Lock()
+			b.metrics.Batches++
+			b.metrics.Messages += uint64(len(batch.Msgs))
+			b.mu.Unlock()
+			b.logger.Debug("batch submitted", map[string]any{
+				"from_nonce": batch.FromNonce,
+				"to_nonce":   batch.ToNonce,
+				"count":      len(batch.Msgs),
+				"total":      batch.Total,
+			})
+			return nil
+		}
+
+		if errors.Is(err, ErrSubmissionPending) {
+			b.logger.Warn("batch transaction pending", map[string]any{
+				"from_nonce": batch.FromNonce,
+				"to_nonce":   batch.ToNonce,
+				"error":      err,
+			})
+			return err
+		}
+		switch types.Classify(err) {
+		case types.ClassConnection, types.ClassTimeout:
+			// 交易未广播，批次本身没有问题，整批重试
+			if attempt >= b.Config.MaxRetries {
+				return err
+			}
+			select {
+			case <-time.After(b.Backoff.Delay(attempt)):
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if errors.Is(err, ErrBatchUnsupported) {
+			return err
+		}
+
+		if len(batch.Msgs) == 1 {
+			b.mu.Lock()
+			b.metrics.Failed++
+			b.mu.Unlock()
+			b.logger.Error("message failed in batch", map[string]any{
+				"nonce": batch.FromNonce,
+				"error": err,
+			})
+			if b.OnFailed != nil {
+				b.OnFailed(batch.Msgs[0], err)
+			}
+			return nil
+		}
+
+		left, right := batch.Split()
+		b.mu.Lock()
+		b.metrics.Splits++
+		b.mu.Unlock()
+		b.logger.Warn("batch failed, splitting", map[string]any{
+			"from_nonce": batch.FromNonce,
+			"to_nonce":   batch.ToNonce,
+			"error":      err,
+		})
+		if err := b.submit(ctx, &left); err != nil {
+			return err
+		}
+		return b.submit(ctx, &right)
+	}
+}
+
+// Metrics 返回批量提交计数器的快照
+func (b *Batcher) Metrics() BatchMetrics {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.metrics
+}
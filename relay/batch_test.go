@@ -0,0 +1,207 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// fakeBatchProcessor 记录提交的批次，包含 bad 中任一 nonce 的批次整体失败
+type fakeBatchProcessor struct {
+	mu      sync.Mutex
+	bad     map[uint64]bool
+	batches [][2]uint64
+}
+
+func (p *fakeBatchProcessor) ProcessBatch(ctx context.Context, batch *BatchMsg) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, msg := range batch.Msgs {
+		if p.bad[msg.Nonce] {
+			return errors.New("execution reverted")
+		}
+	}
+	p.batches = append(p.batches, [2]uint64{batch.FromNonce, batch.ToNonce})
+	return nil
+}
+
+func (p *fakeBatchProcessor) Batches() [][2]uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([][2]uint64(nil), p.batches...)
+}
+
+func runBatcher(t *testing.T, b *Batcher, msgs []InMsg) {
+	t.Helper()
+	ch := make(chan InMsg, len(msgs))
+	for _, msg := range msgs {
+		ch <- msg
+	}
+	close(ch)
+	if err := b.Run(context.Background(), ch); err != nil {
+		t.Fatalf("Batcher.Run failed: %v", err)
+	}
+}
+
+func seqMsgs(from, to uint64, amount string) []InMsg {
+	var msgs []InMsg
+	for n := from; n <= to; n++ {
+		msgs = append(msgs, InMsg{Nonce: n, Amount: amount})
+	}
+	return msgs
+}
+
+func TestBatcherFlushesByCountAndValue(t *testing.T) {
+	p := &fakeBatchProcessor{}
+	b := NewBatcher(BatchConfig{MaxCount: 3, MaxValue: big.NewInt(100)}, p)
+
+	msgs := seqMsgs(1, 4, "10")                                           // 按数量：[1,3]，剩余 4
+	msgs = append(msgs, InMsg{Nonce: 5, Amount: "95"})                    // 按金额：[4,5]
+	msgs = append(msgs, InMsg{Nonce: 7, Amount: "1"})                     // nonce 6 缺失，单独成批
+	msgs = append(msgs, InMsg{Nonce: 8, Amount: "1", TargetToken: "0xb"}) // 目标端代币不同，单独成批
+	runBatcher(t, b, msgs)
+
+	want := [][2]uint64{{1, 3}, {4, 5}, {7, 7}, {8, 8}}
+	got := p.Batches()
+	if len(got) != len(want) {
+		t.Fatalf("Expected batches %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected batches %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestBatcherFlushesByWindow(t *testing.T) {
+	p := &fakeBatchProcessor{}
+	b := NewBatcher(BatchConfig{MaxCount: 100, Window: 10 * time.Millisecond}, p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan InMsg, 2)
+	go b.Run(ctx, ch)
+	ch <- InMsg{Nonce: 1, Amount: "1"}
+	ch <- InMsg{Nonce: 2, Amount: "1"}
+
+	deadline := time.Now().Add(time.Second)
+	for len(p.Batches()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected batch to be flushed after window")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := p.Batches(); got[0] != [2]uint64{1, 2} {
+		t.Errorf("Unexpected batch %v", got[0])
+	}
+}
+
+func TestBatcherSplitsOnFailure(t *testing.T) {
+	p := &fakeBatchProcessor{bad: map[uint64]bool{3: true}}
+	b := NewBatcher(BatchConfig{MaxCount: 8}, p)
+
+	var failed []uint64
+	b.OnFailed = func(msg InMsg, err error) { failed = append(failed, msg.Nonce) }
+	runBatcher(t, b, seqMsgs(1, 8, "1"))
+
+	// [1,8] -> [1,4] [5,8]; [1,4] -> [1,2] [3,4]; [3,4] -> [3] [4]
+	want := [][2]uint64{{1, 2}, {4, 4}, {5, 8}}
+	got := p.Batches()
+	if len(got) != len(want) {
+		t.Fatalf("Expected batches %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected batches %v, got %v", want, got)
+			break
+		}
+	}
+	if len(failed) != 1 || failed[0] != 3 {
+		t.Errorf("Expected nonce 3 to fail, got %v", failed)
+	}
+	if m := b.Metrics(); m.Splits != 3 || m.Failed != 1 || m.Messages != 7 {
+		t.Errorf("Unexpected metrics %+v", m)
+	}
+}
+
+// policyProcessor 记录每个批次的消息数和手续费策略
+type policyProcessor struct {
+	mu       sync.Mutex
+	counts   []int
+	policies []*types.FeePolicy
+}
+
+func (p *policyProcessor) ProcessBatch(ctx context.Context, batch *BatchMsg) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counts = append(p.counts, len(batch.Msgs))
+	p.policies = append(p.policies, batch.FeePolicy)
+	return nil
+}
+
+func TestBatcherSubmitsReplayedFeePolicy(t *testing.T) {
+	dlq := NewDeadLetterQueue(newMemDeadLetters())
+	dl, _ := dlq.Add("InTunnel", ProcessError{Msg: InMsg{Nonce: 2, ChainID: "56", TxHash: testTxHash, Amount: "10"}, Err: errors.New("underpriced")})
+
+	msgs := seqMsgs(1, 3, "10")
+	dlq.Register("InTunnel", func(m InMsg) error {
+		msgs[1] = m
+		return nil
+	})
+	if err := dlq.SetFeePolicy("alice", dl.ID, &types.FeePolicy{GasPrice: "5000000000", GasLimit: 300000}); err != nil {
+		t.Fatalf("Failed to set fee policy: %v", err)
+	}
+	if err := dlq.Replay("alice", dl.ID); err != nil {
+		t.Fatalf("Failed to replay: %v", err)
+	}
+
+	// 重新投递的消息单独成批，策略随批次交给提交方
+	p := &policyProcessor{}
+	runBatcher(t, NewBatcher(BatchConfig{MaxCount: 10}, p), msgs)
+	if fmt.Sprint(p.counts) != "[1 1 1]" {
+		t.Fatalf("Expected the replayed message in its own batch, got %v", p.counts)
+	}
+	if p.policies[0] != nil || p.policies[2] != nil {
+		t.Error("Expected other batches to use the default fee")
+	}
+	if got := p.policies[1]; got == nil || got.GasPrice != "5000000000" || got.GasLimit != 300000 {
+		t.Errorf("Expected edited fee policy to reach the submitter, got %+v", got)
+	}
+}
+
+// pendingProcessor 模拟交易已广播但等待打包超时的目标端
+type pendingProcessor struct {
+	calls int
+}
+
+func (p *pendingProcessor) ProcessBatch(ctx context.Context, batch *BatchMsg) error {
+	p.calls++
+	return fmt.Errorf("%w: 0x01 nonce 5: %w", ErrSubmissionPending, context.DeadlineExceeded)
+}
+
+func TestBatcherDoesNotResubmitPendingBatch(t *testing.T) {
+	p := &pendingProcessor{}
+	b := NewBatcher(BatchConfig{MaxCount: 4, MaxRetries: 3}, p)
+	b.Backoff = Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+	var failed []uint64
+	b.OnFailed = func(msg InMsg, err error) { failed = append(failed, msg.Nonce) }
+
+	ch := make(chan InMsg, 4)
+	for _, msg := range seqMsgs(1, 4, "1") {
+		ch <- msg
+	}
+	close(ch)
+	err := b.Run(context.Background(), ch)
+
+	// 已广播的批次可能上链，不能重试、拆分或记为失败
+	if !errors.Is(err, ErrSubmissionPending) || p.calls != 1 || len(failed) != 0 || b.Metrics().Splits != 0 {
+		t.Errorf("Expected one pending submission, got %v, %d calls, failed %v, %+v", err, p.calls, failed, b.Metrics())
+	}
+}
@@ -0,0 +1,134 @@
+package relay
+
+import (
+	"context"
+	"sync"
+
+	"github.com/st-chain/me-bridge/signer"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// ContractPauser 由支持暂停跨链合约的终端实现，紧急停止时使用运维密钥调用合约 pause()，返回交易哈希
+type ContractPauser interface {
+	PauseContract(ctx context.Context, key signer.Signer) (string, error)
+}
+
+// Gate 是可在运行时暂停和恢复的开关，nil Gate 始终打开
+type Gate struct {
+	mu      sync.Mutex
+	paused  bool
+	changed chan struct{} // 状态变化时关闭
+}
+
+// Set 设置是否暂停
+func (g *Gate) Set(paused bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.paused == paused {
+		return
+	}
+	g.paused = paused
+	if g.changed != nil {
+		close(g.changed)
+		g.changed = nil
+	}
+}
+
+// Paused 返回是否暂停
+func (g *Gate) Paused() bool {
+	paused, _ := g.State()
+	return paused
+}
+
+// State 返回是否暂停，以及状态下次变化时关闭的通道
+func (g *Gate) State() (bool, <-chan struct{}) {
+	if g == nil {
+		return false, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.changed == nil {
+		g.changed = make(chan struct{})
+	}
+	return g.paused, g.changed
+}
+
+// Controls 是一个跨链桥的运行控制开关，由桥下的 InTunnel 和 OutTunnel 共享
+type Controls struct {
+	Inbound  *Gate // 暂停后不再接收新的跨链消息（InTunnel 的源端、OutTunnel 的目标端）
+	Outbound *Gate // 暂停后不再提交交易
+}
+
+func NewControls() *Controls {
+	return &Controls{Inbound: &Gate{}, Outbound: &Gate{}}
+}
+
+// Apply 按持久化的控制状态设置开关，紧急停止时两个方向都暂停
+func (c *Controls) Apply(state types.BridgeControl) {
+	if c == nil {
+		return
+	}
+	c.Inbound.Set(state.InboundPaused || state.EmergencyStop)
+	c.Outbound.Set(state.OutboundPaused || state.EmergencyStop)
+}
+
+func (c *Controls) inbound() *Gate {
+	if c == nil {
+		return nil
+	}
+	return c.Inbound
+}
+
+func (c *Controls) outbound() *Gate {
+	if c == nil {
+		return nil
+	}
+	return c.Outbound
+}
+
+// intakeState 合并排空、暂停开关和账户余额开关的状态，子任务在 select 中监听 drained、changed 和 funded
+// 并据此决定是否接收新消息
+type intakeState struct {
+	gate     *Gate
+	funds    *Gate           // 提交账户余额不足时暂停，只有提交交易的子任务设置
+	drained  <-chan struct{} // 排空时关闭，之后置为 nil
+	changed  <-chan struct{} // 开关状态变化时关闭
+	funded   <-chan struct{} // 余额开关状态变化时关闭
+	draining bool
+	paused   bool
+	unfunded bool
+}
+
+// intake 返回子任务本次运行的接收状态
+func (l *lifecycle) intake(gate *Gate) *intakeState {
+	s := &intakeState{gate: gate, drained: l.intakeDone()}
+	s.paused, s.changed = gate.State()
+	return s
+}
+
+// withFunds 同时监听提交账户的余额开关
+func (s *intakeState) withFunds(funds *Gate) *intakeState {
+	s.funds = funds
+	s.unfunded, s.funded = funds.State()
+	return s
+}
+
+// Open 返回是否接收新消息
+func (s *intakeState) Open() bool {
+	return !s.draining && !s.paused && !s.unfunded
+}
+
+// drain 在 drained 关闭后调用
+func (s *intakeState) drain() {
+	s.draining, s.drained = true, nil
+}
+
+// refresh 在 changed 关闭后调用
+func (s *intakeState) refresh() {
+	s.paused, s.changed = s.gate.State()
+}
+
+// refreshFunds 在 funded 关闭后调用
+func (s *intakeState) refreshFunds() {
+	s.unfunded, s.funded = s.funds.State()
+}
@@ -0,0 +1,60 @@
+package relay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+func TestGateNotifiesChanges(t *testing.T) {
+	var nilGate *Gate
+	if paused, changed := nilGate.State(); paused || changed != nil {
+		t.Error("Expected nil gate to be open")
+	}
+
+	g := &Gate{}
+	paused, changed := g.State()
+	if paused {
+		t.Fatal("Expected new gate to be open")
+	}
+	g.Set(true)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("Expected change to be notified")
+	}
+	if !g.Paused() {
+		t.Error("Expected gate to be paused")
+	}
+}
+
+func TestOutTunnelPausesSubmission(t *testing.T) {
+	source := &gatedSource{}
+	target := &fakeTarget{out: []OutMsg{{Nonce: 1}, {Nonce: 2}}}
+	controls := NewControls()
+	controls.Apply(types.BridgeControl{OutboundPaused: true})
+
+	tunnel := NewOutTunnel(source, target, fakeSigner{})
+	tunnel.Controls = controls
+	tunnel.ConfirmInterval = time.Millisecond
+	tunnel.ErrorHandler = nil
+	if err := tunnel.Start(); err != nil {
+		t.Fatalf("Failed to start tunnel: %v", err)
+	}
+	defer tunnel.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	if n := source.Received(); n != 0 {
+		t.Fatalf("Expected no submissions while paused, got %d", n)
+	}
+
+	controls.Apply(types.BridgeControl{})
+	deadline := time.Now().Add(2 * time.Second)
+	for source.Received() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected submissions after resume, got %d", source.Received())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
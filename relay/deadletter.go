@@ -0,0 +1,207 @@
+package relay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// 死信操作错误
+var (
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrDeadLetterClosed   = errors.New("dead letter already handled")
+	ErrNoReplayer         = errors.New("no running tunnel for dead letter queue")
+)
+
+// 审计日志中的死信操作类型
+const (
+	AuditDeadLetterFee     = "dead_letter.fee"
+	AuditDeadLetterReplay  = "dead_letter.replay"
+	AuditDeadLetterDiscard = "dead_letter.discard"
+)
+
+// Replayer 将死信重新投递到所属 Tunnel
+type Replayer func(msg InMsg) error
+
+// DeadLetterQueue 管理重试耗尽或发生致命错误的消息。
+// 死信等待运维检查、调整手续费策略后重新投递或永久丢弃，每次运维操作都写入审计日志。
+type DeadLetterQueue struct {
+	store  types.DeadLetterStore
+	logger *log.Logger
+
+	mu        sync.RWMutex
+	replayers map[string]Replayer // Tunnel 路径 -> 重新投递函数
+}
+
+func NewDeadLetterQueue(store types.DeadLetterStore) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		store:     store,
+		logger:    log.WithComponent("dead-letter"),
+		replayers: make(map[string]Replayer),
+	}
+}
+
+// Register 注册 Tunnel 的重新投递函数，Tunnel 停止时以 nil 注销
+func (d *DeadLetterQueue) Register(queue string, replayer Replayer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if replayer == nil {
+		delete(d.replayers, queue)
+		return
+	}
+	d.replayers[queue] = replayer
+}
+
+// Add 将处理失败的消息加入死信。同一消息再次失败时累加处理次数和错误链，并重新置为待处理
+func (d *DeadLetterQueue) Add(queue string, pe ProcessError) (*types.DeadLetter, error) {
+	id, err := pe.Msg.ID()
+	if err != nil {
+		id = fmt.Sprintf("%s:%d", queue, pe.Msg.Nonce)
+	}
+	payload, err := json.Marshal(pe.Msg)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	dl, err := d.store.GetDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+	if dl == nil {
+		dl = &types.DeadLetter{ID: id, Queue: queue, Nonce: pe.Msg.Nonce, CreatedAt: now}
+	}
+	dl.Payload = payload
+	dl.Errors = append(dl.Errors, ErrorChain(pe.Err)...)
+	dl.Attempts += pe.RetryCount + 1
+	if pe.TxHash != "" {
+		dl.LastTxHash = pe.TxHash
+	}
+	dl.Status = types.DeadLetterPending
+	dl.UpdatedAt = now
+
+	if err := d.store.SaveDeadLetter(*dl); err != nil {
+		return nil, err
+	}
+	d.logger.Error("message moved to dead letter queue", map[string]any{
+		"id":       id,
+		"queue":    queue,
+		"nonce":    pe.Msg.Nonce,
+		"attempts": dl.Attempts,
+		"error":    pe.Err,
+	})
+	return dl, nil
+}
+
+// List 返回指定状态的死信，status 为空时返回全部
+func (d *DeadLetterQueue) List(status types.DeadLetterStatus) ([]types.DeadLetter, error) {
+	return d.store.ListDeadLetters(status)
+}
+
+// Get 返回死信
+func (d *DeadLetterQueue) Get(id string) (*types.DeadLetter, error) {
+	dl, err := d.store.GetDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+	if dl == nil {
+		return nil, ErrDeadLetterNotFound
+	}
+	return dl, nil
+}
+
+// Audit 返回死信的审计日志
+func (d *DeadLetterQueue) Audit(id string) ([]types.AuditEntry, error) {
+	return d.store.ListAudit(id)
+}
+
+// SetFeePolicy 设置死信重新投递时使用的手续费策略，policy 为 nil 时恢复默认策略
+func (d *DeadLetterQueue) SetFeePolicy(operator, id string, policy *types.FeePolicy) error {
+	detail, _ := json.Marshal(policy)
+	return d.audited(operator, AuditDeadLetterFee, id, string(detail), func(dl *types.DeadLetter) error {
+		dl.FeePolicy = policy
+		return nil
+	})
+}
+
+// Replay 将死信连同其手续费策略重新投递到所属 Tunnel
+func (d *DeadLetterQueue) Replay(operator, id string) error {
+	return d.audited(operator, AuditDeadLetterReplay, id, "", func(dl *types.DeadLetter) error {
+		d.mu.RLock()
+		replay, ok := d.replayers[dl.Queue]
+		d.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrNoReplayer, dl.Queue)
+		}
+
+		var msg InMsg
+		if err := json.Unmarshal(dl.Payload, &msg); err != nil {
+			return err
+		}
+		msg.FeePolicy = dl.FeePolicy
+		if err := replay(msg); err != nil {
+			return err
+		}
+		dl.Status = types.DeadLetterReplayed
+		return nil
+	})
+}
+
+// Discard 永久丢弃死信
+func (d *DeadLetterQueue) Discard(operator, id, reason string) error {
+	return d.audited(operator, AuditDeadLetterDiscard, id, reason, func(dl *types.DeadLetter) error {
+		dl.Status = types.DeadLetterDiscarded
+		return nil
+	})
+}
+
+// audited 对待处理的死信执行运维操作并写入审计日志，操作失败同样记录
+func (d *DeadLetterQueue) audited(operator, action, id, detail string, op func(dl *types.DeadLetter) error) error {
+	err := d.apply(id, op)
+
+	entry := types.AuditEntry{
+		Time:     time.Now(),
+		Operator: operator,
+		Action:   action,
+		Target:   id,
+		Detail:   detail,
+		Result:   "ok",
+	}
+	if err != nil {
+		entry.Result = err.Error()
+	}
+	if auditErr := d.store.AppendAudit(entry); auditErr != nil {
+		d.logger.Error("failed to write audit log", map[string]any{"entry": entry, "error": auditErr})
+		if err == nil {
+			err = auditErr
+		}
+	}
+
+	d.logger.Info("dead letter operation", map[string]any{
+		"operator": operator,
+		"action":   action,
+		"id":       id,
+		"result":   entry.Result,
+	})
+	return err
+}
+
+func (d *DeadLetterQueue) apply(id string, op func(dl *types.DeadLetter) error) error {
+	dl, err := d.Get(id)
+	if err != nil {
+		return err
+	}
+	if dl.Status != types.DeadLetterPending {
+		return fmt.Errorf("%w: %s is %s", ErrDeadLetterClosed, id, dl.Status)
+	}
+	if err := op(dl); err != nil {
+		return err
+	}
+	dl.UpdatedAt = time.Now()
+	return d.store.SaveDeadLetter(*dl)
+}
@@ -0,0 +1,142 @@
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// memDeadLetters 是测试用的内存死信存储
+type memDeadLetters struct {
+	dead  map[string]types.DeadLetter
+	audit []types.AuditEntry
+}
+
+func newMemDeadLetters() *memDeadLetters {
+	return &memDeadLetters{dead: make(map[string]types.DeadLetter)}
+}
+
+func (s *memDeadLetters) SaveDeadLetter(dl types.DeadLetter) error {
+	s.dead[dl.ID] = dl
+	return nil
+}
+
+func (s *memDeadLetters) GetDeadLetter(id string) (*types.DeadLetter, error) {
+	dl, ok := s.dead[id]
+	if !ok {
+		return nil, nil
+	}
+	return &dl, nil
+}
+
+func (s *memDeadLetters) ListDeadLetters(status types.DeadLetterStatus) ([]types.DeadLetter, error) {
+	var out []types.DeadLetter
+	for _, dl := range s.dead {
+		if status == "" || dl.Status == status {
+			out = append(out, dl)
+		}
+	}
+	return out, nil
+}
+
+func (s *memDeadLetters) AppendAudit(entry types.AuditEntry) error {
+	s.audit = append(s.audit, entry)
+	return nil
+}
+
+func (s *memDeadLetters) ListAudit(target string) ([]types.AuditEntry, error) {
+	var out []types.AuditEntry
+	for _, e := range s.audit {
+		if target == "" || e.Target == target {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func TestDeadLetterQueueRecordsFailures(t *testing.T) {
+	store := newMemDeadLetters()
+	dlq := NewDeadLetterQueue(store)
+	msg := InMsg{Nonce: 7, ChainID: "56", TxHash: testTxHash, Amount: "100"}
+
+	cause := errors.New("execution reverted")
+	dl, err := dlq.Add("InTunnel", ProcessError{Msg: msg, Err: fmt.Errorf("max retries exceeded: %w", cause), RetryCount: 3, TxHash: "0x01"})
+	if err != nil {
+		t.Fatalf("Failed to add dead letter: %v", err)
+	}
+	if id, _ := msg.ID(); dl.ID != id {
+		t.Errorf("Expected dead letter keyed by message id %s, got %s", id, dl.ID)
+	}
+	if dl.Attempts != 4 || dl.LastTxHash != "0x01" || len(dl.Errors) != 2 || dl.Errors[1] != cause.Error() {
+		t.Errorf("Unexpected dead letter %+v", dl)
+	}
+
+	// 同一消息再次失败时累加
+	dl, _ = dlq.Add("InTunnel", ProcessError{Msg: msg, Err: cause})
+	if dl.Attempts != 5 || len(dl.Errors) != 3 || dl.LastTxHash != "0x01" {
+		t.Errorf("Expected merged dead letter, got %+v", dl)
+	}
+
+	// 无法计算 ID 的消息按 queue:nonce 记录
+	dl, _ = dlq.Add("InTunnel", ProcessError{Msg: InMsg{Nonce: 8}, Err: ErrInvalidMessage})
+	if dl.ID != "InTunnel:8" {
+		t.Errorf("Expected fallback id, got %s", dl.ID)
+	}
+}
+
+func TestDeadLetterQueueOperatorActions(t *testing.T) {
+	store := newMemDeadLetters()
+	dlq := NewDeadLetterQueue(store)
+	msg := InMsg{Nonce: 7, ChainID: "56", TxHash: testTxHash, Amount: "100"}
+	dl, _ := dlq.Add("InTunnel", ProcessError{Msg: msg, Err: errors.New("underpriced")})
+
+	// 没有运行中的 Tunnel 时无法重新投递，失败同样记录审计
+	if err := dlq.Replay("alice", dl.ID); !errors.Is(err, ErrNoReplayer) {
+		t.Errorf("Expected ErrNoReplayer, got %v", err)
+	}
+
+	var replayed []InMsg
+	dlq.Register("InTunnel", func(m InMsg) error {
+		replayed = append(replayed, m)
+		return nil
+	})
+
+	policy := &types.FeePolicy{GasPrice: "5000000000"}
+	if err := dlq.SetFeePolicy("alice", dl.ID, policy); err != nil {
+		t.Fatalf("Failed to set fee policy: %v", err)
+	}
+	if err := dlq.Replay("alice", dl.ID); err != nil {
+		t.Fatalf("Failed to replay: %v", err)
+	}
+	if len(replayed) != 1 || replayed[0].Nonce != 7 || replayed[0].FeePolicy == nil || replayed[0].FeePolicy.GasPrice != "5000000000" {
+		t.Errorf("Unexpected replayed messages %+v", replayed)
+	}
+
+	// 已处理的死信不能再次操作
+	if err := dlq.Discard("bob", dl.ID, "duplicate"); !errors.Is(err, ErrDeadLetterClosed) {
+		t.Errorf("Expected ErrDeadLetterClosed, got %v", err)
+	}
+	if err := dlq.Discard("bob", "missing", ""); !errors.Is(err, ErrDeadLetterNotFound) {
+		t.Errorf("Expected ErrDeadLetterNotFound, got %v", err)
+	}
+
+	audit, _ := dlq.Audit(dl.ID)
+	var actions []string
+	for _, e := range audit {
+		actions = append(actions, e.Operator+":"+e.Action+":"+fmt.Sprint(e.Result == "ok"))
+	}
+	want := []string{
+		"alice:" + AuditDeadLetterReplay + ":false",
+		"alice:" + AuditDeadLetterFee + ":true",
+		"alice:" + AuditDeadLetterReplay + ":true",
+		"bob:" + AuditDeadLetterDiscard + ":false",
+	}
+	if fmt.Sprint(actions) != fmt.Sprint(want) {
+		t.Errorf("Expected audit %v, got %v", want, actions)
+	}
+	if got, _ := dlq.Get(dl.ID); got.Status != types.DeadLetterReplayed {
+		t.Errorf("Expected replayed status, got %s", got.Status)
+	}
+}
@@ -0,0 +1,136 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrTunnelDraining Tunnel 正在排空，不再接收新消息
+var ErrTunnelDraining = errors.New("tunnel is draining")
+
+// DrainPollInterval 是排空时检查在途消息的间隔
+const DrainPollInterval = 100 * time.Millisecond
+
+// DrainSummary 是 Tunnel 排空的结果
+type DrainSummary struct {
+	Path      string        `json:"path"`
+	Completed int           `json:"completed"` // 排空期间完成的在途消息数
+	Pending   []uint64      `json:"pending"`   // 截止时仍未完成的在途消息 nonce
+	Queued    int           `json:"queued"`    // 队列中尚未提交的消息数
+	Persisted bool          `json:"persisted"` // 剩余消息是否已持久化，重启后重新投递
+	TimedOut  bool          `json:"timed_out"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// intakeDone 返回排空时关闭的通道，尚未运行时返回 nil
+func (l *lifecycle) intakeDone() <-chan struct{} {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return l.drained
+}
+
+func (l *lifecycle) isDraining() bool {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return l.draining
+}
+
+// drain 停止接收新消息，等待 inflight 返回的在途消息全部完成或 ctx 结束，然后停止 Start 启动的 Tunnel。
+// 通过 Run 运行的 Tunnel 需由调用方在 drain 返回后取消 ctx
+func (l *lifecycle) drain(ctx context.Context, inflight func() []uint64) DrainSummary {
+	start := time.Now()
+
+	l.stateMu.Lock()
+	if l.drained != nil && !l.draining {
+		close(l.drained)
+	}
+	l.draining = true
+	l.stateMu.Unlock()
+	l.setState(StateDraining)
+
+	pending := inflight()
+	initial := len(pending)
+	timedOut := false
+
+	ticker := time.NewTicker(DrainPollInterval)
+	defer ticker.Stop()
+wait:
+	for len(pending) > 0 {
+		select {
+		case <-ticker.C:
+			pending = inflight()
+		case <-ctx.Done():
+			timedOut = true
+			break wait
+		}
+	}
+
+	l.Stop()
+	return DrainSummary{
+		Completed: max(initial-len(pending), 0),
+		Pending:   pending,
+		TimedOut:  timedOut,
+		Duration:  time.Since(start),
+	}
+}
+
+// sortedKeys 按升序返回 map 的 nonce
+func sortedKeys[V any](m map[uint64]V) []uint64 {
+	keys := make([]uint64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// Drain 停止接收源端新消息，等待已提交的消息在目标端完成或 ctx 结束后停止 Tunnel。
+// 未完成的消息保留在持久化队列和状态存储中，重启后重新投递
+func (t *InTunnel) Drain(ctx context.Context) DrainSummary {
+	summary := t.drain(ctx, func() []uint64 {
+		t.mu.RLock()
+		defer t.mu.RUnlock()
+		return sortedKeys(t.submitted)
+	})
+	summary.Path = t.Path
+	summary.Persisted = t.Store != nil
+	if t.Queue != nil {
+		summary.Queued = len(t.Queue.Msgs) + t.Queue.Metrics().Pending
+	}
+	t.logDrain(summary)
+	return summary
+}
+
+// Drain 停止接收目标端新消息，等待在途消息在源端完成并确认或 ctx 结束后停止 Tunnel
+func (t *OutTunnel) Drain(ctx context.Context) DrainSummary {
+	summary := t.drain(ctx, func() []uint64 {
+		t.mu.RLock()
+		defer t.mu.RUnlock()
+		return sortedKeys(t.inflight)
+	})
+	summary.Path = t.Path
+	summary.Persisted = t.Store != nil
+	if t.Queue != nil {
+		summary.Queued = len(t.Queue.Msgs) + t.Queue.Metrics().Pending
+	}
+	t.logDrain(summary)
+	return summary
+}
+
+func (l *lifecycle) logDrain(summary DrainSummary) {
+	fields := map[string]any{
+		"path":      summary.Path,
+		"completed": summary.Completed,
+		"pending":   summary.Pending,
+		"queued":    summary.Queued,
+		"persisted": summary.Persisted,
+		"duration":  summary.Duration.String(),
+	}
+	if summary.TimedOut || (!summary.Persisted && summary.Queued > 0) {
+		l.logger.Warn("tunnel drained with unfinished messages", fields)
+		return
+	}
+	l.logger.Info("tunnel drained", fields)
+}
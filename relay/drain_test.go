@@ -0,0 +1,111 @@
+package relay
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedSource 模拟源端：收到的跨出消息在 release 之后才视为已处理
+type gatedSource struct {
+	fakeSource
+
+	mu       sync.Mutex
+	received []uint64
+	released bool
+}
+
+func (s *gatedSource) ProcessOutMsgs(msgs <-chan OutMsg) error {
+	go func() {
+		for msg := range msgs {
+			s.mu.Lock()
+			s.received = append(s.received, msg.Nonce)
+			s.mu.Unlock()
+		}
+	}()
+	return nil
+}
+
+func (s *gatedSource) GetSequence() (uint64, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.released || len(s.received) == 0 {
+		return 0, 0
+	}
+	return s.received[len(s.received)-1], 0
+}
+
+func (s *gatedSource) Received() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.received)
+}
+
+func (s *gatedSource) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = true
+}
+
+func startGatedTunnel(t *testing.T) (*OutTunnel, *gatedSource, *fakeTarget) {
+	t.Helper()
+	source := &gatedSource{}
+	target := &fakeTarget{out: []OutMsg{{Nonce: 1}, {Nonce: 2}}}
+	tunnel := NewOutTunnel(source, target, fakeSigner{})
+	tunnel.ConfirmInterval = time.Millisecond
+	tunnel.ErrorHandler = nil
+	if err := tunnel.Start(); err != nil {
+		t.Fatalf("Failed to start tunnel: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for source.Received() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected messages to be submitted")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return tunnel, source, target
+}
+
+func TestDrainWaitsForInflightMessages(t *testing.T) {
+	tunnel, source, target := startGatedTunnel(t)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		source.Release()
+	}()
+	summary := tunnel.Drain(context.Background())
+
+	if summary.TimedOut || len(summary.Pending) != 0 || summary.Completed != 2 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if got := target.Confirmed(); !equalNonces(got, []uint64{1, 2}) {
+		t.Errorf("Expected confirmed [1 2], got %v", got)
+	}
+	if tunnel.State() != StateStopped {
+		t.Errorf("Expected stopped, got %s", tunnel.State())
+	}
+}
+
+func TestDrainStopsAtDeadline(t *testing.T) {
+	tunnel, source, _ := startGatedTunnel(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	summary := tunnel.Drain(ctx)
+
+	if !summary.TimedOut || !equalNonces(summary.Pending, []uint64{1, 2}) || summary.Completed != 0 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if summary.Persisted {
+		t.Error("Expected in-memory queue not to be reported as persisted")
+	}
+	if source.Received() != 2 {
+		t.Errorf("Expected no new submissions, got %d", source.Received())
+	}
+	if tunnel.State() != StateStopped {
+		t.Errorf("Expected stopped, got %s", tunnel.State())
+	}
+}
@@ -6,18 +6,30 @@ import "context"
 type InEndpoint interface {
 	InWatcher
 	OutProcessor
+
+	// 状态同步方法
+	GetSequence() (uint64, uint64)  // 返回已处理的跨出消息 (sequence, height)
+	GetNonce(address string) uint64 // 获取 nonce
 }
 
 // Watcher 订阅跨链消息
 type InWatcher interface {
+	// LatestHeight 返回源端最新区块高度
+	LatestHeight() (int64, error)
 	FilterInMsgs(fromHeight, toHeight uint64) ([]InMsg, error)
 	// SubscribeToInMsgs 订阅跨入消息，消息通过通道发送
 	SubscribeToInMsgs(msgs chan InMsg) error
 }
 
+// OutFilter 由能够按 nonce 区间重新查询跨出消息的目标端实现，用于回补跨出队列的缺口
+type OutFilter interface {
+	FilterOutMsgs(fromNonce, toNonce uint64) ([]OutMsg, error)
+}
+
 // Processor 处理跨链消息
+// 跨链费用由 Tunnel 持有的 FeeCalculator 计算，不属于终端接口
 type OutProcessor interface {
-	FeeCalculator
+	// ProcessOutMsgs 在源端执行跨出消息，交易必须使用消息的 TxNonce
 	ProcessOutMsgs(msgs <-chan OutMsg) error
 }
 
@@ -32,8 +44,9 @@ type OutEndpoint interface {
 
 // Processor 处理跨链消息
 type InProcessor interface {
-	// ProcessInMsgs 处理跨链消息
-	ProcessInMsgs(msgs <-chan InMsg) error
+	// ProcessInMsgs 处理跨链消息，重试耗尽或发生致命错误的消息通过返回的通道上报，
+	// msgs 关闭后处理结束时关闭错误通道
+	ProcessInMsgs(msgs <-chan InMsg) (<-chan ProcessError, error)
 
 	// HandleError 处理端点级别的错误
 	HandleError(ctx context.Context, err error, metadata map[string]interface{}) error
@@ -45,3 +58,8 @@ type OutWatcher interface {
 	ConfirmOutMsgs(msgs []OutMsg) error
 	SubscribeToBatchMsgs() (<-chan *BatchMsg, error)
 }
+
+// ProcessedChecker 由能够查询消息是否已在链上处理的终端实现，id 为 MessageID 计算的确定性 ID
+type ProcessedChecker interface {
+	IsProcessed(ctx context.Context, id string) (bool, error)
+}
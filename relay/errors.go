@@ -4,8 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
+
+	"github.com/st-chain/me-bridge/types"
 )
 
 // Common relay errors
@@ -20,6 +21,41 @@ var (
 	ErrGasEstimationFailed = errors.New("gas estimation failed")
 )
 
+func init() {
+	types.RegisterClass(ErrInvalidMessage, types.ClassFatal)
+	types.RegisterClass(ErrSubscriptionFailed, types.ClassConnection)
+	types.RegisterClass(ErrInsufficientFunds, types.ClassInsufficientFunds)
+	types.RegisterClass(ErrGasEstimationFailed, types.ClassRetryable)
+}
+
+// ProcessError 是目标端处理消息失败后上报给 Tunnel 的错误
+type ProcessError struct {
+	Msg         InMsg
+	Err         error
+	Recoverable bool      // 是否可以通过重试恢复
+	Timestamp   time.Time // 最后一次失败的时间
+	RetryCount  int       // 已重试次数
+	TxHash      string    // 最后一次提交的交易哈希
+}
+
+func (e ProcessError) Error() string {
+	return fmt.Sprintf("failed to process message %d: %v", e.Msg.Nonce, e.Err)
+}
+
+func (e ProcessError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorChain 按由外到内的顺序返回错误链中每一层的信息
+func ErrorChain(err error) []string {
+	var chain []string
+	for err != nil {
+		chain = append(chain, err.Error())
+		err = errors.Unwrap(err)
+	}
+	return chain
+}
+
 // ErrorAction 定义错误处理后的动作
 type ErrorAction int
 
@@ -51,6 +87,7 @@ const (
 
 // ErrorHandler 基础错误处理器
 type ErrorHandler struct {
+	Level      ErrorLevel
 	MaxRetries int
 	RetryDelay time.Duration
 }
@@ -89,67 +126,29 @@ func (h *ErrorHandler) HandleError(ctx context.Context, err error, metadata map[
 	}
 }
 
-// classifyError 对错误进行分类
+// classifyError 根据错误类别决定处理动作
 func (h *ErrorHandler) classifyError(err error) ErrorAction {
-	errMsg := strings.ToLower(err.Error())
-
-	// 可重试的错误
-	if h.isRetryableError(errMsg) {
+	switch types.Classify(err) {
+	case types.ClassConnection, types.ClassTimeout, types.ClassRetryable:
 		return ActionRetry
-	}
-
-	// 可忽略的错误
-	if h.isIgnorableError(errMsg) {
+	case types.ClassIgnorable:
 		return ActionIgnore
-	}
-
-	// 致命错误
-	if h.isFatalError(errMsg) {
+	case types.ClassFatal, types.ClassInsufficientFunds:
 		return ActionFatal
+	default:
+		// 默认升级处理
+		return ActionEscalate
 	}
-
-	// 默认升级处理
-	return ActionEscalate
-}
-
-func (h *ErrorHandler) isRetryableError(errMsg string) bool {
-	retryablePatterns := []string{
-		"timeout", "network", "connection", "temporary",
-		"nonce too low", "gas", "underpriced",
-	}
-
-	for _, pattern := range retryablePatterns {
-		if strings.Contains(errMsg, pattern) {
-			return true
-		}
-	}
-	return false
-}
-
-func (h *ErrorHandler) isIgnorableError(errMsg string) bool {
-	ignorablePatterns := []string{
-		"already known", "duplicate",
-	}
-
-	for _, pattern := range ignorablePatterns {
-		if strings.Contains(errMsg, pattern) {
-			return true
-		}
-	}
-	return false
 }
 
-func (h *ErrorHandler) isFatalError(errMsg string) bool {
-	fatalPatterns := []string{
-		"invalid signature", "unauthorized", "permission denied",
-	}
-
-	for _, pattern := range fatalPatterns {
-		if strings.Contains(errMsg, pattern) {
-			return true
-		}
+// IsRecoverable 判断错误是否可以通过重试恢复
+func IsRecoverable(err error) bool {
+	switch types.Classify(err) {
+	case types.ClassConnection, types.ClassTimeout, types.ClassRetryable:
+		return true
+	default:
+		return false
 	}
-	return false
 }
 
 func (h *ErrorHandler) handleRetry(ctx context.Context, err error, metadata map[string]interface{}) error {
@@ -1,10 +1,236 @@
 package relay
 
-import "math/big"
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
 
-// FeeCalculator 计算跨链交易的费用
-type FeeCalculator struct{}
+	"github.com/st-chain/me-bridge/types"
+)
 
+// 手续费错误
+var (
+	ErrInvalidFeeConfig   = errors.New("invalid fee config")
+	ErrFeeExceedsAmount   = errors.New("fee exceeds relayed amount")
+	ErrInsufficientFee    = errors.New("insufficient fee paid in source event")
+	ErrGasCostUnavailable = errors.New("gas cost unavailable")
+)
+
+func init() {
+	types.RegisterClass(ErrInvalidFeeConfig, types.ClassFatal)
+	types.RegisterClass(ErrFeeExceedsAmount, types.ClassFatal)
+	types.RegisterClass(ErrInsufficientFee, types.ClassFatal)
+	types.RegisterClass(ErrGasCostUnavailable, types.ClassRetryable)
+}
+
+// BpsDenominator 是基点的分母，1 bps = 0.01%
+const BpsDenominator = 10000
+
+// FeeModel 是手续费的计算方式
+type FeeModel string
+
+const (
+	FeeModelNone       FeeModel = ""           // 不收取手续费
+	FeeModelFlat       FeeModel = "flat"       // 固定手续费
+	FeeModelPercentage FeeModel = "percentage" // 按金额比例，可设置上下限
+	FeeModelTiered     FeeModel = "tiered"     // 按金额所在档位
+	FeeModelGas        FeeModel = "gas"        // 目标端 gas 成本加成
+)
+
+// FeeMode 是手续费的收取方式
+type FeeMode string
+
+const (
+	FeeModeDeduct   FeeMode = "deduct"   // 从跨链金额中扣除，接收方收到扣除后的金额
+	FeeModeRequired FeeMode = "required" // 源端事件中必须携带不少于计算值的手续费，跨链金额不变
+)
+
+// FeeTier 是分档手续费的一档，金额不超过 UpTo 时使用该档，UpTo 为 nil 表示不设上限。
+// 手续费 = Flat + 金额 × Bps / 10000
+type FeeTier struct {
+	UpTo *big.Int
+	Flat *big.Int
+	Bps  uint64
+}
+
+// GasCoster 返回一次中继交易的 gas 成本，以跨链代币的最小单位计
+type GasCoster interface {
+	GasCost() (*big.Int, error)
+}
+
+// StaticGasCost 按固定的 gas 上限和 gas 价格计算成本，适用于跨链代币即目标链原生代币的情况
+type StaticGasCost struct {
+	GasLimit uint64
+	GasPrice *big.Int
+}
+
+func (c StaticGasCost) GasCost() (*big.Int, error) {
+	if c.GasPrice == nil {
+		return nil, ErrGasCostUnavailable
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(c.GasLimit), c.GasPrice), nil
+}
+
+// FeeCalculator 计算跨链交易的费用。所有计算使用整数，比例按基点计算并向上取整，
+// 相同的输入总是得到相同的结果。nil FeeCalculator 不收取手续费
+type FeeCalculator struct {
+	Model FeeModel
+	Mode  FeeMode
+
+	Flat  *big.Int  // flat：固定手续费
+	Bps   uint64    // percentage：比例（基点）
+	Min   *big.Int  // percentage：下限，为 nil 时不限制
+	Max   *big.Int  // percentage：上限，为 nil 时不限制
+	Tiers []FeeTier // tiered：按 UpTo 升序排列，最后一档可以不设上限
+
+	Gas       GasCoster // gas：gas 成本来源
+	MarginBps uint64    // gas：在 gas 成本上的加成（基点）
+}
+
+// Validate 检查配置是否完整，并按 UpTo 对档位排序
+func (fc *FeeCalculator) Validate() error {
+	if fc == nil {
+		return nil
+	}
+	switch fc.Mode {
+	case FeeModeDeduct, FeeModeRequired:
+	case "":
+		fc.Mode = FeeModeDeduct
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidFeeConfig, fc.Mode)
+	}
+
+	switch fc.Model {
+	case FeeModelNone:
+	case FeeModelFlat:
+		if fc.Flat == nil || fc.Flat.Sign() < 0 {
+			return fmt.Errorf("%w: flat fee must be non-negative", ErrInvalidFeeConfig)
+		}
+	case FeeModelPercentage:
+		if fc.Bps > BpsDenominator {
+			return fmt.Errorf("%w: bps %d exceeds %d", ErrInvalidFeeConfig, fc.Bps, BpsDenominator)
+		}
+		if fc.Min != nil && fc.Max != nil && fc.Min.Cmp(fc.Max) > 0 {
+			return fmt.Errorf("%w: min %s exceeds max %s", ErrInvalidFeeConfig, fc.Min, fc.Max)
+		}
+		if (fc.Min != nil && fc.Min.Sign() < 0) || (fc.Max != nil && fc.Max.Sign() < 0) {
+			return fmt.Errorf("%w: min and max must be non-negative", ErrInvalidFeeConfig)
+		}
+	case FeeModelTiered:
+		if len(fc.Tiers) == 0 {
+			return fmt.Errorf("%w: no fee tiers", ErrInvalidFeeConfig)
+		}
+		sort.SliceStable(fc.Tiers, func(i, j int) bool {
+			a, b := fc.Tiers[i].UpTo, fc.Tiers[j].UpTo
+			return b == nil && a != nil || a != nil && b != nil && a.Cmp(b) < 0
+		})
+		for i, tier := range fc.Tiers {
+			if tier.UpTo == nil && i != len(fc.Tiers)-1 {
+				return fmt.Errorf("%w: only the last tier may be unbounded", ErrInvalidFeeConfig)
+			}
+			if i > 0 && tier.UpTo != nil && tier.UpTo.Cmp(fc.Tiers[i-1].UpTo) == 0 {
+				return fmt.Errorf("%w: duplicate tier bound %s", ErrInvalidFeeConfig, tier.UpTo)
+			}
+			if tier.Bps > BpsDenominator || (tier.Flat != nil && tier.Flat.Sign() < 0) {
+				return fmt.Errorf("%w: invalid tier %d", ErrInvalidFeeConfig, i)
+			}
+		}
+	case FeeModelGas:
+		if fc.Gas == nil {
+			return fmt.Errorf("%w: gas model requires a gas cost source", ErrInvalidFeeConfig)
+		}
+	default:
+		return fmt.Errorf("%w: unknown model %q", ErrInvalidFeeConfig, fc.Model)
+	}
+	return nil
+}
+
+// CalculateFee 计算金额 value 的手续费
 func (fc *FeeCalculator) CalculateFee(value *big.Int) (*big.Int, error) {
-	return big.NewInt(0), nil
+	if fc == nil || fc.Model == FeeModelNone {
+		return big.NewInt(0), nil
+	}
+	if value == nil || value.Sign() < 0 {
+		return nil, fmt.Errorf("%w: invalid amount %v", ErrInvalidMessage, value)
+	}
+
+	switch fc.Model {
+	case FeeModelFlat:
+		return new(big.Int).Set(fc.Flat), nil
+	case FeeModelPercentage:
+		fee := mulBps(value, fc.Bps)
+		if fc.Min != nil && fee.Cmp(fc.Min) < 0 {
+			fee.Set(fc.Min)
+		}
+		if fc.Max != nil && fee.Cmp(fc.Max) > 0 {
+			fee.Set(fc.Max)
+		}
+		return fee, nil
+	case FeeModelTiered:
+		for _, tier := range fc.Tiers {
+			if tier.UpTo != nil && value.Cmp(tier.UpTo) > 0 {
+				continue
+			}
+			fee := mulBps(value, tier.Bps)
+			if tier.Flat != nil {
+				fee.Add(fee, tier.Flat)
+			}
+			return fee, nil
+		}
+		return nil, fmt.Errorf("%w: amount %s exceeds the highest tier", ErrInvalidFeeConfig, value)
+	case FeeModelGas:
+		cost, err := fc.Gas.GasCost()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrGasCostUnavailable, err)
+		}
+		return mulBps(cost, BpsDenominator+fc.MarginBps), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown model %q", ErrInvalidFeeConfig, fc.Model)
+	}
+}
+
+// Apply 计算消息的手续费并记录到 Fee。扣除模式下从金额中扣除，手续费不小于金额时拒绝；
+// 预付模式下检查源端事件携带的 FeePaid。已计算过手续费的消息（如重新投递）不再重复计算
+func (fc *FeeCalculator) Apply(msg *InMsg) error {
+	if fc == nil || fc.Model == FeeModelNone || msg.Fee != "" {
+		return nil
+	}
+	value, err := msg.Value()
+	if err != nil {
+		return err
+	}
+	fee, err := fc.CalculateFee(value)
+	if err != nil {
+		return err
+	}
+
+	switch fc.Mode {
+	case FeeModeRequired:
+		paid, ok := new(big.Int).SetString(msg.FeePaid, 10)
+		if !ok {
+			paid = new(big.Int)
+		}
+		if paid.Cmp(fee) < 0 {
+			return fmt.Errorf("%w: nonce %d paid %s, required %s", ErrInsufficientFee, msg.Nonce, paid, fee)
+		}
+	default:
+		if fee.Cmp(value) >= 0 {
+			return fmt.Errorf("%w: nonce %d amount %s, fee %s", ErrFeeExceedsAmount, msg.Nonce, value, fee)
+		}
+		msg.Amount = new(big.Int).Sub(value, fee).String()
+	}
+	msg.Fee = fee.String()
+	return nil
+}
+
+// mulBps 返回 ceil(value × bps / 10000)
+func mulBps(value *big.Int, bps uint64) *big.Int {
+	n := new(big.Int).Mul(value, new(big.Int).SetUint64(bps))
+	d := big.NewInt(BpsDenominator)
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
 }
@@ -0,0 +1,156 @@
+package relay
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// maxUint256 = 2^256 - 1
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+func TestCalculateFee(t *testing.T) {
+	tiers := []FeeTier{
+		{Flat: big.NewInt(1), Bps: 5}, // 不设上限的档位排在最后
+		{UpTo: big.NewInt(1000), Flat: big.NewInt(10)},
+		{UpTo: big.NewInt(100000), Bps: 100},
+	}
+
+	tests := []struct {
+		name  string
+		fc    *FeeCalculator
+		value *big.Int
+		want  string
+		err   error
+	}{
+		{"nil calculator", nil, big.NewInt(100), "0", nil},
+		{"flat", &FeeCalculator{Model: FeeModelFlat, Flat: big.NewInt(7)}, big.NewInt(100), "7", nil},
+		{"flat zero amount", &FeeCalculator{Model: FeeModelFlat, Flat: big.NewInt(7)}, big.NewInt(0), "7", nil},
+		{"percentage exact", &FeeCalculator{Model: FeeModelPercentage, Bps: 30}, big.NewInt(10000), "30", nil},
+		{"percentage rounds up", &FeeCalculator{Model: FeeModelPercentage, Bps: 30}, big.NewInt(10001), "31", nil},
+		{"percentage of one", &FeeCalculator{Model: FeeModelPercentage, Bps: 1}, big.NewInt(1), "1", nil},
+		{"percentage of zero", &FeeCalculator{Model: FeeModelPercentage, Bps: 30}, big.NewInt(0), "0", nil},
+		{"percentage min", &FeeCalculator{Model: FeeModelPercentage, Bps: 30, Min: big.NewInt(50)}, big.NewInt(10000), "50", nil},
+		{"percentage max", &FeeCalculator{Model: FeeModelPercentage, Bps: 30, Max: big.NewInt(20)}, big.NewInt(10000), "20", nil},
+		{"percentage max uint256", &FeeCalculator{Model: FeeModelPercentage, Bps: BpsDenominator}, maxUint256, maxUint256.String(), nil},
+		{"percentage beyond uint256", &FeeCalculator{Model: FeeModelPercentage, Bps: 9999}, maxUint256,
+			"115780510028392463804028627910187039062484657667173999983053638249512338326972", nil},
+		{"tier lower bound", &FeeCalculator{Model: FeeModelTiered, Tiers: tiers}, big.NewInt(1000), "10", nil},
+		{"tier next", &FeeCalculator{Model: FeeModelTiered, Tiers: tiers}, big.NewInt(1001), "11", nil},
+		{"tier upper bound", &FeeCalculator{Model: FeeModelTiered, Tiers: tiers}, big.NewInt(100000), "1000", nil},
+		{"tier unbounded", &FeeCalculator{Model: FeeModelTiered, Tiers: tiers}, big.NewInt(100001), "52", nil},
+		{"tier out of range", &FeeCalculator{Model: FeeModelTiered, Tiers: []FeeTier{{UpTo: big.NewInt(100000)}}}, big.NewInt(100001), "", ErrInvalidFeeConfig},
+		{"gas with margin", &FeeCalculator{Model: FeeModelGas, MarginBps: 2000,
+			Gas: StaticGasCost{GasLimit: 21000, GasPrice: big.NewInt(3000000000)}}, big.NewInt(1), "75600000000000", nil},
+		{"gas rounds up", &FeeCalculator{Model: FeeModelGas, MarginBps: 1,
+			Gas: StaticGasCost{GasLimit: 1, GasPrice: big.NewInt(1)}}, big.NewInt(1), "2", nil},
+		{"gas unavailable", &FeeCalculator{Model: FeeModelGas, Gas: StaticGasCost{GasLimit: 1}}, big.NewInt(1), "", ErrGasCostUnavailable},
+		{"negative amount", &FeeCalculator{Model: FeeModelFlat, Flat: big.NewInt(1)}, big.NewInt(-1), "", ErrInvalidMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fc.Validate(); err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+			// 输入不应被修改，重复计算结果一致
+			value := new(big.Int).Set(tt.value)
+			for i := 0; i < 2; i++ {
+				fee, err := tt.fc.CalculateFee(tt.value)
+				if tt.err != nil {
+					if !errors.Is(err, tt.err) {
+						t.Fatalf("expected %v, got fee %v err %v", tt.err, fee, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("CalculateFee failed: %v", err)
+				}
+				if fee.String() != tt.want {
+					t.Fatalf("expected fee %s, got %s", tt.want, fee)
+				}
+			}
+			if tt.value.Cmp(value) != 0 {
+				t.Fatalf("amount modified: %s", tt.value)
+			}
+		})
+	}
+}
+
+func TestFeeCalculatorValidate(t *testing.T) {
+	bad := []*FeeCalculator{
+		{Model: "auction"},
+		{Model: FeeModelFlat, Mode: "later", Flat: big.NewInt(1)},
+		{Model: FeeModelFlat},
+		{Model: FeeModelFlat, Flat: big.NewInt(-1)},
+		{Model: FeeModelPercentage, Bps: BpsDenominator + 1},
+		{Model: FeeModelPercentage, Min: big.NewInt(2), Max: big.NewInt(1)},
+		{Model: FeeModelTiered},
+		{Model: FeeModelTiered, Tiers: []FeeTier{{}, {}}},
+		{Model: FeeModelTiered, Tiers: []FeeTier{{UpTo: big.NewInt(5)}, {UpTo: big.NewInt(5)}}},
+		{Model: FeeModelGas},
+	}
+	for i, fc := range bad {
+		if err := fc.Validate(); !errors.Is(err, ErrInvalidFeeConfig) {
+			t.Fatalf("case %d: expected ErrInvalidFeeConfig, got %v", i, err)
+		}
+	}
+
+	fc := &FeeCalculator{Model: FeeModelFlat, Flat: big.NewInt(0)}
+	if err := fc.Validate(); err != nil || fc.Mode != FeeModeDeduct {
+		t.Fatalf("expected default deduct mode, got %q err %v", fc.Mode, err)
+	}
+}
+
+func TestFeeCalculatorApply(t *testing.T) {
+	deduct := &FeeCalculator{Model: FeeModelPercentage, Mode: FeeModeDeduct, Bps: 100, Min: big.NewInt(10)}
+	required := &FeeCalculator{Model: FeeModelFlat, Mode: FeeModeRequired, Flat: big.NewInt(5)}
+
+	msg := InMsg{Nonce: 1, Amount: "1000"}
+	if err := deduct.Apply(&msg); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if msg.Amount != "990" || msg.Fee != "10" {
+		t.Fatalf("expected amount 990 fee 10, got %s/%s", msg.Amount, msg.Fee)
+	}
+	// 已计算手续费的消息重新投递时不再扣除
+	if err := deduct.Apply(&msg); err != nil || msg.Amount != "990" {
+		t.Fatalf("expected idempotent apply, got amount %s err %v", msg.Amount, err)
+	}
+
+	huge := InMsg{Nonce: 2, Amount: maxUint256.String()}
+	if err := deduct.Apply(&huge); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	want := new(big.Int).Sub(maxUint256, mulBps(maxUint256, 100))
+	if huge.Amount != want.String() {
+		t.Fatalf("expected amount %s, got %s", want, huge.Amount)
+	}
+
+	for _, amount := range []string{"10", "5", "0"} {
+		small := InMsg{Nonce: 3, Amount: amount}
+		if err := deduct.Apply(&small); !errors.Is(err, ErrFeeExceedsAmount) {
+			t.Fatalf("amount %s: expected ErrFeeExceedsAmount, got %v", amount, err)
+		}
+		if small.Amount != amount || small.Fee != "" {
+			t.Fatalf("rejected message modified: %+v", small)
+		}
+	}
+	if err := deduct.Apply(&InMsg{Nonce: 4, Amount: "1e18"}); !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("expected ErrInvalidMessage, got %v", err)
+	}
+
+	paid := InMsg{Nonce: 5, Amount: "3", FeePaid: "5"}
+	if err := required.Apply(&paid); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if paid.Amount != "3" || paid.Fee != "5" {
+		t.Fatalf("expected amount unchanged, got %s/%s", paid.Amount, paid.Fee)
+	}
+	for _, feePaid := range []string{"4", "", "abc"} {
+		msg := InMsg{Nonce: 6, Amount: "3", FeePaid: feePaid}
+		if err := required.Apply(&msg); !errors.Is(err, ErrInsufficientFee) {
+			t.Fatalf("fee paid %q: expected ErrInsufficientFee, got %v", feePaid, err)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// gas 价格策略错误
+var (
+	ErrInvalidGasPolicy    = errors.New("invalid gas policy")
+	ErrGasPriceUnavailable = errors.New("gas price unavailable")
+	ErrGasDeferred         = errors.New("submission deferred by gas policy")
+)
+
+func init() {
+	types.RegisterClass(ErrGasPriceUnavailable, types.ClassRetryable)
+	types.RegisterClass(ErrGasDeferred, types.ClassRetryable)
+}
+
+// gas 价格策略的默认参数
+const (
+	DefaultGasInterval = 15 * time.Second // 查询 gas 价格的间隔，也是推迟的消息重新检查的间隔
+	DefaultGasMaxDelay = 30 * time.Minute // 消息最长推迟时间
+	DefaultGasHistory  = 240              // 保留的 gas 价格记录数，按默认间隔约 1 小时
+)
+
+// GasPriceSample 是一次 gas 价格查询的结果
+type GasPriceSample struct {
+	Time  time.Time `json:"time"`
+	Price *big.Int  `json:"price"`
+}
+
+// GasStatus 是一条链的 gas 价格策略和近期价格
+type GasStatus struct {
+	Network     string           `json:"network"`
+	Price       *big.Int         `json:"price,omitempty"` // 最近一次查询到的价格
+	MaxGasPrice *big.Int         `json:"max_gas_price,omitempty"`
+	MaxFeeBps   uint64           `json:"max_fee_bps,omitempty"`
+	MaxDelay    time.Duration    `json:"max_delay"`
+	AboveCap    bool             `json:"above_cap"` // 最近一次查询到的价格高于上限
+	Deferred    int              `json:"deferred"`  // 正在推迟的消息数
+	Min         *big.Int         `json:"min,omitempty"`
+	Max         *big.Int         `json:"max,omitempty"`
+	History     []GasPriceSample `json:"history"`
+	Error       string           `json:"error,omitempty"` // 最近一次查询失败的原因
+}
+
+// GasPolicy 是一条链的 gas 价格策略：gas 价格高于 MaxGasPrice，或一次中继的 gas 成本占消息金额的比例高于 MaxFeeBps 时，
+// 推迟非紧急消息的提交，直到价格回落或推迟超过 MaxDelay。运维批准的消息和金额不低于 UrgentValue 的消息不推迟。
+// 同一条链上的跨链桥共用一个 GasPolicy，Run 定期查询价格并保留近期记录
+type GasPolicy struct {
+	Network     string
+	Prices      GasEstimator
+	MaxGasPrice *big.Int      // gas 价格上限（wei），为 nil 时不限制
+	MaxFeeBps   uint64        // gas 成本占消息金额的上限（基点），为 0 时不限制
+	UrgentValue *big.Int      // 金额不低于该值的消息不推迟，为 nil 时只有运维批准的消息不推迟
+	MaxDelay    time.Duration // 消息最长推迟时间，超过后照常提交
+	Interval    time.Duration
+	HistorySize int
+
+	logger *log.Logger
+	now    func() time.Time
+
+	mu       sync.RWMutex
+	history  []GasPriceSample
+	above    bool                 // 上次查询的价格是否高于上限，跨越上限时记录日志
+	deferred map[string]time.Time // 推迟中的消息 ID -> 首次推迟的时间
+	err      error
+}
+
+// NewGasPolicy 创建 network 上的 gas 价格策略，价格上限和成本比例至少设置一项
+func NewGasPolicy(network string, prices GasEstimator, maxGasPrice *big.Int, maxFeeBps uint64) (*GasPolicy, error) {
+	if prices == nil {
+		return nil, fmt.Errorf("%w: %s: gas price source is required", ErrInvalidGasPolicy, network)
+	}
+	if maxGasPrice != nil && maxGasPrice.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %s: max gas price must be positive", ErrInvalidGasPolicy, network)
+	}
+	if maxGasPrice == nil && maxFeeBps == 0 {
+		return nil, fmt.Errorf("%w: %s: max gas price or max fee bps is required", ErrInvalidGasPolicy, network)
+	}
+	return &GasPolicy{
+		Network:     network,
+		Prices:      prices,
+		MaxGasPrice: maxGasPrice,
+		MaxFeeBps:   maxFeeBps,
+		MaxDelay:    DefaultGasMaxDelay,
+		Interval:    DefaultGasInterval,
+		HistorySize: DefaultGasHistory,
+		logger:      log.WithComponent("gas-policy"),
+		now:         time.Now,
+		deferred:    make(map[string]time.Time),
+	}, nil
+}
+
+// retryInterval 返回推迟的消息重新检查的间隔
+func (p *GasPolicy) retryInterval() time.Duration {
+	if p == nil || p.Interval <= 0 {
+		return DefaultGasInterval
+	}
+	return p.Interval
+}
+
+// Run 每隔 Interval 查询一次 gas 价格，直到 ctx 取消
+func (p *GasPolicy) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.Sample(ctx); err != nil {
+			p.logger.Warn("failed to read gas price", map[string]any{"network": p.Network, "error": err})
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sample 查询一次 gas 价格并记入历史，价格跨越上限时记录日志
+func (p *GasPolicy) Sample(ctx context.Context) (*big.Int, error) {
+	price, err := p.Prices.SuggestGasPrice(ctx)
+	if err == nil && (price == nil || price.Sign() < 0) {
+		err = fmt.Errorf("invalid gas price %v", price)
+	}
+
+	p.mu.Lock()
+	p.err = err
+	if err != nil {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s: %w", ErrGasPriceUnavailable, p.Network, err)
+	}
+	p.history = append(p.history, GasPriceSample{Time: p.now(), Price: new(big.Int).Set(price)})
+	if size := max(p.HistorySize, 1); len(p.history) > size {
+		p.history = p.history[len(p.history)-size:]
+	}
+	above, previous := p.aboveCap(price), p.above
+	p.above = above
+	p.mu.Unlock()
+
+	switch {
+	case above && !previous:
+		p.logger.Warn("gas price above cap, deferring non-urgent messages", map[string]any{"network": p.Network, "price": price, "max": p.MaxGasPrice})
+	case !above && previous:
+		p.logger.Info("gas price back below cap", map[string]any{"network": p.Network, "price": price, "max": p.MaxGasPrice})
+	}
+	return price, nil
+}
+
+// Current 返回当前 gas 价格，最近一次查询未超过 Interval 时直接使用，否则重新查询
+func (p *GasPolicy) Current(ctx context.Context) (*big.Int, error) {
+	p.mu.RLock()
+	var price *big.Int
+	if n := len(p.history); n > 0 && p.now().Sub(p.history[n-1].Time) < p.retryInterval() {
+		price = p.history[n-1].Price
+	}
+	p.mu.RUnlock()
+	if price != nil {
+		return price, nil
+	}
+	return p.Sample(ctx)
+}
+
+// Check 检查当前是否应提交消息：价格和成本在策略范围内、消息紧急或已推迟超过 MaxDelay 时返回 nil，
+// 否则返回 ErrGasDeferred。cost 为一次中继折算成跨链代币的 gas 成本，为 nil 时不检查成本比例；
+// urgent 表示运维批准放行的消息。p 为 nil 时不检查
+func (p *GasPolicy) Check(ctx context.Context, msg InMsg, id string, cost *big.Int, urgent bool) error {
+	if p == nil {
+		return nil
+	}
+	price, err := p.Current(ctx)
+	if err != nil {
+		return err
+	}
+	value, err := msg.Value()
+	if err != nil {
+		return err
+	}
+
+	var reasons []string
+	if p.aboveCap(price) {
+		reasons = append(reasons, fmt.Sprintf("gas price %s above max %s", price, p.MaxGasPrice))
+	}
+	// cost / value > MaxFeeBps / 10000
+	if p.MaxFeeBps > 0 && cost != nil && cost.Sign() > 0 {
+		limit := new(big.Int).Mul(value, new(big.Int).SetUint64(p.MaxFeeBps))
+		if new(big.Int).Mul(cost, big.NewInt(10000)).Cmp(limit) > 0 {
+			reasons = append(reasons, fmt.Sprintf("gas cost %s exceeds %d bps of amount %s", cost, p.MaxFeeBps, value))
+		}
+	}
+
+	now := p.now()
+	p.mu.Lock()
+	since, deferred := p.deferred[id]
+	switch {
+	case len(reasons) == 0:
+		delete(p.deferred, id)
+		p.mu.Unlock()
+		return nil
+	case urgent || (p.UrgentValue != nil && value.Cmp(p.UrgentValue) >= 0):
+		delete(p.deferred, id)
+		p.mu.Unlock()
+		p.logger.Warn("submitting urgent message despite gas policy", map[string]any{"network": p.Network, "nonce": msg.Nonce, "id": id, "reason": strings.Join(reasons, ", ")})
+		return nil
+	case !deferred:
+		since = now
+		p.prune(now)
+		p.deferred[id] = now
+	case now.Sub(since) >= p.MaxDelay:
+		delete(p.deferred, id)
+		p.mu.Unlock()
+		p.logger.Warn("submitting message after max gas deferral", map[string]any{"network": p.Network, "nonce": msg.Nonce, "id": id, "delay": now.Sub(since).String(), "reason": strings.Join(reasons, ", ")})
+		return nil
+	}
+	p.mu.Unlock()
+
+	if !deferred {
+		p.logger.Info("message deferred by gas policy", map[string]any{"network": p.Network, "nonce": msg.Nonce, "id": id, "reason": strings.Join(reasons, ", ")})
+	}
+	return fmt.Errorf("%w: nonce %d on %s for %s: %s", ErrGasDeferred, msg.Nonce, p.Network, now.Sub(since).Truncate(time.Second), strings.Join(reasons, ", "))
+}
+
+// Status 返回策略参数、最近的价格和近期价格记录
+func (p *GasPolicy) Status() GasStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	status := GasStatus{
+		Network:     p.Network,
+		MaxGasPrice: p.MaxGasPrice,
+		MaxFeeBps:   p.MaxFeeBps,
+		MaxDelay:    p.MaxDelay,
+		AboveCap:    p.above,
+		Deferred:    len(p.deferred),
+		History:     make([]GasPriceSample, len(p.history)),
+	}
+	copy(status.History, p.history)
+	for _, sample := range p.history {
+		if status.Min == nil || sample.Price.Cmp(status.Min) < 0 {
+			status.Min = sample.Price
+		}
+		if status.Max == nil || sample.Price.Cmp(status.Max) > 0 {
+			status.Max = sample.Price
+		}
+	}
+	if n := len(p.history); n > 0 {
+		status.Price = p.history[n-1].Price
+	}
+	if p.err != nil {
+		status.Error = p.err.Error()
+	}
+	return status
+}
+
+// prune 丢弃推迟已远超 MaxDelay 的记录（如消息已被其他检查拒绝），调用方需持有 p.mu
+func (p *GasPolicy) prune(now time.Time) {
+	for id, since := range p.deferred {
+		if now.Sub(since) > 2*p.MaxDelay+p.retryInterval() {
+			delete(p.deferred, id)
+		}
+	}
+}
+
+func (p *GasPolicy) aboveCap(price *big.Int) bool {
+	return p.MaxGasPrice != nil && price.Cmp(p.MaxGasPrice) > 0
+}
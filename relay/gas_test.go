@@ -0,0 +1,104 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+func TestGasPolicyDefers(t *testing.T) {
+	if _, err := NewGasPolicy("bsc", &fakeEstimator{}, nil, 0); !errors.Is(err, ErrInvalidGasPolicy) {
+		t.Fatalf("Expected ErrInvalidGasPolicy without limits, got %v", err)
+	}
+	estimator := &fakeEstimator{gasPrice: big.NewInt(5)}
+	policy, err := NewGasPolicy("bsc", estimator, big.NewInt(10), 100)
+	if err != nil {
+		t.Fatalf("NewGasPolicy failed: %v", err)
+	}
+	policy.UrgentValue = big.NewInt(1_000_000)
+	policy.MaxDelay = 10 * time.Minute
+	policy.HistorySize = 3
+	now := time.Now()
+	policy.now = func() time.Time { return now }
+	ctx := context.Background()
+	msg := InMsg{Nonce: 1, Amount: "1000"}
+	tick := func(price int64) {
+		now = now.Add(policy.Interval)
+		estimator.gasPrice = big.NewInt(price)
+	}
+
+	if err := policy.Check(ctx, msg, "a", big.NewInt(10), false); err != nil {
+		t.Fatalf("Expected message within policy to pass, got %v", err)
+	}
+	// 成本超过金额的 1%
+	if err := policy.Check(ctx, msg, "a", big.NewInt(11), false); !errors.Is(err, ErrGasDeferred) {
+		t.Fatalf("Expected deferral for expensive message, got %v", err)
+	}
+
+	// 价格高于上限时推迟非紧急消息，运维批准和大额消息照常提交
+	tick(20)
+	if err := policy.Check(ctx, msg, "b", nil, false); !errors.Is(err, ErrGasDeferred) || types.Classify(err) != types.ClassRetryable {
+		t.Fatalf("Expected retryable deferral above cap, got %v", err)
+	}
+	if err := policy.Check(ctx, msg, "c", nil, true); err != nil {
+		t.Errorf("Expected approved message to pass, got %v", err)
+	}
+	if err := policy.Check(ctx, InMsg{Nonce: 2, Amount: "1000000"}, "d", nil, false); err != nil {
+		t.Errorf("Expected urgent message to pass, got %v", err)
+	}
+	if status := policy.Status(); !status.AboveCap || status.Deferred != 2 {
+		t.Errorf("Expected two deferred messages above cap, got %+v", status)
+	}
+
+	// 推迟超过最长时间后照常提交
+	now = now.Add(policy.MaxDelay)
+	estimator.gasPrice = big.NewInt(30)
+	if err := policy.Check(ctx, msg, "b", nil, false); err != nil {
+		t.Fatalf("Expected message to pass after max delay, got %v", err)
+	}
+
+	// 价格回落后放行，只保留最近的记录
+	tick(8)
+	if err := policy.Check(ctx, msg, "a", nil, false); err != nil {
+		t.Fatalf("Expected message to pass below cap, got %v", err)
+	}
+	status := policy.Status()
+	if status.AboveCap || status.Deferred != 0 || len(status.History) != 3 || status.Price.Int64() != 8 || status.Max.Int64() != 30 || status.Min.Int64() != 8 {
+		t.Errorf("Unexpected status %+v", status)
+	}
+}
+
+func TestInTunnelDefersOnGasSpike(t *testing.T) {
+	states := memStates{}
+	tunnel := NewInTunnel(&fakeSource{}, &fakeTarget{}, fakeSigner{}, &FeeCalculator{Model: FeeModelGas, Gas: StaticGasCost{GasLimit: 10, GasPrice: big.NewInt(1)}})
+	tunnel.States = NewStateMachine(states)
+	tunnel.Queue = NewQueue[InMsg](0, 16)
+	estimator := &fakeEstimator{gasPrice: big.NewInt(20)}
+	tunnel.Gas, _ = NewGasPolicy("bsc", estimator, big.NewInt(10), 0)
+	now := time.Now()
+	tunnel.Gas.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	msg := InMsg{Nonce: 1, ChainID: "56", TxHash: testTxHash, Amount: "100"}
+	if ok, err := tunnel.admit(ctx, &msg); ok || !errors.Is(err, ErrGasDeferred) {
+		t.Fatalf("Expected message to be deferred, got %v, %v", ok, err)
+	}
+	id, _ := msg.ID()
+	if rec := states[id]; rec.State == types.MsgStateQueued {
+		t.Fatalf("Expected deferred message not to be queued, got %+v", rec)
+	}
+
+	now = now.Add(tunnel.Gas.Interval)
+	estimator.gasPrice = big.NewInt(5)
+	retry := InMsg{Nonce: 1, ChainID: "56", TxHash: testTxHash, Amount: "100"}
+	if ok, err := tunnel.admit(ctx, &retry); !ok || err != nil {
+		t.Fatalf("Expected message to be admitted after price drop, got %v, %v", ok, err)
+	}
+	if retry.Fee != "10" || retry.Amount != "90" {
+		t.Errorf("Expected fee to be applied once, got %+v", retry)
+	}
+}
@@ -0,0 +1,353 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// 挂起消息操作错误
+var (
+	ErrHoldNotFound     = errors.New("held message not found")
+	ErrHoldClosed       = errors.New("held message already handled")
+	ErrHoldExpired      = errors.New("held message expired")
+	ErrAlreadyApproved  = errors.New("operator already approved")
+	ErrApprovalRequired = errors.New("manual approval required")
+)
+
+func init() {
+	// 未配置挂起队列时无法审批，交给死信由运维处理
+	types.RegisterClass(ErrApprovalRequired, types.ClassFatal)
+}
+
+// 审计日志中的挂起消息操作类型
+const (
+	AuditHoldApprove = "hold.approve"
+	AuditHoldReject  = "hold.reject"
+	AuditHoldExpire  = "hold.expire"
+)
+
+// AuditSystem 是系统自动执行的运维操作在审计日志中的操作员
+const AuditSystem = "system"
+
+// DefaultHoldExpireInterval 是 Run 检查审批超时的间隔
+const DefaultHoldExpireInterval = time.Minute
+
+// HoldQueue 管理超出限流、金额超过审批阈值或地址被筛查拦截而暂停自动中继的消息。
+// 消息收到 Approvals 名不同运维的批准后通过所属 Tunnel 重新进入中继流程，任一运维拒绝后不再中继，
+// 超过 TTL 未完成审批的消息过期。每次运维操作都写入审计日志。
+type HoldQueue struct {
+	Approvals int           // 放行所需的批准数（M-of-N 中的 M），不大于 1 时一名运维批准即放行
+	TTL       time.Duration // 审批期限，为 0 时不过期
+
+	store  types.HoldStore
+	logger *log.Logger
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	replayers map[string]Replayer // Tunnel 路径 -> 放行函数
+
+	locksMu sync.Mutex
+	locks   map[string]*holdLock // 挂起消息 ID -> 正在执行的操作
+}
+
+// holdLock 串行化对同一挂起消息的操作，refs 为等待和持有锁的操作数
+type holdLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func NewHoldQueue(store types.HoldStore) *HoldQueue {
+	return &HoldQueue{
+		store:     store,
+		logger:    log.WithComponent("hold-queue"),
+		now:       time.Now,
+		replayers: make(map[string]Replayer),
+		locks:     make(map[string]*holdLock),
+	}
+}
+
+// lock 锁定挂起消息 id，返回解锁函数。同一消息的审批、拒绝和过期依次执行，
+// 不会基于同一份待审批记录重复放行
+func (h *HoldQueue) lock(id string) func() {
+	h.locksMu.Lock()
+	l, ok := h.locks[id]
+	if !ok {
+		l = &holdLock{}
+		h.locks[id] = l
+	}
+	l.refs++
+	h.locksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		h.locksMu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(h.locks, id)
+		}
+		h.locksMu.Unlock()
+	}
+}
+
+// Register 注册 Tunnel 的放行函数，Tunnel 停止时以 nil 注销
+func (h *HoldQueue) Register(queue string, replayer Replayer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if replayer == nil {
+		delete(h.replayers, queue)
+		return
+	}
+	h.replayers[queue] = replayer
+}
+
+// Hold 挂起消息等待审批。消息已挂起时返回已有记录，不覆盖原因和审批状态
+func (h *HoldQueue) Hold(queue string, msg InMsg, reason string) (*types.HeldMessage, error) {
+	id, err := msg.ID()
+	if err != nil {
+		return nil, err
+	}
+	defer h.lock(id)()
+	held, err := h.store.GetHold(id)
+	if err != nil || held != nil {
+		return held, err
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.now()
+	held = &types.HeldMessage{
+		ID:        id,
+		Queue:     queue,
+		Nonce:     msg.Nonce,
+		Payload:   payload,
+		Reason:    reason,
+		Status:    types.HoldPending,
+		Required:  max(h.Approvals, 1),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if h.TTL > 0 {
+		held.ExpiresAt = now.Add(h.TTL)
+	}
+	if err := h.store.SaveHold(*held); err != nil {
+		return nil, err
+	}
+	h.logger.Warn("message held for approval", map[string]any{
+		"id":      id,
+		"queue":   queue,
+		"nonce":   msg.Nonce,
+		"reason":  reason,
+		"expires": held.ExpiresAt,
+	})
+	return held, nil
+}
+
+// Lookup 返回消息的挂起记录，未挂起时返回 nil
+func (h *HoldQueue) Lookup(id string) (*types.HeldMessage, error) {
+	return h.store.GetHold(id)
+}
+
+// List 返回指定状态的挂起消息，status 为空时返回全部
+func (h *HoldQueue) List(status types.HoldStatus) ([]types.HeldMessage, error) {
+	return h.store.ListHolds(status)
+}
+
+// Get 返回挂起消息
+func (h *HoldQueue) Get(id string) (*types.HeldMessage, error) {
+	held, err := h.store.GetHold(id)
+	if err != nil {
+		return nil, err
+	}
+	if held == nil {
+		return nil, ErrHoldNotFound
+	}
+	return held, nil
+}
+
+// Audit 返回挂起消息的审计日志
+func (h *HoldQueue) Audit(id string) ([]types.AuditEntry, error) {
+	return h.store.ListAudit(id)
+}
+
+// Approve 记录运维对挂起消息的批准，同一运维只能批准一次。
+// 批准数达到要求后先持久化已批准状态，再通过所属 Tunnel 将消息重新放入中继流程；
+// 放行失败时恢复待审批状态
+func (h *HoldQueue) Approve(operator, id string) error {
+	var detail string
+	return h.audited(operator, AuditHoldApprove, id, &detail, func(held *types.HeldMessage) error {
+		for _, a := range held.Approvals {
+			if a.Operator == operator {
+				return fmt.Errorf("%w: %s", ErrAlreadyApproved, operator)
+			}
+		}
+		approvals := append(held.Approvals, types.HoldApproval{Operator: operator, Time: h.now()})
+		detail = fmt.Sprintf("%d/%d", len(approvals), held.Required)
+		if len(approvals) < held.Required {
+			held.Approvals = approvals
+			return nil
+		}
+
+		h.mu.RLock()
+		release, ok := h.replayers[held.Queue]
+		h.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrNoReplayer, held.Queue)
+		}
+
+		var msg InMsg
+		if err := json.Unmarshal(held.Payload, &msg); err != nil {
+			return err
+		}
+		approved := *held
+		approved.Approvals, approved.Status, approved.UpdatedAt = approvals, types.HoldApproved, h.now()
+		if err := h.store.SaveHold(approved); err != nil {
+			return err
+		}
+		if err := release(msg); err != nil {
+			if rerr := h.store.SaveHold(*held); rerr != nil {
+				h.logger.Error("failed to restore pending hold after release failure", map[string]any{"id": id, "error": rerr})
+			}
+			return err
+		}
+		*held = approved
+		return nil
+	})
+}
+
+// Reject 拒绝挂起消息，消息不再中继
+func (h *HoldQueue) Reject(operator, id, reason string) error {
+	return h.audited(operator, AuditHoldReject, id, &reason, func(held *types.HeldMessage) error {
+		held.Status = types.HoldRejected
+		return nil
+	})
+}
+
+// Expire 将超过审批期限的待审批消息记为过期，返回过期的消息数
+func (h *HoldQueue) Expire() (int, error) {
+	pending, err := h.store.ListHolds(types.HoldPending)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for _, held := range pending {
+		if !h.expired(held) {
+			continue
+		}
+		ok, err := h.expire(held.ID)
+		if err != nil {
+			return expired, err
+		}
+		if ok {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// expire 在锁定后重新读取挂起消息，仍待审批且已超过期限时记为过期
+func (h *HoldQueue) expire(id string) (bool, error) {
+	defer h.lock(id)()
+	held, err := h.store.GetHold(id)
+	if err != nil || held == nil || held.Status != types.HoldPending || !h.expired(*held) {
+		return false, err
+	}
+	held.Status, held.UpdatedAt = types.HoldExpired, h.now()
+	err = h.store.SaveHold(*held)
+	if auditErr := h.audit(AuditSystem, AuditHoldExpire, held.ID, held.ExpiresAt.Format(time.RFC3339), err); err == nil {
+		err = auditErr
+	}
+	return err == nil, err
+}
+
+// Run 定期将超过审批期限的消息记为过期，直到 ctx 取消
+func (h *HoldQueue) Run(ctx context.Context, interval time.Duration) {
+	if h.TTL <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := h.Expire(); err != nil {
+				h.logger.Error("failed to expire held messages", map[string]any{"error": err})
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *HoldQueue) expired(held types.HeldMessage) bool {
+	return !held.ExpiresAt.IsZero() && !h.now().Before(held.ExpiresAt)
+}
+
+// audited 对待审批的挂起消息执行运维操作并写入审计日志，操作失败同样记录。
+// detail 在操作执行后读取，操作可借此记录批准进度
+func (h *HoldQueue) audited(operator, action, id string, detail *string, op func(held *types.HeldMessage) error) error {
+	err := h.apply(id, op)
+	if auditErr := h.audit(operator, action, id, *detail, err); err == nil {
+		err = auditErr
+	}
+	return err
+}
+
+// audit 写入一条审计日志，result 为操作的结果
+func (h *HoldQueue) audit(operator, action, id, detail string, result error) error {
+	entry := types.AuditEntry{
+		Time:     h.now(),
+		Operator: operator,
+		Action:   action,
+		Target:   id,
+		Detail:   detail,
+		Result:   "ok",
+	}
+	if result != nil {
+		entry.Result = result.Error()
+	}
+	err := h.store.AppendAudit(entry)
+	if err != nil {
+		h.logger.Error("failed to write audit log", map[string]any{"entry": entry, "error": err})
+	}
+
+	h.logger.Info("held message operation", map[string]any{
+		"operator": operator,
+		"action":   action,
+		"id":       id,
+		"result":   entry.Result,
+	})
+	return err
+}
+
+// apply 锁定挂起消息，对待审批的记录执行 op 并保存
+func (h *HoldQueue) apply(id string, op func(held *types.HeldMessage) error) error {
+	defer h.lock(id)()
+	held, err := h.Get(id)
+	if err != nil {
+		return err
+	}
+	if held.Status != types.HoldPending {
+		return fmt.Errorf("%w: %s is %s", ErrHoldClosed, id, held.Status)
+	}
+	if h.expired(*held) {
+		held.Status, held.UpdatedAt = types.HoldExpired, h.now()
+		if err := h.store.SaveHold(*held); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %s at %s", ErrHoldExpired, id, held.ExpiresAt.Format(time.RFC3339))
+	}
+	if err := op(held); err != nil {
+		return err
+	}
+	held.UpdatedAt = h.now()
+	return h.store.SaveHold(*held)
+}
@@ -0,0 +1,337 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// memHolds 是测试用的内存挂起消息存储，可并发使用
+type memHolds struct {
+	*memDeadLetters
+	mu    sync.Mutex
+	holds map[string]types.HeldMessage
+}
+
+func newMemHolds() *memHolds {
+	return &memHolds{memDeadLetters: newMemDeadLetters(), holds: make(map[string]types.HeldMessage)}
+}
+
+func (s *memHolds) SaveHold(h types.HeldMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holds[h.ID] = h
+	return nil
+}
+
+func (s *memHolds) GetHold(id string) (*types.HeldMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.holds[id]
+	if !ok {
+		return nil, nil
+	}
+	return &h, nil
+}
+
+func (s *memHolds) AppendAudit(entry types.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.memDeadLetters.AppendAudit(entry)
+}
+
+func (s *memHolds) ListAudit(target string) ([]types.AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.memDeadLetters.ListAudit(target)
+}
+
+func (s *memHolds) ListHolds(status types.HoldStatus) ([]types.HeldMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []types.HeldMessage
+	for _, h := range s.holds {
+		if status == "" || h.Status == status {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+func TestInTunnelHoldsRateLimitedMessages(t *testing.T) {
+	states := memStates{}
+	store := newMemHolds()
+	tunnel := NewInTunnel(&fakeSource{}, &fakeTarget{}, fakeSigner{}, nil)
+	tunnel.States = NewStateMachine(states)
+	tunnel.ErrorHandler = nil
+	tunnel.Queue = NewQueue[InMsg](0, 16)
+	tunnel.Holds = NewHoldQueue(store)
+	tunnel.Holds.Register(tunnel.Path, tunnel.release)
+	tunnel.Limits, _ = NewRateLimiter(tunnel.Path, []RateLimit{{Scope: LimitBridge, Window: time.Hour, MaxCount: 1}}, nil)
+
+	msg := func(logIndex uint) InMsg {
+		return InMsg{Nonce: uint64(logIndex), ChainID: "56", TxHash: testTxHash, LogIndex: logIndex, Amount: "100"}
+	}
+	id := func(m InMsg) string {
+		id, _ := m.ID()
+		return id
+	}
+	ctx := context.Background()
+
+	first, second, third := msg(1), msg(2), msg(3)
+	if ok, err := tunnel.admit(ctx, &first); !ok || err != nil {
+		t.Fatalf("Expected first message to be admitted, got %v, %v", ok, err)
+	}
+
+	// 超出限流的消息挂起等待审批
+	if ok, err := tunnel.admit(ctx, &second); ok || err != nil {
+		t.Fatalf("Expected second message to be held, got %v, %v", ok, err)
+	}
+	if states[id(second)].State != types.MsgStateHeld {
+		t.Errorf("Expected held state, got %+v", states[id(second)])
+	}
+	held, err := tunnel.Holds.Get(id(second))
+	if err != nil || held.Status != types.HoldPending || held.Nonce != 2 {
+		t.Fatalf("Unexpected hold %+v, %v", held, err)
+	}
+	// 重新同步到的挂起消息不会绕过审批
+	if ok, _ := tunnel.admit(ctx, &second); ok {
+		t.Error("Expected pending held message to be skipped")
+	}
+
+	// 批准后消息重新进入中继流程，不受限流
+	if err := tunnel.Holds.Approve("alice", held.ID); err != nil {
+		t.Fatalf("Failed to approve: %v", err)
+	}
+	replayed := <-tunnel.replays
+	if ok, err := tunnel.admit(ctx, &replayed); !ok || err != nil {
+		t.Fatalf("Expected approved message to be admitted, got %v, %v", ok, err)
+	}
+	if states[id(second)].State != types.MsgStateQueued {
+		t.Errorf("Expected queued state, got %+v", states[id(second)])
+	}
+
+	// 拒绝的消息不再中继
+	tunnel.admit(ctx, &third)
+	if err := tunnel.Holds.Reject("bob", id(third), "suspicious"); err != nil {
+		t.Fatalf("Failed to reject: %v", err)
+	}
+	if ok, _ := tunnel.admit(ctx, &third); ok {
+		t.Error("Expected rejected message to be skipped")
+	}
+	if err := tunnel.Holds.Approve("alice", id(third)); !errors.Is(err, ErrHoldClosed) {
+		t.Errorf("Expected ErrHoldClosed, got %v", err)
+	}
+
+	audit, _ := tunnel.Holds.Audit(id(third))
+	if len(audit) != 2 || audit[0].Action != AuditHoldReject || audit[0].Detail != "suspicious" || audit[1].Result == "ok" {
+		t.Errorf("Unexpected audit %+v", audit)
+	}
+	if pending, _ := tunnel.Holds.List(types.HoldPending); len(pending) != 0 {
+		t.Errorf("Expected no pending holds, got %+v", pending)
+	}
+}
+
+func TestHoldQueueRequiresDistinctApprovals(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	holds := NewHoldQueue(newMemHolds())
+	holds.Approvals, holds.TTL = 2, time.Hour
+	holds.now = func() time.Time { return now }
+
+	var released []InMsg
+	holds.Register("InTunnel", func(m InMsg) error {
+		released = append(released, m)
+		return nil
+	})
+
+	msg := InMsg{Nonce: 7, ChainID: "56", TxHash: testTxHash, Amount: "100"}
+	held, err := holds.Hold("InTunnel", msg, "large transfer")
+	if err != nil || held.Required != 2 || !held.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Fatalf("Unexpected hold %+v, %v", held, err)
+	}
+
+	if err := holds.Approve("alice", held.ID); err != nil {
+		t.Fatalf("Failed to approve: %v", err)
+	}
+	// 同一运维不能重复批准
+	if err := holds.Approve("alice", held.ID); !errors.Is(err, ErrAlreadyApproved) {
+		t.Fatalf("Expected ErrAlreadyApproved, got %v", err)
+	}
+	if got, _ := holds.Get(held.ID); got.Status != types.HoldPending || len(got.Approvals) != 1 || len(released) != 0 {
+		t.Fatalf("Expected pending hold with 1 approval, got %+v", got)
+	}
+	if err := holds.Approve("bob", held.ID); err != nil {
+		t.Fatalf("Failed to approve: %v", err)
+	}
+	got, _ := holds.Get(held.ID)
+	if got.Status != types.HoldApproved || len(got.Approvals) != 2 || len(released) != 1 || released[0].Nonce != 7 {
+		t.Fatalf("Expected approved and released hold, got %+v, released %+v", got, released)
+	}
+
+	audit, _ := holds.Audit(held.ID)
+	if len(audit) != 3 || audit[0].Detail != "1/2" || audit[1].Result == "ok" || audit[2].Detail != "2/2" {
+		t.Errorf("Unexpected audit %+v", audit)
+	}
+}
+
+func TestHoldQueueReleasesOnce(t *testing.T) {
+	store := newMemHolds()
+	holds := NewHoldQueue(store)
+	held, _ := holds.Hold("InTunnel", InMsg{Nonce: 7, ChainID: "56", TxHash: testTxHash, Amount: "100"}, "large transfer")
+
+	// 放行前已持久化批准状态
+	var released atomic.Int32
+	holds.Register("InTunnel", func(m InMsg) error {
+		if got, _ := store.GetHold(held.ID); got.Status != types.HoldApproved {
+			t.Errorf("Expected approved state to be saved before release, got %s", got.Status)
+		}
+		released.Add(1)
+		return nil
+	})
+
+	// 多名运维同时批准只放行一次
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(op string) {
+			defer wg.Done()
+			if err := holds.Approve(op, held.ID); err == nil {
+				succeeded.Add(1)
+			} else if !errors.Is(err, ErrHoldClosed) {
+				t.Errorf("Expected ErrHoldClosed, got %v", err)
+			}
+		}(fmt.Sprintf("op%d", i))
+	}
+	wg.Wait()
+	if released.Load() != 1 || succeeded.Load() != 1 {
+		t.Fatalf("Expected one release, got %d releases and %d approvals", released.Load(), succeeded.Load())
+	}
+}
+
+func TestHoldQueueRestoresPendingOnReleaseFailure(t *testing.T) {
+	holds := NewHoldQueue(newMemHolds())
+	held, _ := holds.Hold("InTunnel", InMsg{Nonce: 7, ChainID: "56", TxHash: testTxHash, Amount: "100"}, "large transfer")
+
+	fail := true
+	holds.Register("InTunnel", func(m InMsg) error {
+		if fail {
+			return ErrReplayBusy
+		}
+		return nil
+	})
+	if err := holds.Approve("alice", held.ID); !errors.Is(err, ErrReplayBusy) {
+		t.Fatalf("Expected ErrReplayBusy, got %v", err)
+	}
+	if got, _ := holds.Get(held.ID); got.Status != types.HoldPending || len(got.Approvals) != 0 {
+		t.Fatalf("Expected pending hold without approvals after failed release, got %+v", got)
+	}
+
+	// 同一运维可以重新批准
+	fail = false
+	if err := holds.Approve("alice", held.ID); err != nil {
+		t.Fatalf("Failed to approve: %v", err)
+	}
+	if got, _ := holds.Get(held.ID); got.Status != types.HoldApproved {
+		t.Errorf("Expected approved status, got %s", got.Status)
+	}
+}
+
+func TestHoldQueueExpiresPendingHolds(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	holds := NewHoldQueue(newMemHolds())
+	holds.TTL = time.Hour
+	holds.now = func() time.Time { return now }
+
+	first, _ := holds.Hold("InTunnel", InMsg{Nonce: 1, ChainID: "56", TxHash: testTxHash, LogIndex: 1}, "large transfer")
+	now = now.Add(30 * time.Minute)
+	second, _ := holds.Hold("InTunnel", InMsg{Nonce: 2, ChainID: "56", TxHash: testTxHash, LogIndex: 2}, "large transfer")
+
+	// 过期的消息不能再批准
+	now = now.Add(45 * time.Minute)
+	if err := holds.Approve("alice", first.ID); !errors.Is(err, ErrHoldExpired) {
+		t.Fatalf("Expected ErrHoldExpired, got %v", err)
+	}
+	if got, _ := holds.Get(first.ID); got.Status != types.HoldExpired {
+		t.Errorf("Expected expired status, got %s", got.Status)
+	}
+
+	now = now.Add(time.Hour)
+	if n, err := holds.Expire(); n != 1 || err != nil {
+		t.Fatalf("Expected 1 expired hold, got %d, %v", n, err)
+	}
+	if got, _ := holds.Get(second.ID); got.Status != types.HoldExpired {
+		t.Errorf("Expected expired status, got %s", got.Status)
+	}
+	audit, _ := holds.Audit(second.ID)
+	if len(audit) != 1 || audit[0].Operator != AuditSystem || audit[0].Action != AuditHoldExpire || audit[0].Result != "ok" {
+		t.Errorf("Unexpected audit %+v", audit)
+	}
+}
+
+func TestInTunnelHoldsLargeTransfers(t *testing.T) {
+	states := memStates{}
+	tunnel := NewInTunnel(&fakeSource{}, &fakeTarget{}, fakeSigner{}, nil)
+	tunnel.States = NewStateMachine(states)
+	tunnel.ErrorHandler = nil
+	tunnel.Queue = NewQueue[InMsg](0, 16)
+	tunnel.Tokens, _ = NewTokenRegistry([]Token{{
+		Symbol:            "USDT",
+		Source:            "0xa",
+		Target:            "0xb",
+		SourceDecimals:    18,
+		TargetDecimals:    6,
+		ApprovalThreshold: bigString("1000000000000000000000"),
+	}})
+
+	msg := func(logIndex uint, amount string) InMsg {
+		return InMsg{Nonce: uint64(logIndex), ChainID: "56", TxHash: testTxHash, LogIndex: logIndex, Token: "0xa", Amount: amount}
+	}
+	ctx := context.Background()
+
+	// 等于阈值的消息自动放行
+	small := msg(1, "1000000000000000000000")
+	if ok, err := tunnel.admit(ctx, &small); !ok || err != nil || small.Amount != "1000000000" {
+		t.Fatalf("Expected message at threshold to be admitted, got %v, %v, %+v", ok, err, small)
+	}
+
+	// 未配置挂起队列时超过阈值的消息进入死信
+	large := msg(2, "1000000000000000000001")
+	if ok, err := tunnel.admit(ctx, &large); ok || err != nil {
+		t.Fatalf("Expected large message to be rejected, got %v, %v", ok, err)
+	}
+	if id, _ := large.ID(); states[id].State != types.MsgStateFailed {
+		t.Errorf("Expected failed state, got %+v", states[id])
+	}
+
+	tunnel.Holds = NewHoldQueue(newMemHolds())
+	tunnel.Holds.Register(tunnel.Path, tunnel.release)
+	large = msg(3, "2000000000000000000000")
+	if ok, err := tunnel.admit(ctx, &large); ok || err != nil {
+		t.Fatalf("Expected large message to be held, got %v, %v", ok, err)
+	}
+	id, _ := large.ID()
+	held, err := tunnel.Holds.Get(id)
+	if err != nil || !strings.HasPrefix(held.Reason, ErrApprovalRequired.Error()) {
+		t.Fatalf("Unexpected hold %+v, %v", held, err)
+	}
+	// 挂起的是未换算的原始消息
+	if large.TargetToken != "" {
+		t.Errorf("Expected held message to stay unconverted, got %+v", large)
+	}
+
+	if err := tunnel.Holds.Approve("alice", id); err != nil {
+		t.Fatalf("Failed to approve: %v", err)
+	}
+	replayed := <-tunnel.replays
+	if ok, err := tunnel.admit(ctx, &replayed); !ok || err != nil || replayed.Amount != "2000000000" {
+		t.Fatalf("Expected approved message to be admitted and converted, got %v, %v, %+v", ok, err, replayed)
+	}
+}
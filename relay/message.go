@@ -1,5 +1,17 @@
 package relay
 
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
 // Message 通用消息接口
 type Message interface {
 	GetNonce() uint64
@@ -7,17 +19,76 @@ type Message interface {
 
 // InMsg 代表从源端接收到的跨入消息
 type InMsg struct {
-	Nonce    uint64 `json:"nonce"`
-	TxHash   string `json:"hash"`
-	Sender   string `json:"sender"`
-	Receiver string `json:"receiver"`
-	Amount   string `json:"amount"`
+	Nonce       uint64 `json:"nonce"`
+	ChainID     string `json:"chain_id"`  // 源链 ID
+	Height      uint64 `json:"height"`    // 源端事件所在区块
+	TxHash      string `json:"hash"`      // 源端交易哈希
+	LogIndex    uint   `json:"log_index"` // 源端事件在区块中的日志序号
+	Sender      string `json:"sender"`
+	Receiver    string `json:"receiver"`
+	Token       string `json:"token,omitempty"`        // 源端代币地址
+	TargetToken string `json:"target_token,omitempty"` // 目标端代币地址，由代币登记表换算金额时设置
+	Amount      string `json:"amount"`                 // 经代币登记表换算后为目标端最小单位，扣除模式下为扣除手续费后的金额
+	FeePaid     string `json:"fee_paid,omitempty"`     // 源端事件携带的手续费，用于预付模式
+	Fee         string `json:"fee,omitempty"`          // FeeCalculator 计算的手续费，计算后不再重复计算
+
+	SourceAmount  string `json:"source_amount,omitempty"`   // 换算前的源端金额，用于重新核对源端事件
+	SourceFeePaid string `json:"source_fee_paid,omitempty"` // 换算前的源端手续费
+
+	FeePolicy *types.FeePolicy `json:"fee_policy,omitempty"` // 运维重新投递时指定的手续费策略
+	Observer  string           `json:"observer,omitempty"`   // 观察到消息的源端节点，签名前在其他节点上核对
 }
 
 func (m InMsg) GetNonce() uint64 {
 	return m.Nonce
 }
 
+func (m InMsg) GetHeight() uint64 {
+	return m.Height
+}
+
+// ID 返回消息的确定性 ID，见 MessageID
+func (m InMsg) ID() (string, error) {
+	return MessageID(m.ChainID, m.TxHash, m.LogIndex)
+}
+
+// Value 将十进制金额解析为 big.Int
+func (m InMsg) Value() (*big.Int, error) {
+	v, ok := new(big.Int).SetString(m.Amount, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, fmt.Errorf("%w: invalid amount %q of nonce %d", ErrInvalidMessage, m.Amount, m.Nonce)
+	}
+	return v, nil
+}
+
+// MessageID 由源链 ID、交易哈希和日志序号计算跨链消息的确定性 ID：
+// keccak256(uint256(chainID) ‖ bytes32(txHash) ‖ uint256(logIndex))，与合约 isProcessed 使用的 ID 一致。
+// 同一笔源端事件无论被订阅、回补还是人工重放多少次，ID 都相同
+func MessageID(chainID, txHash string, logIndex uint) (string, error) {
+	id, ok := math.ParseBig256(chainID)
+	if !ok || id.Sign() <= 0 {
+		return "", fmt.Errorf("%w: invalid chain id %q", ErrInvalidMessage, chainID)
+	}
+	hash, err := parseTxHash(txHash)
+	if err != nil {
+		return "", err
+	}
+
+	data := make([]byte, 0, 96)
+	data = append(data, math.U256Bytes(id)...)
+	data = append(data, hash.Bytes()...)
+	data = append(data, math.U256Bytes(new(big.Int).SetUint64(uint64(logIndex)))...)
+	return crypto.Keccak256Hash(data).Hex(), nil
+}
+
+func parseTxHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("%w: invalid tx hash %q", ErrInvalidMessage, s)
+	}
+	return common.BytesToHash(b), nil
+}
+
 // OutMsg 代表从目标端发送的跨出消息
 type OutMsg struct {
 	Nonce    uint64 `json:"nonce"`
@@ -25,18 +96,53 @@ type OutMsg struct {
 	Sender   string `json:"sender"`
 	Receiver string `json:"receiver"`
 	Amount   string `json:"amount"`
+	TxNonce  uint64 `json:"tx_nonce,omitempty"` // OutTunnel 分配的源端交易 nonce，源端必须使用该 nonce 提交
 }
 
 func (m OutMsg) GetNonce() uint64 {
 	return m.Nonce
 }
 
-// BatchMsg 代表从目标端发送的预签名批量消息
+// BatchMsg 代表一批 nonce 连续的跨入消息，通过批量合约方法一次提交。
+// 从目标端订阅时 Data 为预签名的批量数据
 type BatchMsg struct {
-	Nonces uint64 `json:"nonces"`
-	Data   []byte `json:"data"`
+	FromNonce uint64  `json:"from_nonce"`
+	ToNonce   uint64  `json:"to_nonce"`
+	Msgs      []InMsg `json:"msgs"`
+	Total     string  `json:"total"` // 批次内金额总和
+	Data      []byte  `json:"data"`
+
+	FeePolicy *types.FeePolicy `json:"fee_policy,omitempty"` // 运维重新投递时指定的手续费策略，提交时覆盖节点建议的 gas 价格和上限
+}
+
+// NewBatchMsg 由 nonce 连续的消息创建批次
+func NewBatchMsg(msgs []InMsg) BatchMsg {
+	total := new(big.Int)
+	for _, msg := range msgs {
+		if v, err := msg.Value(); err == nil {
+			total.Add(total, v)
+		}
+	}
+	batch := BatchMsg{Msgs: msgs, Total: total.String()}
+	for _, msg := range msgs {
+		if msg.FeePolicy != nil {
+			batch.FeePolicy = msg.FeePolicy
+			break
+		}
+	}
+	if len(msgs) > 0 {
+		batch.FromNonce = msgs[0].Nonce
+		batch.ToNonce = msgs[len(msgs)-1].Nonce
+	}
+	return batch
 }
 
 func (m BatchMsg) GetNonce() uint64 {
-	return m.Nonces
+	return m.FromNonce
+}
+
+// Split 将批次从中间拆分为两个批次
+func (m BatchMsg) Split() (BatchMsg, BatchMsg) {
+	mid := len(m.Msgs) / 2
+	return NewBatchMsg(m.Msgs[:mid]), NewBatchMsg(m.Msgs[mid:])
 }
@@ -0,0 +1,47 @@
+package relay
+
+import (
+	"errors"
+	"testing"
+)
+
+const testTxHash = "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060"
+
+func TestMessageIDIsDeterministic(t *testing.T) {
+	msg := InMsg{ChainID: "56", TxHash: testTxHash, LogIndex: 3}
+
+	id1, err := msg.ID()
+	if err != nil {
+		t.Fatalf("Failed to compute id: %v", err)
+	}
+	// 回补或重放得到的同一事件 nonce、高度可能不同，ID 不变
+	replayed := msg
+	replayed.Nonce, replayed.Height = 9, 100
+	if id2, _ := replayed.ID(); id2 != id1 {
+		t.Errorf("Expected same id for replayed event, got %s and %s", id1, id2)
+	}
+
+	other := msg
+	other.LogIndex = 4
+	if id3, _ := other.ID(); id3 == id1 {
+		t.Error("Expected different id for different log index")
+	}
+	other = msg
+	other.ChainID = "1"
+	if id4, _ := other.ID(); id4 == id1 {
+		t.Error("Expected different id for different chain")
+	}
+}
+
+func TestMessageIDRejectsInvalidInput(t *testing.T) {
+	for _, msg := range []InMsg{
+		{ChainID: "", TxHash: testTxHash},
+		{ChainID: "abc", TxHash: testTxHash},
+		{ChainID: "56", TxHash: "0x1234"},
+		{ChainID: "56", TxHash: ""},
+	} {
+		if _, err := msg.ID(); !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("Expected ErrInvalidMessage for %+v, got %v", msg, err)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/signer"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// DefaultGapSkipTimeout 是跨出队列的缺口无法回补时，跳过缺口前的等待时间
+const DefaultGapSkipTimeout = 10 * time.Minute
+
+// ErrNonceGapSkipped 表示跨出队列跳过了无法回补的 nonce 缺口，缺口内的提现需要人工处理
+var ErrNonceGapSkipped = errors.New("nonce gap skipped")
+
+func init() {
+	types.RegisterClass(ErrNonceGapSkipped, types.ClassFatal)
+}
+
+// inflightOut 记录已交给源端处理、等待确认的跨出消息
+type inflightOut struct {
+	msg     OutMsg
+	txNonce uint64 // TxRecorder 分配的交易 nonce
+}
+
+// OutTunnel 处理跨链出向消息的通道，与 InTunnel 方向相反：
+//   - watcher：订阅目标端的跨出（提现）消息，按 nonce 排序后放入队列
+//   - processor：为消息分配源端交易 nonce，交给源端释放资产
+//   - confirmer：轮询源端已处理的序号，通过目标端 ConfirmOutMsgs 确认并确认队列中的消息
+//
+// 序号检查点、队列和 nonce 管理都独立于 InTunnel。
+type OutTunnel struct {
+	lifecycle
+
+	Path     string
+	Source   InEndpoint  // 源端，执行跨出消息
+	Target   OutEndpoint // 目标端，产生跨出消息
+	Sequence Sequence    // 源端已处理的跨出消息序号
+
+	Key        signer.Signer
+	Nonce      uint64 // 源端账户当前使用的nonce
+	TxRecorder *TxRecorder
+
+	Msgs            chan OutMsg        // 跨出消息通道（从目标端订阅）
+	Queue           *Queue[OutMsg]     // 按 nonce 排序后的跨出消息队列
+	Store           types.MessageStore // 队列持久化存储，为 nil 时使用内存队列
+	Controls        *Controls          // 跨链桥的暂停开关，为 nil 时不可暂停
+	Funds           *BalanceMonitor    // 源端提交账户的余额监控，余额不足时暂停提交，为 nil 时不检查
+	ErrorHandler    types.ErrorHandler // 错误处理器
+	Backoff         Backoff            // 初始化和子任务重启的退避参数
+	ConfirmInterval time.Duration      // 轮询源端已处理序号的间隔
+	GapSkipTimeout  time.Duration      // 缺口持续无法回补多久后跳过，跳过的缺口上报错误处理器
+	logger          *log.Logger
+
+	mu          sync.RWMutex // 保护 Sequence、Nonce、inflight 和缺口状态
+	inflight    map[uint64]inflightOut
+	subscribed  bool      // 目标端订阅只建立一次
+	gapFrom     uint64    // 当前缺口的起始 nonce
+	gapSince    time.Time // 当前缺口首次出现的时间
+	skippedGaps uint64    // 已跳过的缺口数
+}
+
+func NewOutTunnel(source InEndpoint, target OutEndpoint, key signer.Signer) *OutTunnel {
+	logger := log.WithComponent("out-tunnel")
+	return &OutTunnel{
+		lifecycle:       lifecycle{name: "OutTunnel", logger: logger},
+		Path:            "OutTunnel", // TODO: target.Name() + "->" + source.Name(),
+		Source:          source,
+		Target:          target,
+		Key:             key,
+		Msgs:            make(chan OutMsg, 1024),
+		ErrorHandler:    NewErrorHandler(3, time.Second*10),
+		Backoff:         DefaultBackoff(),
+		ConfirmInterval: DefaultConfirmInterval,
+		GapSkipTimeout:  DefaultGapSkipTimeout,
+		logger:          logger,
+		inflight:        make(map[uint64]inflightOut),
+	}
+}
+
+// Init 同步源端已处理的序号和账户 nonce，并创建队列
+func (t *OutTunnel) Init() error {
+	seq, height := t.Source.GetSequence()
+	nonce := t.Source.GetNonce(t.Key.Address())
+	t.mu.Lock()
+	t.Sequence.ID = seq
+	t.Sequence.Height = height
+	t.Nonce = nonce
+	t.inflight = make(map[uint64]inflightOut)
+	t.mu.Unlock()
+	t.TxRecorder = NewTxRecorder(nonce)
+
+	if t.Store != nil {
+		queue, err := NewDurableQueue[OutMsg](t.Path, t.Store, cap(t.Msgs))
+		if err != nil {
+			return err
+		}
+		t.Queue = queue
+	} else {
+		t.Queue = NewQueue[OutMsg](seq, cap(t.Msgs))
+	}
+	t.Queue.SetGapHandler(DefaultGapTimeout, t.backfill)
+	return nil
+}
+
+// backfill 从支持按 nonce 查询的目标端重新获取缺口内的跨出消息。缺口持续超过 GapSkipTimeout
+// 仍无法补齐时跳过缺口，避免之后的提现永久停滞，并将跳过的区间上报错误处理器
+func (t *OutTunnel) backfill(gap Gap) {
+	if filter, ok := t.Target.(OutFilter); ok {
+		msgs, err := filter.FilterOutMsgs(gap.FromNonce, gap.ToNonce)
+		if err != nil {
+			t.logger.Error("failed to backfill nonce gap", map[string]any{
+				"from_nonce": gap.FromNonce,
+				"to_nonce":   gap.ToNonce,
+				"error":      err,
+			})
+		}
+		for _, msg := range msgs {
+			t.Queue.Push(msg)
+		}
+		if len(msgs) > 0 {
+			return
+		}
+	}
+
+	t.mu.Lock()
+	if t.gapSince.IsZero() || t.gapFrom != gap.FromNonce {
+		t.gapFrom, t.gapSince = gap.FromNonce, gap.Since
+	}
+	since := t.gapSince
+	t.mu.Unlock()
+	if time.Since(since) < t.GapSkipTimeout || !t.Queue.SkipGap(gap) {
+		return
+	}
+
+	t.mu.Lock()
+	t.skippedGaps++
+	t.gapSince = time.Time{}
+	t.mu.Unlock()
+	err := fmt.Errorf("%w: nonces %d-%d unrecoverable since %s", ErrNonceGapSkipped, gap.FromNonce, gap.ToNonce, since.Format(time.RFC3339))
+	t.HandleError(context.Background(), err, map[string]any{
+		"operation":  "Backfill",
+		"from_nonce": gap.FromNonce,
+		"to_nonce":   gap.ToNonce,
+	})
+}
+
+// Run 初始化并运行 Tunnel，直到 ctx 取消。初始化失败时按退避重试
+func (t *OutTunnel) Run(ctx context.Context) error {
+	t.setState(StateStarting)
+	defer t.setState(StateStopped)
+
+	if err := retryInit(ctx, t.Backoff, t.logger, t.Path, t.Init); err != nil {
+		return err
+	}
+
+	sup := t.supervise(ctx, t.Backoff)
+	sup.Go(ctx, "watcher", t.watch)
+	sup.Go(ctx, "processor", t.process)
+	sup.Go(ctx, "confirmer", t.confirm)
+
+	t.logger.Info("tunnel started", map[string]any{"path": t.Path, "sequence": t.Sequence.ID})
+	<-ctx.Done()
+	sup.Wait()
+	t.logger.Info("tunnel stopped", map[string]any{"path": t.Path, "sequence": t.Sequence.ID})
+	return nil
+}
+
+// watch 订阅目标端跨出消息并按 nonce 排序后放入队列
+func (t *OutTunnel) watch(ctx context.Context) error {
+	if !t.subscribed {
+		if err := t.Target.SubscribeToOutMsgs(t.Msgs); err != nil {
+			return t.HandleError(ctx, err, map[string]any{"operation": "SubscribeToOutMsgs"})
+		}
+		t.subscribed = true
+	}
+
+	intake := t.intake(t.Controls.inbound())
+	ticker := time.NewTicker(DefaultGapInterval)
+	defer ticker.Stop()
+	for {
+		msgs := t.Msgs
+		if !intake.Open() {
+			msgs = nil
+		}
+		select {
+		case msg := <-msgs:
+			t.Queue.Push(msg)
+		case <-intake.drained:
+			intake.drain()
+		case <-intake.changed:
+			intake.refresh()
+		case <-ticker.C:
+			t.Queue.CheckGaps()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// process 为队列中的消息分配源端交易 nonce 并交给源端处理，排空、暂停提交或账户余额不足时不再提交新消息
+func (t *OutTunnel) process(ctx context.Context) error {
+	out := make(chan OutMsg)
+	defer close(out)
+
+	if err := t.Source.ProcessOutMsgs(out); err != nil {
+		return t.HandleError(ctx, err, map[string]any{"operation": "ProcessOutMsgs"})
+	}
+
+	intake := t.intake(t.Controls.outbound()).withFunds(t.Funds.funds())
+	for {
+		msgs := t.Queue.Msgs
+		if !intake.Open() {
+			msgs = nil
+		}
+		select {
+		case <-intake.drained:
+			intake.drain()
+		case <-intake.changed:
+			intake.refresh()
+		case <-intake.funded:
+			intake.refreshFunds()
+		case msg := <-msgs:
+			// 源端使用分配的交易 nonce 提交，确认后释放
+			msg.TxNonce = t.TxRecorder.AllocateNonce(&msg)
+			t.mu.Lock()
+			t.inflight[msg.Nonce] = inflightOut{msg: msg, txNonce: msg.TxNonce}
+			t.mu.Unlock()
+
+			select {
+			case out <- msg:
+			case <-ctx.Done():
+				return nil
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// confirm 轮询源端已处理的序号，将已完成的消息在目标端确认后确认队列
+func (t *OutTunnel) confirm(ctx context.Context) error {
+	ticker := time.NewTicker(t.ConfirmInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := t.confirmProcessed(); err != nil {
+				return t.HandleError(ctx, err, map[string]any{"operation": "ConfirmOutMsgs"})
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// confirmProcessed 确认源端序号之前的所有在途消息，失败时保留在途记录等待下次重试
+func (t *OutTunnel) confirmProcessed() error {
+	seq, height := t.Source.GetSequence()
+
+	t.mu.RLock()
+	var done []inflightOut
+	for nonce, f := range t.inflight {
+		if nonce <= seq {
+			done = append(done, f)
+		}
+	}
+	t.mu.RUnlock()
+
+	if len(done) > 0 {
+		sort.Slice(done, func(i, j int) bool { return done[i].msg.Nonce < done[j].msg.Nonce })
+		msgs := make([]OutMsg, len(done))
+		for i, f := range done {
+			msgs[i] = f.msg
+		}
+		if err := t.Target.ConfirmOutMsgs(msgs); err != nil {
+			return err
+		}
+
+		for _, f := range done {
+			t.TxRecorder.MarkConfirmed(f.txNonce)
+			if err := t.Queue.Ack(f.msg.Nonce); err != nil {
+				return err
+			}
+			t.mu.Lock()
+			delete(t.inflight, f.msg.Nonce)
+			t.mu.Unlock()
+		}
+	}
+
+	t.mu.Lock()
+	t.Sequence.ID = max(t.Sequence.ID, seq)
+	t.Sequence.Height = max(t.Sequence.Height, height)
+	t.mu.Unlock()
+	return nil
+}
+
+// HandleError 记录错误并交给错误处理器，返回的错误由 supervisor 决定重启
+func (t *OutTunnel) HandleError(ctx context.Context, err error, metadata map[string]any) error {
+	metadata["path"] = t.Path
+	metadata["error"] = err
+	t.logger.Error("tunnel task error", metadata)
+
+	if t.ErrorHandler != nil {
+		t.ErrorHandler.HandleError(ctx, err, metadata)
+	}
+	return err
+}
+
+// Start 在后台运行 Tunnel，通过 Stop 停止
+func (t *OutTunnel) Start() error {
+	return t.start(t.Run)
+}
+
+// Status 返回 Tunnel 的状态信息
+func (t *OutTunnel) Status() map[string]any {
+	t.mu.RLock()
+	status := map[string]any{
+		"path":     t.Path,
+		"state":    t.State().String(),
+		"sequence": t.Sequence.ID,
+		"height":   t.Sequence.Height,
+		"nonce":    t.Nonce,
+		"inflight": len(t.inflight),
+	}
+	if t.skippedGaps > 0 {
+		status["skipped_gaps"] = t.skippedGaps
+	}
+	t.mu.RUnlock()
+
+	if tasks := t.tasks(); tasks != nil {
+		status["tasks"] = tasks
+	}
+	if t.Queue != nil {
+		status["queue"] = t.Queue.Metrics()
+	}
+	if t.TxRecorder != nil {
+		status["pending_txs"] = t.TxRecorder.GetPendingCount()
+	}
+	return status
+}
@@ -0,0 +1,191 @@
+package relay
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSigner struct{}
+
+func (fakeSigner) Address() string                                           { return "0x01" }
+func (fakeSigner) PublicKey() string                                         { return "" }
+func (fakeSigner) SignData(ctx context.Context, data []byte) ([]byte, error) { return data, nil }
+func (fakeSigner) Close() error                                              { return nil }
+
+// fakeSource 模拟源端：收到的跨出消息立即视为已处理
+type fakeSource struct {
+	mu       sync.Mutex
+	seq      uint64
+	txNonces []uint64 // 收到的跨出消息携带的交易 nonce
+}
+
+func (s *fakeSource) LatestHeight() (int64, error)                              { return 0, nil }
+func (s *fakeSource) FilterInMsgs(fromHeight, toHeight uint64) ([]InMsg, error) { return nil, nil }
+func (s *fakeSource) SubscribeToInMsgs(msgs chan InMsg) error                   { return nil }
+func (s *fakeSource) GetNonce(address string) uint64                            { return 7 }
+
+func (s *fakeSource) GetSequence() (uint64, uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq, 0
+}
+
+func (s *fakeSource) ProcessOutMsgs(msgs <-chan OutMsg) error {
+	go func() {
+		for msg := range msgs {
+			s.mu.Lock()
+			s.seq = max(s.seq, msg.Nonce)
+			s.txNonces = append(s.txNonces, msg.TxNonce)
+			s.mu.Unlock()
+		}
+	}()
+	return nil
+}
+
+// fakeTarget 模拟目标端：订阅时发送预设的跨出消息，并记录被确认的消息
+type fakeTarget struct {
+	out []OutMsg
+
+	mu        sync.Mutex
+	confirmed []uint64
+}
+
+func (t *fakeTarget) ProcessInMsgs(msgs <-chan InMsg) (<-chan ProcessError, error) {
+	return nil, nil
+}
+func (t *fakeTarget) HandleError(ctx context.Context, err error, metadata map[string]interface{}) error {
+	return err
+}
+func (t *fakeTarget) GetSequence() (uint64, uint64)                   { return 0, 0 }
+func (t *fakeTarget) GetNonce(address string) uint64                  { return 0 }
+func (t *fakeTarget) SubscribeToBatchMsgs() (<-chan *BatchMsg, error) { return nil, nil }
+
+func (t *fakeTarget) SubscribeToOutMsgs(msgs chan OutMsg) error {
+	for _, msg := range t.out {
+		msgs <- msg
+	}
+	return nil
+}
+
+func (t *fakeTarget) ConfirmOutMsgs(msgs []OutMsg) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, msg := range msgs {
+		t.confirmed = append(t.confirmed, msg.Nonce)
+	}
+	return nil
+}
+
+func (t *fakeTarget) Confirmed() []uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]uint64(nil), t.confirmed...)
+}
+
+func TestOutTunnelRelaysAndConfirms(t *testing.T) {
+	source := &fakeSource{}
+	target := &fakeTarget{out: []OutMsg{{Nonce: 2}, {Nonce: 1}, {Nonce: 3}}}
+
+	tunnel := NewOutTunnel(source, target, fakeSigner{})
+	tunnel.ConfirmInterval = time.Millisecond
+	tunnel.ErrorHandler = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tunnel.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(target.Confirmed()) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 3 confirmed messages, got %v", target.Confirmed())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if got, want := target.Confirmed(), []uint64{1, 2, 3}; !equalNonces(got, want) {
+		t.Errorf("Expected confirmed %v, got %v", want, got)
+	}
+	if tunnel.State() != StateRunning {
+		t.Errorf("Expected running, got %s", tunnel.State())
+	}
+
+	cancel()
+	<-done
+	if tunnel.State() != StateStopped {
+		t.Errorf("Expected stopped, got %s", tunnel.State())
+	}
+	status := tunnel.Status()
+	if status["sequence"] != uint64(3) || status["nonce"] != uint64(7) {
+		t.Errorf("Unexpected status %v", status)
+	}
+	// 源端按分配的交易 nonce 提交
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	if want := []uint64{7, 8, 9}; !equalNonces(source.txNonces, want) {
+		t.Errorf("Expected tx nonces %v, got %v", want, source.txNonces)
+	}
+}
+
+// filterTarget 是支持按 nonce 区间查询跨出消息的目标端
+type filterTarget struct {
+	fakeTarget
+	msgs []OutMsg
+}
+
+func (t *filterTarget) FilterOutMsgs(fromNonce, toNonce uint64) ([]OutMsg, error) {
+	var out []OutMsg
+	for _, msg := range t.msgs {
+		if msg.Nonce >= fromNonce && msg.Nonce <= toNonce {
+			out = append(out, msg)
+		}
+	}
+	return out, nil
+}
+
+func TestOutTunnelBackfillsGap(t *testing.T) {
+	target := &filterTarget{msgs: []OutMsg{{Nonce: 1}}}
+	tunnel := NewOutTunnel(&fakeSource{}, target, fakeSigner{})
+	tunnel.ErrorHandler = nil
+	if err := tunnel.Init(); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	tunnel.Queue.Push(OutMsg{Nonce: 2})
+	tunnel.backfill(Gap{FromNonce: 1, ToNonce: 1, Since: time.Now()})
+	for _, want := range []uint64{1, 2} {
+		if msg := <-tunnel.Queue.Msgs; msg.Nonce != want {
+			t.Fatalf("Expected nonce %d, got %d", want, msg.Nonce)
+		}
+	}
+}
+
+func TestOutTunnelSkipsUnrecoverableGap(t *testing.T) {
+	tunnel := NewOutTunnel(&fakeSource{}, &fakeTarget{}, fakeSigner{})
+	tunnel.ErrorHandler = nil
+	if err := tunnel.Init(); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	tunnel.Queue.Push(OutMsg{Nonce: 3})
+	gap := Gap{FromNonce: 1, ToNonce: 2, Since: time.Now()}
+
+	// 缺口未超过等待时间时保留
+	tunnel.backfill(gap)
+	if len(tunnel.Queue.Msgs) != 0 {
+		t.Fatal("Expected gap to be kept before the skip timeout")
+	}
+
+	// 超过等待时间后跳过缺口，放行之后的消息
+	tunnel.GapSkipTimeout = 0
+	tunnel.backfill(gap)
+	if msg := <-tunnel.Queue.Msgs; msg.Nonce != 3 {
+		t.Fatalf("Expected nonce 3 after skipping the gap, got %d", msg.Nonce)
+	}
+	if status := tunnel.Status(); status["skipped_gaps"] != uint64(1) {
+		t.Errorf("Expected one skipped gap, got %v", status)
+	}
+}
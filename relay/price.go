@@ -0,0 +1,214 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// 价格源错误
+var (
+	ErrInvalidPrice = errors.New("invalid price")
+	ErrStalePrice   = errors.New("stale price")
+)
+
+func init() {
+	types.RegisterClass(ErrInvalidPrice, types.ClassRetryable)
+	types.RegisterClass(ErrStalePrice, types.ClassRetryable)
+}
+
+// Price 是目标链原生代币以跨链代币计的价格：1 个原生代币 = Answer / 10^Decimals 个跨链代币
+type Price struct {
+	Answer    *big.Int  `json:"answer"`
+	Decimals  uint8     `json:"decimals"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Source    string    `json:"source"`
+}
+
+// PriceSource 提供原生代币兑跨链代币的价格
+type PriceSource interface {
+	Price(ctx context.Context) (*Price, error)
+}
+
+// ParsePrice 将十进制价格（如 "612.35"）精确解析为定点数 Answer / 10^Decimals
+func ParsePrice(s string) (*big.Int, uint8, error) {
+	whole, frac, _ := strings.Cut(strings.TrimSpace(s), ".")
+	if len(frac) > 77 {
+		return nil, 0, fmt.Errorf("%w: too many decimals in %q", ErrInvalidPrice, s)
+	}
+	answer, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok || whole == "" || strings.ContainsAny(whole+frac, "+-_") || answer.Sign() <= 0 {
+		return nil, 0, fmt.Errorf("%w: %q", ErrInvalidPrice, s)
+	}
+	return answer, uint8(len(frac)), nil
+}
+
+// StaticPrice 是配置中的固定价格，不会过期
+type StaticPrice struct {
+	Answer   *big.Int
+	Decimals uint8
+}
+
+func (p StaticPrice) Price(ctx context.Context) (*Price, error) {
+	if p.Answer == nil || p.Answer.Sign() <= 0 {
+		return nil, ErrInvalidPrice
+	}
+	return &Price{Answer: new(big.Int).Set(p.Answer), Decimals: p.Decimals, UpdatedAt: time.Now(), Source: "static"}, nil
+}
+
+// aggregatorABI 是 Chainlink AggregatorV3Interface 中读取价格的方法
+const aggregatorABI = `[{
+	"type": "function",
+	"name": "decimals",
+	"stateMutability": "view",
+	"inputs": [],
+	"outputs": [{"name": "", "type": "uint8"}]
+}, {
+	"type": "function",
+	"name": "latestRoundData",
+	"stateMutability": "view",
+	"inputs": [],
+	"outputs": [
+		{"name": "roundId", "type": "uint80"},
+		{"name": "answer", "type": "int256"},
+		{"name": "startedAt", "type": "uint256"},
+		{"name": "updatedAt", "type": "uint256"},
+		{"name": "answeredInRound", "type": "uint80"}
+	]
+}]`
+
+var chainlinkAggregatorABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(aggregatorABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// ChainlinkPrice 通过 CallContract 读取 Chainlink 聚合器的最新一轮价格，
+// 聚合器的报价对应为原生代币 / 跨链代币
+type ChainlinkPrice struct {
+	Caller     ethereum.ContractCaller
+	Aggregator common.Address
+
+	mu       sync.Mutex
+	decimals *uint8 // 聚合器精度不会变化，首次读取后缓存
+}
+
+func NewChainlinkPrice(caller ethereum.ContractCaller, aggregator common.Address) *ChainlinkPrice {
+	return &ChainlinkPrice{Caller: caller, Aggregator: aggregator}
+}
+
+func (p *ChainlinkPrice) Price(ctx context.Context) (*Price, error) {
+	decimals, err := p.readDecimals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := p.call(ctx, "latestRoundData")
+	if err != nil {
+		return nil, err
+	}
+	roundID, _ := values[0].(*big.Int)
+	answer, _ := values[1].(*big.Int)
+	updatedAt, _ := values[3].(*big.Int)
+	answeredInRound, _ := values[4].(*big.Int)
+	if answer == nil || answer.Sign() <= 0 || updatedAt == nil || !updatedAt.IsInt64() {
+		return nil, fmt.Errorf("%w: aggregator %s answer %v", ErrInvalidPrice, p.Aggregator.Hex(), answer)
+	}
+	if roundID != nil && answeredInRound != nil && answeredInRound.Cmp(roundID) < 0 {
+		return nil, fmt.Errorf("%w: aggregator %s round %s answered in %s", ErrStalePrice, p.Aggregator.Hex(), roundID, answeredInRound)
+	}
+
+	return &Price{
+		Answer:    answer,
+		Decimals:  decimals,
+		UpdatedAt: time.Unix(updatedAt.Int64(), 0),
+		Source:    "chainlink:" + p.Aggregator.Hex(),
+	}, nil
+}
+
+func (p *ChainlinkPrice) readDecimals(ctx context.Context) (uint8, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.decimals != nil {
+		return *p.decimals, nil
+	}
+	values, err := p.call(ctx, "decimals")
+	if err != nil {
+		return 0, err
+	}
+	decimals, ok := values[0].(uint8)
+	if !ok {
+		return 0, fmt.Errorf("%w: unexpected decimals %v", ErrInvalidPrice, values[0])
+	}
+	p.decimals = &decimals
+	return decimals, nil
+}
+
+func (p *ChainlinkPrice) call(ctx context.Context, method string) ([]any, error) {
+	data, err := chainlinkAggregatorABI.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	out, err := p.Caller.CallContract(ctx, ethereum.CallMsg{To: &p.Aggregator, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	values, err := chainlinkAggregatorABI.Unpack(method, out)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidPrice, method, err)
+	}
+	return values, nil
+}
+
+// HTTPPrice 从本地 HTTP 价格服务读取价格，响应格式为
+// {"price": "612.35", "updated_at": 1700000000}，updated_at 为 Unix 秒
+type HTTPPrice struct {
+	URL    string
+	Client *http.Client
+}
+
+func (p *HTTPPrice) Price(ctx context.Context) (*Price, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("price feed %s: unexpected status %s", p.URL, resp.Status)
+	}
+
+	var body struct {
+		Price     json.Number `json:"price"`
+		UpdatedAt int64       `json:"updated_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidPrice, p.URL, err)
+	}
+	answer, decimals, err := ParsePrice(body.Price.String())
+	if err != nil {
+		return nil, err
+	}
+	return &Price{Answer: answer, Decimals: decimals, UpdatedAt: time.Unix(body.UpdatedAt, 0), Source: p.URL}, nil
+}
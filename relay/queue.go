@@ -1,33 +1,384 @@
 package relay
 
-// queue 实现一个简单的消息队列,对进入队列的消息进行nonce校验
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// DefaultGapTimeout 是 nonce 缺口持续多久后触发回补
+const DefaultGapTimeout = 30 * time.Second
+
+// Gap 描述队列中持续存在的 nonce 缺口，[FromNonce, ToNonce] 为缺失的 nonce 区间。
+// 消息携带区块高度时，FromHeight/ToHeight 为缺口两侧消息所在区块，用于定向回补。
+type Gap struct {
+	FromNonce  uint64
+	ToNonce    uint64
+	FromHeight uint64
+	ToHeight   uint64
+	Since      time.Time
+}
+
+// QueueMetrics 是队列计数器的快照
+type QueueMetrics struct {
+	Accepted   uint64 `json:"accepted"`   // 按顺序放行的消息数
+	Duplicates uint64 `json:"duplicates"` // 重复消息数（nonce 已放行或已在缓冲区中）
+	Buffered   uint64 `json:"buffered"`   // 因乱序进入缓冲区的消息数
+	Evicted    uint64 `json:"evicted"`    // 缓冲区满时被淘汰的消息数，需依赖回补重新获取
+	Gaps       uint64 `json:"gaps"`       // 触发回补的缺口数
+	Pending    int    `json:"pending"`    // 当前缓冲区中的消息数
+}
+
+// heighter 由携带源端区块高度的消息实现
+type heighter interface {
+	GetHeight() uint64
+}
+
+// Queue 实现一个按 nonce 顺序放行的消息队列。
+// nonce 超前的消息进入有界最小堆等待缺口补齐，缺口持续超过 gapTimeout 时通过 onGap 触发回补。
+// 配置了 store 的队列在放行前持久化消息，消息在目标端确认后通过 Ack 确认，
+// 重启时未确认的消息会被重新投递（至少一次）。
 type Queue[T Message] struct {
 	LatestSeq uint64
 	Msgs      chan T
 
+	mu           sync.Mutex
+	pending      msgHeap[T]
+	pendingSet   map[uint64]struct{}
+	maxPending   int
+	latestHeight uint64 // 最近放行消息所在区块
+	gapSince     time.Time
+	gapTimeout   time.Duration
+	onGap        func(Gap)
+	metrics      QueueMetrics
+	logger       *log.Logger
+
+	outbox  []T  // 已放行、等待发送到 Msgs 的消息
+	sending bool // 是否有调用方正在发送 outbox
+
+	name  string             // 持久化队列名称
+	store types.MessageStore // 为 nil 时为纯内存队列
+
+	redelivered map[uint64]T // 重启时重新投递、尚未确认的消息，确认时用于找回重启前已提交的消息
+
 	// stopCh chan struct{}
 	// done   chan struct{}
 }
 
 func NewQueue[T Message](latestSeq uint64, bufferSize int) *Queue[T] {
 	return &Queue[T]{
-		LatestSeq: latestSeq,
-		Msgs:      make(chan T, bufferSize),
+		LatestSeq:  latestSeq,
+		Msgs:       make(chan T, bufferSize),
+		pendingSet: make(map[uint64]struct{}),
+		maxPending: bufferSize,
+		gapTimeout: DefaultGapTimeout,
+		logger:     log.WithComponent("queue"),
 	}
 }
 
-// Push 将消息推入队列，要求消息的nonce必须是连续的
+// NewDurableQueue 创建持久化队列，从 store 恢复已放行的最大 nonce，
+// 并将未确认的消息按 nonce 顺序重新放入队列
+func NewDurableQueue[T Message](name string, store types.MessageStore, bufferSize int) (*Queue[T], error) {
+	latest, err := store.LatestNonce(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load latest nonce of queue %s: %w", name, err)
+	}
+	unacked, err := store.Unacked(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load unacked messages of queue %s: %w", name, err)
+	}
+
+	q := NewQueue[T](latest, max(bufferSize, len(unacked)+bufferSize))
+	q.maxPending = bufferSize
+	q.name = name
+	q.store = store
+	q.redelivered = make(map[uint64]T, len(unacked))
+
+	for _, stored := range unacked {
+		var msg T
+		if err := json.Unmarshal(stored.Payload, &msg); err != nil {
+			return nil, fmt.Errorf("failed to decode message %d of queue %s: %w", stored.Nonce, name, err)
+		}
+		q.Msgs <- msg
+		q.redelivered[stored.Nonce] = msg
+	}
+	if len(unacked) > 0 {
+		q.logger.Info("redelivering unacked messages", map[string]any{
+			"queue": name,
+			"count": len(unacked),
+			"from":  unacked[0].Nonce,
+		})
+	}
+
+	return q, nil
+}
+
+// Ack 确认 nonce 对应的消息已在目标端完成，持久化队列重启后不再投递
+func (q *Queue[T]) Ack(nonce uint64) error {
+	if q.store == nil {
+		return nil
+	}
+	if err := q.store.Ack(q.name, nonce); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	delete(q.redelivered, nonce)
+	q.mu.Unlock()
+	return nil
+}
+
+// Redelivered 返回重启时重新投递且尚未确认的消息
+func (q *Queue[T]) Redelivered(nonce uint64) (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	msg, ok := q.redelivered[nonce]
+	return msg, ok
+}
+
+// SetMaxPending 设置乱序缓冲区的容量上限
+func (q *Queue[T]) SetMaxPending(n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.maxPending = n
+}
+
+// SetGapHandler 设置缺口回补回调，缺口持续超过 timeout 时调用
+func (q *Queue[T]) SetGapHandler(timeout time.Duration, fn func(Gap)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.gapTimeout = timeout
+	q.onGap = fn
+}
+
+// Push 将消息推入队列。nonce 连续的消息立即放行，超前的消息缓存到缺口补齐后按序放行，
+// 已放行或已缓存的 nonce 视为重复丢弃
 func (q *Queue[T]) Push(msg T) {
-	if msg.GetNonce() != q.LatestSeq+1 {
-		// 如果nonce不连续，直接丢弃
+	q.mu.Lock()
+	q.push(msg)
+	q.mu.Unlock()
+	q.flush()
+}
+
+// push 放行或缓存一条消息，调用方需持有 q.mu
+func (q *Queue[T]) push(msg T) {
+	nonce := msg.GetNonce()
+	switch {
+	case nonce <= q.LatestSeq:
+		q.metrics.Duplicates++
+		return
+	case nonce == q.LatestSeq+1:
+		if err := q.emit(msg); err != nil {
+			// 持久化失败时暂存到缓冲区，下一次放行时重试
+			q.logger.Error("failed to persist message", map[string]any{"nonce": nonce, "error": err})
+			break
+		}
+		q.release()
+		return
+	}
+
+	if _, ok := q.pendingSet[nonce]; ok {
+		q.metrics.Duplicates++
 		return
 	}
+
+	if q.maxPending > 0 && len(q.pending) >= q.maxPending {
+		// 缓冲区已满，淘汰 nonce 最大的消息，缺口回补时会重新获取
+		far := q.pending.farthest()
+		if nonce >= q.pending[far].GetNonce() {
+			q.metrics.Evicted++
+			return
+		}
+		evicted := heap.Remove(&q.pending, far).(T)
+		delete(q.pendingSet, evicted.GetNonce())
+		q.metrics.Evicted++
+	}
+
+	heap.Push(&q.pending, msg)
+	q.pendingSet[nonce] = struct{}{}
+	q.metrics.Buffered++
+	if q.gapSince.IsZero() {
+		q.gapSince = time.Now()
+	}
+}
+
+// emit 持久化并放行一条消息，消息由 flush 发送到 Msgs，调用方需持有 q.mu
+func (q *Queue[T]) emit(msg T) error {
+	if q.store != nil {
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if err := q.store.Append(q.name, msg.GetNonce(), payload); err != nil {
+			return err
+		}
+	}
+
 	q.LatestSeq = msg.GetNonce()
+	if h, ok := any(msg).(heighter); ok {
+		q.latestHeight = h.GetHeight()
+	}
+	q.metrics.Accepted++
+	q.outbox = append(q.outbox, msg)
+	return nil
+}
+
+// flush 在不持有 q.mu 的情况下将已放行的消息按序发送到 Msgs。
+// 同一时刻只有一个调用方发送，Msgs 已满时只阻塞该调用方，不影响 Push、Metrics 和 CheckGaps
+func (q *Queue[T]) flush() {
+	q.mu.Lock()
+	if q.sending {
+		q.mu.Unlock()
+		return
+	}
+	q.sending = true
+	for len(q.outbox) > 0 {
+		msg := q.outbox[0]
+		q.outbox = q.outbox[1:]
+		q.mu.Unlock()
+		q.Msgs <- msg
+		q.mu.Lock()
+	}
+	q.sending = false
+	q.mu.Unlock()
+}
+
+// release 按序放行缓冲区中已连续的消息，调用方需持有 q.mu
+func (q *Queue[T]) release() {
+	for len(q.pending) > 0 {
+		next := q.pending[0]
+		nonce := next.GetNonce()
+		if nonce > q.LatestSeq+1 {
+			break
+		}
+		if nonce == q.LatestSeq+1 {
+			if err := q.emit(next); err != nil {
+				q.logger.Error("failed to persist message", map[string]any{"nonce": nonce, "error": err})
+				break
+			}
+		}
+		heap.Pop(&q.pending)
+		delete(q.pendingSet, nonce)
+	}
+
+	if len(q.pending) == 0 {
+		q.gapSince = time.Time{}
+	} else {
+		// 缺口前移，重新计时
+		q.gapSince = time.Now()
+	}
+}
+
+// CheckGaps 检查缺口是否超时，超时则触发回补并重新计时
+func (q *Queue[T]) CheckGaps() {
+	q.mu.Lock()
+	// 先重试因持久化失败而滞留的消息
+	if len(q.pending) > 0 && q.pending[0].GetNonce() == q.LatestSeq+1 {
+		q.release()
+	}
+	if len(q.pending) == 0 || q.gapSince.IsZero() || time.Since(q.gapSince) < q.gapTimeout {
+		q.mu.Unlock()
+		q.flush()
+		return
+	}
+
+	next := q.pending[0]
+	gap := Gap{
+		FromNonce:  q.LatestSeq + 1,
+		ToNonce:    next.GetNonce() - 1,
+		FromHeight: q.latestHeight,
+		Since:      q.gapSince,
+	}
+	if h, ok := any(next).(heighter); ok {
+		gap.ToHeight = h.GetHeight()
+	}
+	q.gapSince = time.Now()
+	q.metrics.Gaps++
+	onGap := q.onGap
+	q.mu.Unlock()
+	q.flush()
+
+	q.logger.Warn("nonce gap persisted, requesting backfill", map[string]any{
+		"from_nonce":  gap.FromNonce,
+		"to_nonce":    gap.ToNonce,
+		"from_height": gap.FromHeight,
+		"to_height":   gap.ToHeight,
+	})
+	if onGap != nil {
+		onGap(gap)
+	}
+}
+
+// SkipGap 放弃无法回补的缺口：将 [gap.FromNonce, gap.ToNonce] 视为已放行，并放行之后已缓存的消息。
+// 缺口已补齐或已变化时不做任何操作，返回是否跳过
+func (q *Queue[T]) SkipGap(gap Gap) bool {
+	q.mu.Lock()
+	if q.LatestSeq+1 != gap.FromNonce || len(q.pending) == 0 || q.pending[0].GetNonce() != gap.ToNonce+1 {
+		q.mu.Unlock()
+		return false
+	}
+	q.LatestSeq = gap.ToNonce
+	q.release()
+	q.mu.Unlock()
+	q.flush()
+	return true
+}
 
-	q.Msgs <- msg
+// WatchGaps 定期检查缺口，直到 stop 关闭
+func (q *Queue[T]) WatchGaps(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			q.CheckGaps()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Metrics 返回队列计数器的快照
+func (q *Queue[T]) Metrics() QueueMetrics {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	m := q.metrics
+	m.Pending = len(q.pending)
+	return m
 }
 
-// Pop 从队列中弹出消息，若队列为空则返回nil
+// Pop 从队列中弹出消息，若队列为空则阻塞
 func (q *Queue[T]) Pop() T {
 	return <-q.Msgs
 }
+
+// msgHeap 是按 nonce 排序的最小堆
+type msgHeap[T Message] []T
+
+func (h msgHeap[T]) Len() int           { return len(h) }
+func (h msgHeap[T]) Less(i, j int) bool { return h[i].GetNonce() < h[j].GetNonce() }
+func (h msgHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *msgHeap[T]) Push(x any)        { *h = append(*h, x.(T)) }
+
+func (h *msgHeap[T]) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// farthest 返回 nonce 最大的元素下标
+func (h msgHeap[T]) farthest() int {
+	far := 0
+	for i := range h {
+		if h[i].GetNonce() > h[far].GetNonce() {
+			far = i
+		}
+	}
+	return far
+}
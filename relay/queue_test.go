@@ -0,0 +1,232 @@
+package relay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+func drain(q *Queue[InMsg]) []uint64 {
+	var nonces []uint64
+	for {
+		select {
+		case msg := <-q.Msgs:
+			nonces = append(nonces, msg.Nonce)
+		default:
+			return nonces
+		}
+	}
+}
+
+func equalNonces(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQueueReordersOutOfOrderMessages(t *testing.T) {
+	q := NewQueue[InMsg](0, 16)
+
+	for _, n := range []uint64{3, 1, 4, 2, 5} {
+		q.Push(InMsg{Nonce: n})
+	}
+
+	got := drain(q)
+	if want := []uint64{1, 2, 3, 4, 5}; !equalNonces(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if q.LatestSeq != 5 {
+		t.Errorf("Expected LatestSeq 5, got %d", q.LatestSeq)
+	}
+}
+
+func TestQueueCountsDuplicates(t *testing.T) {
+	q := NewQueue[InMsg](0, 16)
+
+	q.Push(InMsg{Nonce: 1})
+	q.Push(InMsg{Nonce: 1}) // 已放行
+	q.Push(InMsg{Nonce: 3})
+	q.Push(InMsg{Nonce: 3}) // 已在缓冲区中
+
+	m := q.Metrics()
+	if m.Duplicates != 2 {
+		t.Errorf("Expected 2 duplicates, got %d", m.Duplicates)
+	}
+	if m.Pending != 1 {
+		t.Errorf("Expected 1 pending, got %d", m.Pending)
+	}
+	if m.Gaps != 0 {
+		t.Errorf("Expected no gaps reported yet, got %d", m.Gaps)
+	}
+}
+
+func TestQueueEvictsFarthestWhenFull(t *testing.T) {
+	q := NewQueue[InMsg](0, 16)
+	q.SetMaxPending(2)
+
+	q.Push(InMsg{Nonce: 5})
+	q.Push(InMsg{Nonce: 3})
+	q.Push(InMsg{Nonce: 4}) // 淘汰 5
+	q.Push(InMsg{Nonce: 9}) // 比缓冲区内所有消息都远，直接淘汰
+
+	if m := q.Metrics(); m.Evicted != 2 || m.Pending != 2 {
+		t.Errorf("Expected 2 evicted and 2 pending, got %+v", m)
+	}
+
+	q.Push(InMsg{Nonce: 1})
+	q.Push(InMsg{Nonce: 2})
+	if got, want := drain(q), []uint64{1, 2, 3, 4}; !equalNonces(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestQueueReportsPersistentGap(t *testing.T) {
+	q := NewQueue[InMsg](0, 16)
+
+	var gaps []Gap
+	q.SetGapHandler(time.Millisecond, func(g Gap) { gaps = append(gaps, g) })
+
+	q.Push(InMsg{Nonce: 1, Height: 100})
+	q.Push(InMsg{Nonce: 4, Height: 110})
+	time.Sleep(5 * time.Millisecond)
+	q.CheckGaps()
+
+	if len(gaps) != 1 {
+		t.Fatalf("Expected 1 gap, got %d", len(gaps))
+	}
+	g := gaps[0]
+	if g.FromNonce != 2 || g.ToNonce != 3 || g.FromHeight != 100 || g.ToHeight != 110 {
+		t.Errorf("Unexpected gap %+v", g)
+	}
+
+	// 回补后缺口消失
+	q.Push(InMsg{Nonce: 2})
+	q.Push(InMsg{Nonce: 3})
+	time.Sleep(5 * time.Millisecond)
+	q.CheckGaps()
+	if len(gaps) != 1 {
+		t.Errorf("Expected gap to be closed, got %d reports", len(gaps))
+	}
+	if m := q.Metrics(); m.Gaps != 1 || m.Pending != 0 {
+		t.Errorf("Unexpected metrics %+v", m)
+	}
+}
+
+func TestQueueFullChannelDoesNotBlockMetrics(t *testing.T) {
+	q := NewQueue[InMsg](0, 1)
+	q.Push(InMsg{Nonce: 1})
+
+	// 通道已满，发送方阻塞在通道上而不是队列锁上
+	blocked := make(chan struct{})
+	go func() {
+		q.Push(InMsg{Nonce: 2})
+		close(blocked)
+	}()
+	done := make(chan struct{})
+	go func() {
+		q.Push(InMsg{Nonce: 3})
+		q.Metrics()
+		q.CheckGaps()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Push, Metrics and CheckGaps not to block on a full channel")
+	}
+
+	var got []uint64
+	for len(got) < 3 {
+		select {
+		case msg := <-q.Msgs:
+			got = append(got, msg.Nonce)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected all messages to be delivered, got %v", got)
+		}
+	}
+	if want := []uint64{1, 2, 3}; !equalNonces(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	<-blocked
+}
+
+// memStore 是测试用的内存消息存储
+type memStore struct {
+	msgs  map[uint64][]byte
+	acked map[uint64]bool
+}
+
+func newMemStore() *memStore {
+	return &memStore{msgs: make(map[uint64][]byte), acked: make(map[uint64]bool)}
+}
+
+func (s *memStore) Append(queue string, nonce uint64, payload []byte) error {
+	if _, ok := s.msgs[nonce]; !ok {
+		s.msgs[nonce] = payload
+	}
+	return nil
+}
+
+func (s *memStore) Ack(queue string, nonce uint64) error {
+	s.acked[nonce] = true
+	return nil
+}
+
+func (s *memStore) Unacked(queue string) ([]types.StoredMessage, error) {
+	var out []types.StoredMessage
+	for nonce := uint64(0); nonce <= uint64(len(s.msgs)); nonce++ {
+		if payload, ok := s.msgs[nonce]; ok && !s.acked[nonce] {
+			out = append(out, types.StoredMessage{Nonce: nonce, Payload: payload})
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) LatestNonce(queue string) (uint64, error) {
+	var latest uint64
+	for nonce := range s.msgs {
+		latest = max(latest, nonce)
+	}
+	return latest, nil
+}
+
+func (s *memStore) Close() error { return nil }
+
+func TestDurableQueueRedeliversUnacked(t *testing.T) {
+	store := newMemStore()
+
+	q, err := NewDurableQueue[InMsg]("test", store, 16)
+	if err != nil {
+		t.Fatalf("Failed to create durable queue: %v", err)
+	}
+	for n := uint64(1); n <= 3; n++ {
+		q.Push(InMsg{Nonce: n, TxHash: "0xabc"})
+	}
+	drain(q)
+	q.Ack(1)
+
+	// 模拟重启
+	q, err = NewDurableQueue[InMsg]("test", store, 16)
+	if err != nil {
+		t.Fatalf("Failed to restore durable queue: %v", err)
+	}
+	if q.LatestSeq != 3 {
+		t.Errorf("Expected LatestSeq 3, got %d", q.LatestSeq)
+	}
+	if got, want := drain(q), []uint64{2, 3}; !equalNonces(got, want) {
+		t.Errorf("Expected redelivered %v, got %v", want, got)
+	}
+
+	q.Push(InMsg{Nonce: 3}) // 重启后回放的旧消息被去重
+	q.Push(InMsg{Nonce: 4})
+	if got, want := drain(q), []uint64{4}; !equalNonces(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,178 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+)
+
+// 报价默认值
+const (
+	DefaultQuoteTTL     = 30 * time.Second
+	DefaultQuoteTimeout = 10 * time.Second
+)
+
+// ErrNoGasEstimator 目标端不支持估算中继调用的 gas
+var ErrNoGasEstimator = errors.New("target does not support gas estimation")
+
+// GasEstimator 由能够估算中继调用 gas 的目标端实现
+type GasEstimator interface {
+	// EstimateRelayGas 估算由 from 提交 msg 所需的 gas 用量
+	EstimateRelayGas(ctx context.Context, from string, msg InMsg) (uint64, error)
+	// SuggestGasPrice 返回目标链当前的 gas 价格（wei）
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// FeeQuote 是一次中继的 gas 成本报价，Fee 为折算成跨链代币的成本，向上取整
+type FeeQuote struct {
+	GasLimit  uint64    `json:"gas_limit"`
+	GasPrice  *big.Int  `json:"gas_price"`
+	GasCost   *big.Int  `json:"gas_cost"`  // 原生代币最小单位
+	Estimated bool      `json:"estimated"` // false 表示估算失败，使用了默认 gas 上限
+	Price     *Price    `json:"price"`
+	Fee       *big.Int  `json:"fee"` // 跨链代币最小单位
+	QuotedAt  time.Time `json:"quoted_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FeeQuoter 估算目标端中继调用的 gas 成本，并通过价格源折算成跨链代币。
+// 报价在 TTL 内缓存，过期前的查询和手续费计算都使用同一报价。
+// FeeQuoter 实现 GasCoster，可作为 gas 手续费模型的成本来源
+type FeeQuoter struct {
+	Estimator GasEstimator
+	Prices    PriceSource
+
+	From     string // 估算 gas 时使用的中继账户
+	Sample   InMsg  // 估算 gas 时使用的代表性消息
+	GasLimit uint64 // 估算失败时使用的 gas 上限，为 0 时估算失败直接返回错误
+
+	NativeDecimals uint8         // 目标链原生代币精度
+	TokenDecimals  uint8         // 跨链代币精度
+	MaxPriceAge    time.Duration // 价格最长有效期，为 0 时不检查
+	TTL            time.Duration // 报价缓存时间
+	Timeout        time.Duration // GasCost 查询报价的超时
+
+	logger *log.Logger
+	now    func() time.Time
+
+	mu     sync.Mutex
+	cached *FeeQuote
+}
+
+func NewFeeQuoter(estimator GasEstimator, prices PriceSource) *FeeQuoter {
+	return &FeeQuoter{
+		Estimator:      estimator,
+		Prices:         prices,
+		NativeDecimals: 18,
+		TokenDecimals:  18,
+		TTL:            DefaultQuoteTTL,
+		Timeout:        DefaultQuoteTimeout,
+		logger:         log.WithComponent("fee-quoter"),
+		now:            time.Now,
+	}
+}
+
+// Quote 返回当前报价，缓存未过期时直接返回缓存。并发查询在刷新期间等待同一次刷新
+func (q *FeeQuoter) Quote(ctx context.Context) (*FeeQuote, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.cached != nil && q.now().Before(q.cached.ExpiresAt) {
+		return q.cached, nil
+	}
+	quote, err := q.quote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	q.cached = quote
+	return quote, nil
+}
+
+// GasCost 返回当前报价折算成跨链代币的 gas 成本
+func (q *FeeQuoter) GasCost() (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), q.Timeout)
+	defer cancel()
+	quote, err := q.Quote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(quote.Fee), nil
+}
+
+func (q *FeeQuoter) quote(ctx context.Context) (*FeeQuote, error) {
+	if q.Estimator == nil {
+		return nil, ErrNoGasEstimator
+	}
+	now := q.now()
+
+	gasPrice, err := q.Estimator.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("suggest gas price: %w", err)
+	}
+	estimated := true
+	gasLimit, err := q.Estimator.EstimateRelayGas(ctx, q.From, q.Sample)
+	if err != nil {
+		if q.GasLimit == 0 {
+			return nil, fmt.Errorf("estimate relay gas: %w", err)
+		}
+		q.logger.Warn("gas estimation failed, using default gas limit", map[string]any{
+			"gas_limit": q.GasLimit,
+			"error":     err,
+		})
+		gasLimit, estimated = q.GasLimit, false
+	}
+
+	price, err := q.Prices.Price(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if price.Answer == nil || price.Answer.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %s answer %v", ErrInvalidPrice, price.Source, price.Answer)
+	}
+	if age := now.Sub(price.UpdatedAt); q.MaxPriceAge > 0 && age > q.MaxPriceAge {
+		return nil, fmt.Errorf("%w: %s updated %s ago, max %s", ErrStalePrice, price.Source, age.Truncate(time.Second), q.MaxPriceAge)
+	}
+
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasPrice)
+	quote := &FeeQuote{
+		GasLimit:  gasLimit,
+		GasPrice:  gasPrice,
+		GasCost:   cost,
+		Estimated: estimated,
+		Price:     price,
+		Fee:       ConvertGasCost(cost, price, q.NativeDecimals, q.TokenDecimals),
+		QuotedAt:  now,
+		ExpiresAt: now.Add(q.TTL),
+	}
+	q.logger.Debug("fee quote refreshed", map[string]any{
+		"gas_limit": gasLimit,
+		"gas_price": gasPrice,
+		"price":     price.Answer,
+		"decimals":  price.Decimals,
+		"fee":       quote.Fee,
+	})
+	return quote, nil
+}
+
+// ConvertGasCost 将原生代币最小单位的 gas 成本按价格折算成跨链代币最小单位，向上取整：
+// ceil(cost × answer × 10^tokenDecimals / (10^priceDecimals × 10^nativeDecimals))
+func ConvertGasCost(cost *big.Int, price *Price, nativeDecimals, tokenDecimals uint8) *big.Int {
+	n := new(big.Int).Mul(cost, price.Answer)
+	n.Mul(n, pow10(tokenDecimals))
+	d := new(big.Int).Mul(pow10(price.Decimals), pow10(nativeDecimals))
+
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() > 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
+
+func pow10(n uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,224 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeEstimator 返回固定的 gas 用量和价格，并记录调用次数
+type fakeEstimator struct {
+	mu       sync.Mutex
+	gas      uint64
+	gasErr   error
+	gasPrice *big.Int
+	calls    int
+}
+
+func (e *fakeEstimator) EstimateRelayGas(ctx context.Context, from string, msg InMsg) (uint64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls++
+	return e.gas, e.gasErr
+}
+
+func (e *fakeEstimator) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Set(e.gasPrice), nil
+}
+
+// fakePrice 返回指定更新时间的价格
+type fakePrice struct {
+	answer    int64
+	decimals  uint8
+	updatedAt time.Time
+}
+
+func (p *fakePrice) Price(ctx context.Context) (*Price, error) {
+	return &Price{Answer: big.NewInt(p.answer), Decimals: p.decimals, UpdatedAt: p.updatedAt, Source: "fake"}, nil
+}
+
+func TestConvertGasCost(t *testing.T) {
+	tests := []struct {
+		name          string
+		cost          *big.Int
+		price         *Price
+		native, token uint8
+		want          string
+	}{
+		// 0.00063 BNB × 612.35 = 0.3857805 USDT（18 位）
+		{"bnb to usdt", big.NewInt(630000000000000), &Price{Answer: big.NewInt(61235), Decimals: 2}, 18, 18, "385780500000000000"},
+		// 折算到 6 位精度时向上取整
+		{"rounds up", big.NewInt(630000000000000), &Price{Answer: big.NewInt(61235), Decimals: 2}, 18, 6, "385781"},
+		{"zero cost", big.NewInt(0), &Price{Answer: big.NewInt(1), Decimals: 0}, 18, 18, "0"},
+		// Chainlink 8 位精度，原生代币 1 wei
+		{"one wei", big.NewInt(1), &Price{Answer: big.NewInt(61235000000), Decimals: 8}, 18, 18, "613"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertGasCost(tt.cost, tt.price, tt.native, tt.token); got.String() != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFeeQuoterCachesQuote(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	estimator := &fakeEstimator{gas: 100000, gasPrice: big.NewInt(3000000000)}
+	prices := &fakePrice{answer: 600, updatedAt: now}
+	quoter := NewFeeQuoter(estimator, prices)
+	quoter.now = func() time.Time { return now }
+
+	quote, err := quoter.Quote(context.Background())
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	// 100000 × 3 gwei = 0.0003 BNB，× 600 = 0.18
+	if quote.Fee.String() != "180000000000000000" || !quote.Estimated {
+		t.Fatalf("unexpected quote %+v", quote)
+	}
+
+	now = now.Add(DefaultQuoteTTL - time.Second)
+	if _, err := quoter.GasCost(); err != nil || estimator.calls != 1 {
+		t.Fatalf("expected cached quote, got %d estimations err %v", estimator.calls, err)
+	}
+	now = now.Add(time.Second)
+	if _, err := quoter.GasCost(); err != nil || estimator.calls != 2 {
+		t.Fatalf("expected refreshed quote, got %d estimations err %v", estimator.calls, err)
+	}
+}
+
+func TestFeeQuoterRejectsStalePrice(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	estimator := &fakeEstimator{gas: 100000, gasPrice: big.NewInt(1)}
+	quoter := NewFeeQuoter(estimator, &fakePrice{answer: 600, updatedAt: now.Add(-2 * time.Hour)})
+	quoter.now = func() time.Time { return now }
+	quoter.MaxPriceAge = time.Hour
+
+	if _, err := quoter.Quote(context.Background()); !errors.Is(err, ErrStalePrice) {
+		t.Fatalf("expected ErrStalePrice, got %v", err)
+	}
+
+	// gas 手续费模型在价格过期时不计算手续费，错误可重试
+	fc := &FeeCalculator{Model: FeeModelGas, Gas: quoter}
+	if _, err := fc.CalculateFee(big.NewInt(1)); !errors.Is(err, ErrGasCostUnavailable) || !errors.Is(err, ErrStalePrice) {
+		t.Fatalf("expected ErrGasCostUnavailable, got %v", err)
+	}
+}
+
+func TestFeeQuoterFallsBackToGasLimit(t *testing.T) {
+	estimator := &fakeEstimator{gasErr: errors.New("execution reverted"), gasPrice: big.NewInt(2)}
+	quoter := NewFeeQuoter(estimator, StaticPrice{Answer: big.NewInt(1)})
+
+	if _, err := quoter.Quote(context.Background()); err == nil {
+		t.Fatal("expected estimation error without default gas limit")
+	}
+	quoter.GasLimit = 50000
+	quote, err := quoter.Quote(context.Background())
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if quote.Estimated || quote.GasLimit != 50000 || quote.Fee.String() != "100000" {
+		t.Fatalf("unexpected quote %+v", quote)
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	valid := map[string][2]any{
+		"612.35": {"61235", uint8(2)},
+		"1":      {"1", uint8(0)},
+		"0.0001": {"1", uint8(4)},
+		"10.":    {"10", uint8(0)},
+	}
+	for s, want := range valid {
+		answer, decimals, err := ParsePrice(s)
+		if err != nil || answer.String() != want[0] || decimals != want[1] {
+			t.Fatalf("ParsePrice(%q) = %v, %d, %v", s, answer, decimals, err)
+		}
+	}
+	for _, s := range []string{"", "0", "0.00", "-1", "+1", ".5", "1e3", "abc", "1.2.3"} {
+		if _, _, err := ParsePrice(s); !errors.Is(err, ErrInvalidPrice) {
+			t.Fatalf("ParsePrice(%q): expected ErrInvalidPrice, got %v", s, err)
+		}
+	}
+}
+
+// fakeAggregator 模拟 Chainlink 聚合器合约
+type fakeAggregator struct {
+	round, answeredIn int64
+	answer            *big.Int
+	updatedAt         int64
+}
+
+func (a *fakeAggregator) CallContract(ctx context.Context, call ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	method, err := chainlinkAggregatorABI.MethodById(call.Data)
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "decimals":
+		return method.Outputs.Pack(uint8(8))
+	default:
+		return method.Outputs.Pack(big.NewInt(a.round), a.answer, big.NewInt(a.updatedAt), big.NewInt(a.updatedAt), big.NewInt(a.answeredIn))
+	}
+}
+
+func TestChainlinkPrice(t *testing.T) {
+	agg := &fakeAggregator{round: 7, answeredIn: 7, answer: big.NewInt(61235000000), updatedAt: 1700000000}
+	source := NewChainlinkPrice(agg, common.HexToAddress("0x0000000000000000000000000000000000000001"))
+
+	price, err := source.Price(context.Background())
+	if err != nil {
+		t.Fatalf("Price failed: %v", err)
+	}
+	if price.Answer.String() != "61235000000" || price.Decimals != 8 || price.UpdatedAt.Unix() != 1700000000 {
+		t.Fatalf("unexpected price %+v", price)
+	}
+
+	agg.answeredIn = 6
+	if _, err := source.Price(context.Background()); !errors.Is(err, ErrStalePrice) {
+		t.Fatalf("expected ErrStalePrice, got %v", err)
+	}
+	agg.answeredIn, agg.answer = 7, big.NewInt(-1)
+	if _, err := source.Price(context.Background()); !errors.Is(err, ErrInvalidPrice) {
+		t.Fatalf("expected ErrInvalidPrice, got %v", err)
+	}
+}
+
+func TestHTTPPrice(t *testing.T) {
+	body := `{"price": "612.35", "updated_at": 1700000000}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body == "" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	source := &HTTPPrice{URL: srv.URL}
+
+	price, err := source.Price(context.Background())
+	if err != nil {
+		t.Fatalf("Price failed: %v", err)
+	}
+	if price.Answer.String() != "61235" || price.Decimals != 2 || price.UpdatedAt.Unix() != 1700000000 {
+		t.Fatalf("unexpected price %+v", price)
+	}
+
+	body = `{"price": 0.5, "updated_at": 1}`
+	if price, err := source.Price(context.Background()); err != nil || price.Answer.String() != "5" {
+		t.Fatalf("expected numeric price, got %+v err %v", price, err)
+	}
+	body = ""
+	if _, err := source.Price(context.Background()); err == nil {
+		t.Fatal("expected error for unavailable price feed")
+	}
+}
@@ -0,0 +1,394 @@
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// 限流错误
+var (
+	ErrRateLimited            = errors.New("rate limit exceeded")
+	ErrInvalidRateLimitConfig = errors.New("invalid rate limit config")
+)
+
+func init() {
+	// 未配置挂起队列时超出限流的消息等待窗口滚动后重试
+	types.RegisterClass(ErrRateLimited, types.ClassRetryable)
+}
+
+// LimitScope 限流对象
+type LimitScope string
+
+const (
+	LimitBridge   LimitScope = "bridge"   // 整个跨链桥
+	LimitToken    LimitScope = "token"    // 每个目标端代币
+	LimitReceiver LimitScope = "receiver" // 每个接收地址
+)
+
+// 时间桶粒度为最短窗口的 1/60，不小于 minLimitBucket
+const (
+	limitBuckets   = 60
+	minLimitBucket = time.Second
+)
+
+// RateLimit 是一条滚动窗口限流规则。MaxValue 为目标端最小单位的累计金额上限，为 nil 时不限制；
+// MaxCount 为消息数上限，为 0 时不限制
+type RateLimit struct {
+	Scope    LimitScope
+	Token    string // 只对该目标端代币生效，为空时对所有代币生效
+	Window   time.Duration
+	MaxValue *big.Int
+	MaxCount uint64
+}
+
+// LimitUsage 是一条限流规则下一个限流对象在当前窗口内的用量
+type LimitUsage struct {
+	Scope     LimitScope    `json:"scope"`
+	Key       string        `json:"key"`
+	Window    time.Duration `json:"window"`
+	Value     *big.Int      `json:"value"`
+	MaxValue  *big.Int      `json:"max_value,omitempty"`
+	Count     uint64        `json:"count"`
+	MaxCount  uint64        `json:"max_count,omitempty"`
+	ValueUtil float64       `json:"value_utilization"` // 0 ~ 1，不限制时为 0
+	CountUtil float64       `json:"count_utilization"`
+}
+
+// bucket 是一个时间桶内的累计用量
+type bucket struct {
+	value *big.Int
+	count uint64
+}
+
+// RateLimiter 按跨链桥、代币和接收地址对消息金额和数量做滚动窗口限流。
+// 用量按时间桶累计并写入 store，重启后通过 Load 恢复窗口，不会因重启清零。
+// nil RateLimiter 不限流
+type RateLimiter struct {
+	path   string
+	limits []RateLimit
+	store  types.RateLimitStore // 为 nil 时只在内存中限流
+	logger *log.Logger
+	now    func() time.Time
+
+	granularity time.Duration
+	maxWindow   time.Duration
+
+	mu        sync.Mutex
+	buckets   map[string]map[int64]*bucket // 限流对象 -> 时间桶起点（Unix 纳秒） -> 用量
+	lastPrune time.Time
+}
+
+// NewRateLimiter 创建跨链桥 path 的限流器，检查限流规则
+func NewRateLimiter(path string, limits []RateLimit, store types.RateLimitStore) (*RateLimiter, error) {
+	l := &RateLimiter{
+		path:    path,
+		limits:  limits,
+		store:   store,
+		logger:  log.WithComponent("rate-limiter"),
+		now:     time.Now,
+		buckets: make(map[string]map[int64]*bucket),
+	}
+	for _, limit := range limits {
+		switch {
+		case limit.Scope != LimitBridge && limit.Scope != LimitToken && limit.Scope != LimitReceiver:
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidRateLimitConfig, limit.Scope)
+		case limit.Window <= 0:
+			return nil, fmt.Errorf("%w: %s window must be positive", ErrInvalidRateLimitConfig, limit.Scope)
+		case limit.MaxValue == nil && limit.MaxCount == 0:
+			return nil, fmt.Errorf("%w: %s limit needs max value or max count", ErrInvalidRateLimitConfig, limit.Scope)
+		case limit.MaxValue != nil && limit.MaxValue.Sign() <= 0:
+			return nil, fmt.Errorf("%w: %s max value must be positive", ErrInvalidRateLimitConfig, limit.Scope)
+		}
+		if g := limit.Window / limitBuckets; l.granularity == 0 || g < l.granularity {
+			l.granularity = g
+		}
+		l.maxWindow = max(l.maxWindow, limit.Window)
+	}
+	l.granularity = max(l.granularity, minLimitBucket)
+	return l, nil
+}
+
+// Load 从 store 恢复最长窗口内的用量
+func (l *RateLimiter) Load() error {
+	if l == nil || l.store == nil || len(l.limits) == 0 {
+		return nil
+	}
+	usage, err := l.store.ListUsage(l.now().Add(-l.maxWindow - l.granularity))
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buckets = make(map[string]map[int64]*bucket)
+	for _, u := range usage {
+		value, ok := new(big.Int).SetString(u.Value, 10)
+		if !ok {
+			return fmt.Errorf("invalid usage value %q of %s", u.Value, u.Key)
+		}
+		l.add(u.Key, u.Bucket.UnixNano(), value, u.Count)
+	}
+	return nil
+}
+
+// Reservation 是 Reserve 为一条消息记录的用量，消息最终没有提交时通过 Release 撤销
+type Reservation struct {
+	msg    InMsg
+	value  *big.Int
+	bucket time.Time
+}
+
+// Reserve 检查消息是否超出任一限流规则，未超出时记录用量并返回预留，不限流时返回 nil。
+// 超出时不记录用量，返回包装 ErrRateLimited 的错误说明超出的规则
+func (l *RateLimiter) Reserve(msg InMsg) (*Reservation, error) {
+	if l == nil || len(l.limits) == 0 {
+		return nil, nil
+	}
+	value, err := msg.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	for _, limit := range l.limits {
+		key, ok := l.key(limit, msg)
+		if !ok {
+			continue
+		}
+		used, count := l.sum(key, limit.Window, now)
+		if limit.MaxCount > 0 && count+1 > limit.MaxCount {
+			return nil, fmt.Errorf("%w: %s %s count %d/%d in %s", ErrRateLimited, limit.Scope, key, count+1, limit.MaxCount, limit.Window)
+		}
+		if limit.MaxValue != nil && used.Add(used, value).Cmp(limit.MaxValue) > 0 {
+			return nil, fmt.Errorf("%w: %s %s value %s/%s in %s", ErrRateLimited, limit.Scope, key, used, limit.MaxValue, limit.Window)
+		}
+	}
+	if err := l.record(msg, value, now); err != nil {
+		return nil, err
+	}
+	return &Reservation{msg: msg, value: value, bucket: now.Truncate(l.granularity)}, nil
+}
+
+// Release 撤销预留的用量，用于限流检查之后的步骤失败、消息没有提交的情况。r 为 nil 时不做任何事
+func (l *RateLimiter) Release(r *Reservation) error {
+	if l == nil || r == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	seen := make(map[string]bool)
+	for _, limit := range l.limits {
+		key, ok := l.key(limit, r.msg)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if l.store != nil {
+			err := l.store.ReleaseUsage(types.RateUsage{Key: key, Bucket: r.bucket, Value: r.value.String(), Count: 1})
+			if err != nil {
+				return err
+			}
+		}
+		if b, ok := l.buckets[key][r.bucket.UnixNano()]; ok {
+			b.value.Sub(b.value, r.value)
+			if b.value.Sign() < 0 {
+				b.value.SetInt64(0)
+			}
+			b.count -= min(b.count, 1)
+		}
+	}
+	return nil
+}
+
+// Record 无条件记录消息用量并返回预留，用于运维批准后放行的消息
+func (l *RateLimiter) Record(msg InMsg) (*Reservation, error) {
+	if l == nil || len(l.limits) == 0 {
+		return nil, nil
+	}
+	value, err := msg.Value()
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if err := l.record(msg, value, now); err != nil {
+		return nil, err
+	}
+	return &Reservation{msg: msg, value: value, bucket: now.Truncate(l.granularity)}, nil
+}
+
+// Utilization 返回每条限流规则下各限流对象在当前窗口内的用量
+func (l *RateLimiter) Utilization() []LimitUsage {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var out []LimitUsage
+	for _, limit := range l.limits {
+		for _, key := range l.keys(limit) {
+			value, count := l.sum(key, limit.Window, now)
+			u := LimitUsage{
+				Scope:    limit.Scope,
+				Key:      key,
+				Window:   limit.Window,
+				Value:    value,
+				MaxValue: limit.MaxValue,
+				Count:    count,
+				MaxCount: limit.MaxCount,
+			}
+			if limit.MaxValue != nil {
+				u.ValueUtil, _ = new(big.Rat).SetFrac(value, limit.MaxValue).Float64()
+			}
+			if limit.MaxCount > 0 {
+				u.CountUtil = float64(count) / float64(limit.MaxCount)
+			}
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// key 返回消息在限流规则下的限流对象，规则不适用于该消息时返回 false。
+// 指定代币的规则只累计该代币的用量，与其他规则的限流对象分开
+func (l *RateLimiter) key(limit RateLimit, msg InMsg) (string, bool) {
+	token := msg.TargetToken
+	if token == "" {
+		token = msg.Token
+	}
+	if limit.Token != "" && tokenKey(limit.Token) != tokenKey(token) {
+		return "", false
+	}
+	base := l.base(limit)
+	switch limit.Scope {
+	case LimitToken:
+		if limit.Token == "" {
+			return base + "/token/" + tokenKey(token), true
+		}
+		return base, true
+	case LimitReceiver:
+		return base + "/receiver/" + tokenKey(msg.Receiver), true
+	default:
+		return base, true
+	}
+}
+
+func (l *RateLimiter) base(limit RateLimit) string {
+	if limit.Token == "" {
+		return l.path
+	}
+	return l.path + "/token/" + tokenKey(limit.Token)
+}
+
+// keys 返回限流规则下有用量记录的限流对象，调用方需持有 l.mu
+func (l *RateLimiter) keys(limit RateLimit) []string {
+	base := l.base(limit)
+	var out []string
+	for key := range l.buckets {
+		var match bool
+		switch {
+		case limit.Scope == LimitReceiver:
+			match = strings.HasPrefix(key, base+"/receiver/")
+		case limit.Scope == LimitToken && limit.Token == "":
+			match = strings.HasPrefix(key, base+"/token/") && !strings.Contains(key, "/receiver/")
+		default:
+			match = key == base
+		}
+		if match {
+			out = append(out, key)
+		}
+	}
+	if len(out) == 0 && (limit.Scope == LimitBridge || limit.Token != "" && limit.Scope == LimitToken) {
+		out = append(out, base)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// sum 返回限流对象在 now 之前 window 内的累计用量，与窗口有重叠的时间桶全部计入，调用方需持有 l.mu
+func (l *RateLimiter) sum(key string, window time.Duration, now time.Time) (*big.Int, uint64) {
+	value, count := new(big.Int), uint64(0)
+	from := now.Add(-window - l.granularity).UnixNano()
+	for start, b := range l.buckets[key] {
+		if start > from {
+			value.Add(value, b.value)
+			count += b.count
+		}
+	}
+	return value, count
+}
+
+// record 将用量累加到消息适用的每个限流对象并持久化，调用方需持有 l.mu
+func (l *RateLimiter) record(msg InMsg, value *big.Int, now time.Time) error {
+	start := now.Truncate(l.granularity)
+	seen := make(map[string]bool)
+	for _, limit := range l.limits {
+		key, ok := l.key(limit, msg)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		if l.store != nil {
+			err := l.store.AddUsage(types.RateUsage{Key: key, Bucket: start, Value: value.String(), Count: 1})
+			if err != nil {
+				return err
+			}
+		}
+		l.add(key, start.UnixNano(), value, 1)
+	}
+	l.prune(now)
+	return nil
+}
+
+// add 累加内存中的时间桶，调用方需持有 l.mu
+func (l *RateLimiter) add(key string, start int64, value *big.Int, count uint64) {
+	buckets, ok := l.buckets[key]
+	if !ok {
+		buckets = make(map[int64]*bucket)
+		l.buckets[key] = buckets
+	}
+	b, ok := buckets[start]
+	if !ok {
+		b = &bucket{value: new(big.Int)}
+		buckets[start] = b
+	}
+	b.value.Add(b.value, value)
+	b.count += count
+}
+
+// prune 每个时间桶周期最多一次删除超出最长窗口的时间桶，调用方需持有 l.mu
+func (l *RateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < l.granularity {
+		return
+	}
+	l.lastPrune = now
+	before := now.Add(-l.maxWindow - l.granularity)
+	for key, buckets := range l.buckets {
+		for start := range buckets {
+			if start < before.UnixNano() {
+				delete(buckets, start)
+			}
+		}
+		if len(buckets) == 0 {
+			delete(l.buckets, key)
+		}
+	}
+	if l.store != nil {
+		if err := l.store.PruneUsage(l.path, before); err != nil {
+			l.logger.Warn("failed to prune rate limit usage", map[string]any{"path": l.path, "error": err})
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package relay
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// memUsage 是测试用的内存限流用量存储
+type memUsage struct {
+	usage map[string]types.RateUsage
+}
+
+func (s *memUsage) AddUsage(u types.RateUsage) error {
+	if s.usage == nil {
+		s.usage = make(map[string]types.RateUsage)
+	}
+	k := u.Key + "@" + u.Bucket.String()
+	if cur, ok := s.usage[k]; ok {
+		total := bigString(cur.Value)
+		u.Value = total.Add(total, bigString(u.Value)).String()
+		u.Count += cur.Count
+	}
+	s.usage[k] = u
+	return nil
+}
+
+func (s *memUsage) ReleaseUsage(u types.RateUsage) error {
+	k := u.Key + "@" + u.Bucket.String()
+	if cur, ok := s.usage[k]; ok {
+		total := bigString(cur.Value)
+		if total.Sub(total, bigString(u.Value)).Sign() < 0 {
+			total.SetInt64(0)
+		}
+		cur.Value = total.String()
+		cur.Count -= min(cur.Count, u.Count)
+		s.usage[k] = cur
+	}
+	return nil
+}
+
+func (s *memUsage) ListUsage(since time.Time) ([]types.RateUsage, error) {
+	var out []types.RateUsage
+	for _, u := range s.usage {
+		if !u.Bucket.Before(since) {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func (s *memUsage) PruneUsage(prefix string, before time.Time) error {
+	for k, u := range s.usage {
+		if u.Bucket.Before(before) && types.UsageKeyHasPrefix(u.Key, prefix) {
+			delete(s.usage, k)
+		}
+	}
+	return nil
+}
+
+func TestRateLimiterRollingWindow(t *testing.T) {
+	limiter, err := NewRateLimiter("bsc->tron", []RateLimit{
+		{Scope: LimitBridge, Window: time.Hour, MaxValue: big.NewInt(100)},
+		{Scope: LimitReceiver, Window: time.Hour, MaxCount: 2},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	limiter.now = func() time.Time { return now }
+
+	transfer := func(receiver, amount string) InMsg {
+		return InMsg{Receiver: receiver, TargetToken: "0xb", Amount: amount}
+	}
+	if _, err := limiter.Reserve(transfer("0xA", "60")); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	// 接收地址大小写不同视为同一地址
+	if _, err := limiter.Reserve(transfer("0xa", "30")); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	if _, err := limiter.Reserve(transfer("0xa", "1")); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected receiver count limit, got %v", err)
+	}
+	if _, err := limiter.Reserve(transfer("0xc", "11")); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected bridge value limit, got %v", err)
+	}
+	// 超出限流的消息不计入用量
+	if _, err := limiter.Reserve(transfer("0xc", "10")); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+
+	for _, u := range limiter.Utilization() {
+		if u.Scope == LimitBridge && (u.Value.String() != "100" || u.ValueUtil != 1 || u.Count != 3) {
+			t.Errorf("unexpected bridge usage %+v", u)
+		}
+	}
+
+	// 窗口滚动后用量释放
+	now = now.Add(time.Hour + 2*time.Minute)
+	if _, err := limiter.Reserve(transfer("0xa", "100")); err != nil {
+		t.Fatalf("expected window to roll over, got %v", err)
+	}
+}
+
+func TestRateLimiterTokenScope(t *testing.T) {
+	limiter, err := NewRateLimiter("bsc->tron", []RateLimit{
+		{Scope: LimitToken, Window: time.Minute, MaxValue: big.NewInt(10)},
+		{Scope: LimitToken, Token: "0xB", Window: time.Minute, MaxCount: 1},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewRateLimiter failed: %v", err)
+	}
+
+	if _, err := limiter.Reserve(InMsg{TargetToken: "0xa", Amount: "10"}); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	// 每个代币分别累计
+	if _, err := limiter.Reserve(InMsg{TargetToken: "0xb", Amount: "10"}); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	if _, err := limiter.Reserve(InMsg{TargetToken: "0xb", Amount: "0"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected token count limit, got %v", err)
+	}
+	if _, err := limiter.Reserve(InMsg{TargetToken: "0xa", Amount: "1"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected token value limit, got %v", err)
+	}
+	if got := len(limiter.Utilization()); got != 3 {
+		t.Errorf("expected 3 usage entries, got %d", got)
+	}
+}
+
+func TestRateLimiterSurvivesRestart(t *testing.T) {
+	store := &memUsage{}
+	limits := []RateLimit{{Scope: LimitBridge, Window: 24 * time.Hour, MaxValue: big.NewInt(100)}}
+	now := time.Unix(1700000000, 0)
+
+	limiter, _ := NewRateLimiter("bsc->tron", limits, store)
+	limiter.now = func() time.Time { return now }
+	if _, err := limiter.Reserve(InMsg{Amount: "80"}); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	if _, err := limiter.Record(InMsg{Amount: "50"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// 重启后从存储恢复窗口内的用量
+	now = now.Add(time.Hour)
+	restarted, _ := NewRateLimiter("bsc->tron", limits, store)
+	restarted.now = func() time.Time { return now }
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := restarted.Reserve(InMsg{Amount: "1"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected usage to survive restart, got %v", err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	if _, err := restarted.Reserve(InMsg{Amount: "100"}); err != nil {
+		t.Fatalf("expected window to roll over, got %v", err)
+	}
+}
+
+func TestRateLimitersShareStore(t *testing.T) {
+	store := &memUsage{}
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+
+	short, _ := NewRateLimiter("bsc->tron", []RateLimit{{Scope: LimitBridge, Window: time.Minute, MaxValue: big.NewInt(100)}}, store)
+	short.now = clock
+	long, _ := NewRateLimiter("bsc->tron2", []RateLimit{{Scope: LimitBridge, Window: 24 * time.Hour, MaxValue: big.NewInt(100)}}, store)
+	long.now = clock
+	if _, err := long.Record(InMsg{Amount: "90"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	// 窗口较短的限流器清理自己的时间桶时不能删除另一条桥仍在窗口内的用量
+	now = now.Add(time.Hour)
+	if _, err := short.Record(InMsg{Amount: "10"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	restarted, _ := NewRateLimiter("bsc->tron2", []RateLimit{{Scope: LimitBridge, Window: 24 * time.Hour, MaxValue: big.NewInt(100)}}, store)
+	restarted.now = clock
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := restarted.Reserve(InMsg{Amount: "20"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected usage of the other bridge to survive prune, got %v", err)
+	}
+}
+
+func TestRateLimiterRelease(t *testing.T) {
+	store := &memUsage{}
+	limits := []RateLimit{{Scope: LimitBridge, Window: time.Hour, MaxValue: big.NewInt(100), MaxCount: 2}}
+	now := time.Unix(1700000000, 0)
+
+	limiter, _ := NewRateLimiter("bsc->tron", limits, store)
+	limiter.now = func() time.Time { return now }
+	kept, _ := limiter.Reserve(InMsg{Amount: "40"})
+	released, err := limiter.Reserve(InMsg{Amount: "60"})
+	if err != nil || kept == nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+
+	// 撤销的用量不再计入窗口，内存和存储中都扣减
+	now = now.Add(time.Minute)
+	if err := limiter.Release(released); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if _, err := limiter.Reserve(InMsg{Amount: "60"}); err != nil {
+		t.Fatalf("expected released usage to be available, got %v", err)
+	}
+	restarted, _ := NewRateLimiter("bsc->tron", limits, store)
+	restarted.now = func() time.Time { return now }
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := restarted.Reserve(InMsg{Amount: "1"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected 2 reserved messages after restart, got %v", err)
+	}
+
+	var none *RateLimiter
+	if err := none.Release(nil); err != nil {
+		t.Errorf("expected nil limiter release to succeed, got %v", err)
+	}
+}
+
+func TestNewRateLimiterRejectsInvalidConfig(t *testing.T) {
+	bad := []RateLimit{
+		{Scope: "sender", Window: time.Hour, MaxCount: 1},
+		{Scope: LimitBridge, MaxCount: 1},
+		{Scope: LimitBridge, Window: time.Hour},
+		{Scope: LimitBridge, Window: time.Hour, MaxValue: big.NewInt(0)},
+	}
+	for i, limit := range bad {
+		if _, err := NewRateLimiter("bsc->tron", []RateLimit{limit}, nil); !errors.Is(err, ErrInvalidRateLimitConfig) {
+			t.Fatalf("case %d: expected ErrInvalidRateLimitConfig, got %v", i, err)
+		}
+	}
+}
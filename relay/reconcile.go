@@ -0,0 +1,392 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// ErrInvalidReconcileConfig 对账配置无效
+var ErrInvalidReconcileConfig = errors.New("invalid reconcile config")
+
+// DefaultReconcileInterval 是对账的默认间隔
+const DefaultReconcileInterval = 5 * time.Minute
+
+// ReleaseMode 目标端释放量的计算方式
+type ReleaseMode string
+
+const (
+	ReleaseSupply ReleaseMode = "supply" // 目标端铸造：释放量 = 代币总发行量 - Baseline
+	ReleaseEscrow ReleaseMode = "escrow" // 目标端从托管合约释放：释放量 = Baseline - 托管合约余额
+)
+
+// AmountReader 读取链上的代币数量，如源端托管合约的锁定余额或目标端代币的总发行量
+type AmountReader interface {
+	ReadAmount(ctx context.Context, token string) (*big.Int, error)
+}
+
+// erc20ReadABI 是 ERC20 中读取余额和总发行量的方法
+const erc20ReadABI = `[{
+	"type": "function",
+	"name": "balanceOf",
+	"stateMutability": "view",
+	"inputs": [{"name": "account", "type": "address"}],
+	"outputs": [{"name": "", "type": "uint256"}]
+}, {
+	"type": "function",
+	"name": "totalSupply",
+	"stateMutability": "view",
+	"inputs": [],
+	"outputs": [{"name": "", "type": "uint256"}]
+}]`
+
+var erc20ABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(erc20ReadABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// ERC20Balance 读取 Holder 持有的 ERC20 代币余额，如托管合约锁定的代币
+type ERC20Balance struct {
+	Caller ethereum.ContractCaller
+	Holder common.Address
+}
+
+func (b *ERC20Balance) ReadAmount(ctx context.Context, token string) (*big.Int, error) {
+	return callERC20(ctx, b.Caller, token, "balanceOf", b.Holder)
+}
+
+// ERC20Supply 读取 ERC20 代币的总发行量
+type ERC20Supply struct {
+	Caller ethereum.ContractCaller
+}
+
+func (s *ERC20Supply) ReadAmount(ctx context.Context, token string) (*big.Int, error) {
+	return callERC20(ctx, s.Caller, token, "totalSupply")
+}
+
+func callERC20(ctx context.Context, caller ethereum.ContractCaller, token, method string, args ...any) (*big.Int, error) {
+	if !common.IsHexAddress(token) {
+		return nil, fmt.Errorf("invalid token address %q", token)
+	}
+	data, err := erc20ABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	to := common.HexToAddress(token)
+	out, err := caller.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	values, err := erc20ABI.Unpack(method, out)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", token, method, err)
+	}
+	amount, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("%s %s: unexpected result %v", token, method, values[0])
+	}
+	return amount, nil
+}
+
+// ReconcileToken 是一个代币的对账参数，金额均为目标端最小单位
+type ReconcileToken struct {
+	Token
+	Tolerance *big.Int // 允许的差额，为 nil 时不允许差额
+	Baseline  *big.Int // 开始中继前已有的总发行量（supply）或托管合约的初始余额（escrow），为 nil 时为 0
+}
+
+// TokenBalance 是一个代币一次对账的结果，金额均为目标端最小单位
+type TokenBalance struct {
+	Symbol        string   `json:"symbol"`
+	Token         string   `json:"token"`            // 目标端代币地址
+	Locked        *big.Int `json:"locked,omitempty"` // 源端锁定量，按精度换算并向下取整
+	Released      *big.Int `json:"released,omitempty"`
+	Ledger        *big.Int `json:"ledger,omitempty"`    // 账本中已完成消息的累计金额，未配置账本时为空
+	InFlight      *big.Int `json:"in_flight,omitempty"` // 账本中已入队未完成消息的累计金额，未配置账本时为空
+	Discrepancies []string `json:"discrepancies,omitempty"`
+	Error         string   `json:"error,omitempty"` // 读取失败的原因
+}
+
+// ReconcileReport 是一次对账的结果，Healthy 表示没有超出容差的差额
+type ReconcileReport struct {
+	Bridge  string         `json:"bridge"`
+	Time    time.Time      `json:"time"`
+	Healthy bool           `json:"healthy"`
+	Tokens  []TokenBalance `json:"tokens"`
+}
+
+// Reconciler 定期对账跨链桥的偿付能力：读取源端托管合约的锁定量和目标端的释放量，
+// 与中继器账本中已完成消息的累计金额比较。释放量超过锁定量，或释放量与账本的差额超过容差时发出告警，
+// 配置 Pause 时发现差额后暂停跨链桥。读取失败只告警，不暂停
+type Reconciler struct {
+	Bridge   string                                         // 跨链桥名称，也是账本中的 Tunnel 路径
+	Tokens   []ReconcileToken                               // 参与对账的代币
+	Locked   AmountReader                                   // 源端锁定量，按源端代币地址读取
+	Released AmountReader                                   // 目标端释放量，按目标端代币地址读取
+	Mode     ReleaseMode                                    // 释放量的计算方式
+	Ledger   types.LedgerStore                              // 中继器账本，为 nil 时不与账本比较
+	Alerter  Alerter                                        // 告警渠道
+	Pause    func(ctx context.Context, reason string) error // 发现差额时暂停跨链桥，为 nil 时只告警
+	Interval time.Duration
+
+	logger *log.Logger
+	now    func() time.Time
+
+	mu     sync.RWMutex
+	last   *ReconcileReport
+	paused bool // 本轮异常已暂停跨链桥，恢复正常后重置
+}
+
+// NewReconciler 创建跨链桥 bridge 的对账器，检查对账参数
+func NewReconciler(bridge string, tokens []ReconcileToken, locked, released AmountReader, mode ReleaseMode) (*Reconciler, error) {
+	if locked == nil || released == nil {
+		return nil, fmt.Errorf("%w: locked and released readers are required", ErrInvalidReconcileConfig)
+	}
+	if mode != ReleaseSupply && mode != ReleaseEscrow {
+		return nil, fmt.Errorf("%w: unknown release mode %q", ErrInvalidReconcileConfig, mode)
+	}
+	for _, token := range tokens {
+		if token.Tolerance != nil && token.Tolerance.Sign() < 0 || token.Baseline != nil && token.Baseline.Sign() < 0 {
+			return nil, fmt.Errorf("%w: %s: tolerance and baseline must not be negative", ErrInvalidReconcileConfig, token.Symbol)
+		}
+	}
+	return &Reconciler{
+		Bridge:   bridge,
+		Tokens:   tokens,
+		Locked:   locked,
+		Released: released,
+		Mode:     mode,
+		Alerter:  LogAlerter{},
+		Interval: DefaultReconcileInterval,
+		logger:   log.WithComponent("reconciler"),
+		now:      time.Now,
+	}, nil
+}
+
+// Last 返回最近一次对账的结果，尚未对账时返回 nil
+func (r *Reconciler) Last() *ReconcileReport {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.last
+}
+
+// Run 每隔 Interval 对账一次，直到 ctx 取消
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		r.Reconcile(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reconcile 执行一次对账，发现差额时告警，并在本轮异常中首次发现差额时暂停跨链桥，暂停失败时下次对账重试
+func (r *Reconciler) Reconcile(ctx context.Context) *ReconcileReport {
+	report := &ReconcileReport{Bridge: r.Bridge, Time: r.now(), Healthy: true}
+
+	var totals map[string]*ledgerSum
+	var ledgerErr error
+	if r.Ledger != nil {
+		totals, ledgerErr = r.ledgerTotals()
+	}
+	for _, token := range r.Tokens {
+		balance := r.check(ctx, token, totals, ledgerErr)
+		if len(balance.Discrepancies) > 0 {
+			report.Healthy = false
+		}
+		report.Tokens = append(report.Tokens, balance)
+	}
+
+	r.mu.Lock()
+	r.last = report
+	if report.Healthy {
+		r.paused = false
+	}
+	pause := !report.Healthy && !r.paused && r.Pause != nil
+	r.mu.Unlock()
+
+	for _, balance := range report.Tokens {
+		switch {
+		case len(balance.Discrepancies) > 0:
+			r.alert(ctx, AlertCritical, "solvency discrepancy detected", balance)
+		case balance.Error != "":
+			r.alert(ctx, AlertWarning, "solvency reconciliation failed", balance)
+		}
+	}
+	if pause {
+		reason := "solvency discrepancy: " + report.summary()
+		if err := r.Pause(ctx, reason); err != nil {
+			r.logger.Error("failed to pause bridge", map[string]any{"bridge": r.Bridge, "error": err})
+			return report
+		}
+		r.logger.Warn("bridge paused by reconciler", map[string]any{"bridge": r.Bridge, "reason": reason})
+		r.mu.Lock()
+		r.paused = true
+		r.mu.Unlock()
+	}
+	return report
+}
+
+// check 对账一个代币
+func (r *Reconciler) check(ctx context.Context, token ReconcileToken, totals map[string]*ledgerSum, ledgerErr error) TokenBalance {
+	balance := TokenBalance{Symbol: token.Symbol, Token: token.Target}
+
+	locked, err := r.Locked.ReadAmount(ctx, token.Source)
+	if err != nil {
+		balance.Error = fmt.Sprintf("read locked: %v", err)
+		return balance
+	}
+	balance.Locked = scaleFloor(token.Token, locked)
+
+	reading, err := r.Released.ReadAmount(ctx, token.Target)
+	if err != nil {
+		balance.Error = fmt.Sprintf("read released: %v", err)
+		return balance
+	}
+	baseline := new(big.Int)
+	if token.Baseline != nil {
+		baseline.Set(token.Baseline)
+	}
+	if r.Mode == ReleaseEscrow {
+		balance.Released = baseline.Sub(baseline, reading)
+	} else {
+		balance.Released = reading.Sub(reading, baseline)
+	}
+
+	tolerance := new(big.Int)
+	if token.Tolerance != nil {
+		tolerance.Set(token.Tolerance)
+	}
+	if diff := new(big.Int).Sub(balance.Released, balance.Locked); diff.Cmp(tolerance) > 0 {
+		balance.Discrepancies = append(balance.Discrepancies,
+			fmt.Sprintf("released %s exceeds locked %s by %s", balance.Released, balance.Locked, diff))
+	}
+
+	switch {
+	case r.Ledger == nil:
+	case ledgerErr != nil:
+		balance.Error = fmt.Sprintf("read ledger: %v", ledgerErr)
+	default:
+		balance.Ledger, balance.InFlight = new(big.Int), new(big.Int)
+		if total, ok := totals[tokenKey(token.Target)]; ok {
+			balance.Ledger.Set(total.completed)
+			balance.InFlight.Set(total.inflight)
+		}
+		// 在途消息可能已在目标端释放但尚未确认，释放量落在 [已完成, 已完成+在途] 之间不算差额
+		if diff := new(big.Int).Sub(balance.Released, balance.Ledger); diff.Sign() < 0 && diff.CmpAbs(tolerance) > 0 {
+			balance.Discrepancies = append(balance.Discrepancies,
+				fmt.Sprintf("released %s is below ledger %s by %s", balance.Released, balance.Ledger, diff.Neg(diff)))
+		} else if diff.Sub(diff, balance.InFlight).Cmp(tolerance) > 0 {
+			balance.Discrepancies = append(balance.Discrepancies,
+				fmt.Sprintf("released %s exceeds ledger %s and in-flight %s by %s", balance.Released, balance.Ledger, balance.InFlight, diff))
+		}
+	}
+	return balance
+}
+
+// ledgerSum 是账本中一种目标端代币的累计金额
+type ledgerSum struct {
+	completed *big.Int // 已完成消息
+	inflight  *big.Int // 已入队未完成消息
+}
+
+// ledgerTotals 按目标端代币返回账本中已完成消息和在途消息的累计金额
+func (r *Reconciler) ledgerTotals() (map[string]*ledgerSum, error) {
+	list, err := r.Ledger.LedgerTotals(r.Bridge)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[string]*ledgerSum, len(list))
+	for _, t := range list {
+		amount, ok := new(big.Int).SetString(t.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid ledger total %q of %s", t.Amount, t.Token)
+		}
+		inflight := new(big.Int)
+		if t.InFlight != "" {
+			if _, ok := inflight.SetString(t.InFlight, 10); !ok {
+				return nil, fmt.Errorf("invalid ledger in-flight total %q of %s", t.InFlight, t.Token)
+			}
+		}
+		key := tokenKey(t.Token)
+		if totals[key] == nil {
+			totals[key] = &ledgerSum{completed: new(big.Int), inflight: new(big.Int)}
+		}
+		totals[key].completed.Add(totals[key].completed, amount)
+		totals[key].inflight.Add(totals[key].inflight, inflight)
+	}
+	return totals, nil
+}
+
+func (r *Reconciler) alert(ctx context.Context, severity AlertSeverity, message string, balance TokenBalance) {
+	if r.Alerter == nil {
+		return
+	}
+	fields := map[string]any{
+		"symbol":   balance.Symbol,
+		"token":    balance.Token,
+		"locked":   balance.Locked,
+		"released": balance.Released,
+	}
+	if balance.Ledger != nil {
+		fields["ledger"] = balance.Ledger
+		fields["in_flight"] = balance.InFlight
+	}
+	if len(balance.Discrepancies) > 0 {
+		fields["discrepancies"] = balance.Discrepancies
+	}
+	if balance.Error != "" {
+		fields["error"] = balance.Error
+	}
+	err := r.Alerter.Alert(ctx, Alert{
+		Time:     r.now(),
+		Severity: severity,
+		Source:   "reconciler",
+		Bridge:   r.Bridge,
+		Message:  message,
+		Fields:   fields,
+	})
+	if err != nil {
+		r.logger.Error("failed to send alert", map[string]any{"bridge": r.Bridge, "error": err})
+	}
+}
+
+// summary 返回差额的简要说明
+func (rep *ReconcileReport) summary() string {
+	var parts []string
+	for _, balance := range rep.Tokens {
+		for _, d := range balance.Discrepancies {
+			parts = append(parts, balance.Symbol+" "+d)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// scaleFloor 将源端最小单位的金额换算为目标端最小单位，目标端精度较低时向下取整
+func scaleFloor(t Token, amount *big.Int) *big.Int {
+	if t.TargetDecimals >= t.SourceDecimals {
+		return new(big.Int).Mul(amount, pow10(t.TargetDecimals-t.SourceDecimals))
+	}
+	return new(big.Int).Quo(amount, pow10(t.SourceDecimals-t.TargetDecimals))
+}
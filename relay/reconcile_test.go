@@ -0,0 +1,262 @@
+package relay
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// memLedger 是测试用的内存账本
+type memLedger map[string]types.LedgerEntry
+
+func (l memLedger) SaveLedgerEntry(e types.LedgerEntry) error {
+	l[e.ID] = e
+	return nil
+}
+
+func (l memLedger) GetLedgerEntry(id string) (*types.LedgerEntry, error) {
+	e, ok := l[id]
+	if !ok {
+		return nil, nil
+	}
+	return &e, nil
+}
+
+func (l memLedger) LedgerTotals(bridge string) ([]types.LedgerTotal, error) {
+	totals := make(map[string]*types.LedgerTotal)
+	for _, e := range l {
+		if e.Bridge != bridge {
+			continue
+		}
+		t, ok := totals[e.Token]
+		if !ok {
+			t = &types.LedgerTotal{Bridge: bridge, Token: e.Token, Amount: "0", InFlight: "0"}
+			totals[e.Token] = t
+		}
+		if !e.Completed {
+			sum := bigString(t.InFlight)
+			t.InFlight = sum.Add(sum, bigString(e.Amount)).String()
+			continue
+		}
+		sum := bigString(t.Amount)
+		t.Amount = sum.Add(sum, bigString(e.Amount)).String()
+		t.Count++
+	}
+	var out []types.LedgerTotal
+	for _, t := range totals {
+		out = append(out, *t)
+	}
+	return out, nil
+}
+
+// fakeAmounts 按代币地址返回固定数量
+type fakeAmounts map[string]*big.Int
+
+func (a fakeAmounts) ReadAmount(ctx context.Context, token string) (*big.Int, error) {
+	return new(big.Int).Set(a[token]), nil
+}
+
+// recordingAlerter 记录发出的告警
+type recordingAlerter []Alert
+
+func (a *recordingAlerter) Alert(ctx context.Context, alert Alert) error {
+	*a = append(*a, alert)
+	return nil
+}
+
+func TestReconcilerDetectsDiscrepancies(t *testing.T) {
+	usdt := Token{Symbol: "USDT", Source: "0xa", Target: "0xb", SourceDecimals: 18, TargetDecimals: 6}
+	locked := fakeAmounts{"0xa": bigString("1000000000000000000000")} // 1000 USDT
+	supply := fakeAmounts{"0xb": big.NewInt(5_000_000_000)}           // 基线 4000 + 已释放 1000
+	ledger := memLedger{
+		"0x01": {ID: "0x01", Bridge: "bsc->tron", Token: "0xB", Amount: "999000000", Completed: true},
+		"0x02": {ID: "0x02", Bridge: "bsc->tron", Token: "0xb", Amount: "5000000"},
+	}
+
+	reconciler, err := NewReconciler("bsc->tron", []ReconcileToken{{
+		Token:     usdt,
+		Tolerance: big.NewInt(1_000_000),
+		Baseline:  big.NewInt(4_000_000_000),
+	}}, locked, supply, ReleaseSupply)
+	if err != nil {
+		t.Fatalf("NewReconciler failed: %v", err)
+	}
+	alerts := &recordingAlerter{}
+	reconciler.Ledger, reconciler.Alerter = ledger, alerts
+	var pauses []string
+	reconciler.Pause = func(ctx context.Context, reason string) error {
+		pauses = append(pauses, reason)
+		return nil
+	}
+	ctx := context.Background()
+
+	// 差额在容差内，未完成的消息计入在途金额
+	report := reconciler.Reconcile(ctx)
+	balance := report.Tokens[0]
+	if !report.Healthy || balance.Locked.String() != "1000000000" || balance.Released.String() != "1000000000" ||
+		balance.Ledger.String() != "999000000" || balance.InFlight.String() != "5000000" {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+
+	// 在途消息已在目标端释放但尚未确认时不算差额
+	locked["0xa"] = bigString("1005000000000000000000")
+	supply["0xb"] = big.NewInt(5_004_000_000)
+	if report = reconciler.Reconcile(ctx); !report.Healthy {
+		t.Fatalf("Expected in-flight release to be healthy, got %+v", report)
+	}
+	if len(*alerts) != 0 || len(pauses) != 0 {
+		t.Fatalf("Expected no alerts or pauses, got %+v, %v", *alerts, pauses)
+	}
+	locked["0xa"] = bigString("1000000000000000000000")
+
+	// 目标端多铸造时告警并暂停一次
+	supply["0xb"] = big.NewInt(5_010_000_000)
+	report = reconciler.Reconcile(ctx)
+	if report.Healthy || len(report.Tokens[0].Discrepancies) != 2 {
+		t.Fatalf("Expected 2 discrepancies, got %+v", report)
+	}
+	reconciler.Reconcile(ctx)
+	if len(pauses) != 1 || !strings.Contains(pauses[0], "USDT released 1010000000 exceeds locked 1000000000") {
+		t.Fatalf("Expected one pause, got %v", pauses)
+	}
+	if len(*alerts) != 2 || (*alerts)[0].Severity != AlertCritical || (*alerts)[0].Bridge != "bsc->tron" {
+		t.Fatalf("Unexpected alerts %+v", *alerts)
+	}
+	if last := reconciler.Last(); last == nil || last.Healthy {
+		t.Errorf("Expected last report to be unhealthy, got %+v", last)
+	}
+
+	// 恢复正常后再次出现差额时重新暂停
+	supply["0xb"] = big.NewInt(5_000_000_000)
+	reconciler.Reconcile(ctx)
+	locked["0xa"] = bigString("900000000000000000000")
+	reconciler.Reconcile(ctx)
+	if len(pauses) != 2 || !strings.Contains(pauses[1], "exceeds locked 900000000") {
+		t.Fatalf("Expected second pause, got %v", pauses)
+	}
+}
+
+func TestReconcilerEscrowMode(t *testing.T) {
+	token := ReconcileToken{
+		Token:    Token{Symbol: "USDT", Source: "0xa", Target: "0xb", SourceDecimals: 6, TargetDecimals: 18},
+		Baseline: bigString("10000000000000000000000"), // 托管合约初始余额 10000
+	}
+	locked := fakeAmounts{"0xa": big.NewInt(500_000_000)}             // 500
+	escrow := fakeAmounts{"0xb": bigString("9400000000000000000000")} // 已释放 600
+	reconciler, _ := NewReconciler("bsc->tron", []ReconcileToken{token}, locked, escrow, ReleaseEscrow)
+	reconciler.Alerter = nil
+
+	report := reconciler.Reconcile(context.Background())
+	balance := report.Tokens[0]
+	if report.Healthy || balance.Released.String() != "600000000000000000000" || balance.Ledger != nil {
+		t.Fatalf("Expected escrow release to exceed locked, got %+v", report)
+	}
+}
+
+// fakeERC20 按方法返回固定的 uint256
+type fakeERC20 struct {
+	balance, supply *big.Int
+	holder          common.Address
+}
+
+func (c *fakeERC20) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := erc20ABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	if method.Name == "totalSupply" {
+		return method.Outputs.Pack(c.supply)
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+	if args[0].(common.Address) != c.holder {
+		return method.Outputs.Pack(new(big.Int))
+	}
+	return method.Outputs.Pack(c.balance)
+}
+
+func TestERC20Readers(t *testing.T) {
+	holder := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	caller := &fakeERC20{balance: big.NewInt(42), supply: big.NewInt(1000), holder: holder}
+	token := "0x0000000000000000000000000000000000000001"
+	ctx := context.Background()
+
+	if amount, err := (&ERC20Balance{Caller: caller, Holder: holder}).ReadAmount(ctx, token); err != nil || amount.Int64() != 42 {
+		t.Fatalf("Expected balance 42, got %v, %v", amount, err)
+	}
+	if amount, err := (&ERC20Supply{Caller: caller}).ReadAmount(ctx, token); err != nil || amount.Int64() != 1000 {
+		t.Fatalf("Expected supply 1000, got %v, %v", amount, err)
+	}
+	if _, err := (&ERC20Supply{Caller: caller}).ReadAmount(ctx, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"); err == nil {
+		t.Fatal("Expected error for non-hex token address")
+	}
+}
+
+func TestInTunnelRecordsLedger(t *testing.T) {
+	ledger := memLedger{}
+	tunnel := NewInTunnel(&fakeSource{}, &fakeTarget{}, fakeSigner{}, nil)
+	tunnel.States = NewStateMachine(memStates{})
+	tunnel.ErrorHandler = nil
+	tunnel.Queue = NewQueue[InMsg](0, 16)
+	tunnel.Ledger = ledger
+
+	msg := InMsg{Nonce: 1, ChainID: "56", TxHash: testTxHash, Token: "0xa", Amount: "100"}
+	tunnel.Queue.Push(msg)
+	if ok, err := tunnel.admit(context.Background(), &msg); !ok || err != nil {
+		t.Fatalf("Expected message to be admitted, got %v, %v", ok, err)
+	}
+	id, _ := msg.ID()
+	if e := ledger[id]; e.Completed || e.Amount != "100" || e.Token != "0xa" || e.Bridge != tunnel.Path {
+		t.Fatalf("Expected pending ledger entry, got %+v", e)
+	}
+
+	if err := tunnel.complete(1); err != nil {
+		t.Fatalf("complete failed: %v", err)
+	}
+	if !ledger[id].Completed {
+		t.Fatalf("Expected completed ledger entry, got %+v", ledger[id])
+	}
+}
+
+func TestInTunnelSettlesLedgerAfterRestart(t *testing.T) {
+	ledger := memLedger{}
+	store := newMemStore()
+	msg := InMsg{Nonce: 1, ChainID: "56", TxHash: testTxHash, Token: "0xa", Amount: "100"}
+	first := NewInTunnel(&fakeSource{}, &fakeTarget{}, fakeSigner{}, nil)
+	first.ErrorHandler = nil
+	first.Ledger = ledger
+	var err error
+	if first.Queue, err = NewDurableQueue[InMsg]("test", store, 16); err != nil {
+		t.Fatalf("NewDurableQueue failed: %v", err)
+	}
+	first.Queue.Push(msg)
+	if ok, err := first.admit(context.Background(), &msg); !ok || err != nil {
+		t.Fatalf("Expected message to be admitted, got %v, %v", ok, err)
+	}
+
+	// 重启后确认循环先于重新投递的消息确认 nonce
+	second := NewInTunnel(&fakeSource{}, &fakeTarget{}, fakeSigner{}, nil)
+	second.ErrorHandler = nil
+	second.Ledger = ledger
+	if second.Queue, err = NewDurableQueue[InMsg]("test", store, 16); err != nil {
+		t.Fatalf("NewDurableQueue failed: %v", err)
+	}
+	if err := second.complete(1); err != nil {
+		t.Fatalf("complete failed: %v", err)
+	}
+	id, _ := msg.ID()
+	if !ledger[id].Completed {
+		t.Fatalf("Expected ledger entry to settle after restart, got %+v", ledger[id])
+	}
+	if _, ok := second.Queue.Redelivered(1); ok {
+		t.Error("Expected acked message to be forgotten")
+	}
+}
@@ -124,7 +124,7 @@ func (r *Relay) processTargetMessages() {
 			go func(msg *OutMsg, allocatedNonce uint64) {
 				// 这将由目标端点处理
 				// 目标端将通过MarkSubmitted回调交易哈希
-				if err := r.Target.ProcessInMsgs(make(chan InMsg)); err != nil {
+				if _, err := r.Target.ProcessInMsgs(make(chan InMsg)); err != nil {
 					r.NonceManager.MarkFailed(allocatedNonce)
 				}
 			}(outMsg, nonce)
@@ -188,7 +188,7 @@ func (r *Relay) GetStatus() map[string]interface{} {
 	return map[string]interface{}{
 		"current_nonce":  r.NonceManager.GetCurrentNonce(),
 		"pending_count":  r.NonceManager.GetPendingCount(),
-		"in_chan_len":    len(r.InQueue),
+		"in_queue":       r.InQueue.Metrics(),
 		"out_chan_len":   len(r.outChan),
 		"batch_chan_len": len(r.batchChan),
 	}
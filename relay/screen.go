@@ -0,0 +1,247 @@
+package relay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// 地址筛查错误
+var (
+	ErrAddressBlocked         = errors.New("address blocked by screening")
+	ErrScreeningFailed        = errors.New("address screening failed")
+	ErrInvalidScreeningConfig = errors.New("invalid screening config")
+)
+
+func init() {
+	// 未配置挂起队列时被拦截的消息交给死信由运维处理
+	types.RegisterClass(ErrAddressBlocked, types.ClassFatal)
+	// 筛查服务不可用时不放行，等待服务恢复后重试
+	types.RegisterClass(ErrScreeningFailed, types.ClassRetryable)
+}
+
+// DefaultDenylistInterval 是检查本地黑名单文件是否修改的间隔
+const DefaultDenylistInterval = 10 * time.Second
+
+// Screener 检查地址是否受制裁或被禁止跨链，地址被拦截时返回原因，未拦截时返回空字符串
+type Screener interface {
+	Screen(ctx context.Context, address string) (string, error)
+}
+
+// Screeners 依次调用多个 Screener，任一拦截即拦截
+type Screeners []Screener
+
+func (s Screeners) Screen(ctx context.Context, address string) (string, error) {
+	for _, screener := range s {
+		reason, err := screener.Screen(ctx, address)
+		if err != nil || reason != "" {
+			return reason, err
+		}
+	}
+	return "", nil
+}
+
+// ScreenMsg 筛查消息的发送和接收地址，返回第一个被拦截地址的原因
+func ScreenMsg(ctx context.Context, screener Screener, msg InMsg) (string, error) {
+	if screener == nil {
+		return "", nil
+	}
+	for _, party := range []struct{ role, address string }{
+		{"sender", msg.Sender},
+		{"receiver", msg.Receiver},
+	} {
+		if party.address == "" {
+			continue
+		}
+		reason, err := screener.Screen(ctx, party.address)
+		if err != nil {
+			return "", err
+		}
+		if reason != "" {
+			return fmt.Sprintf("%s %s: %s", party.role, party.address, reason), nil
+		}
+	}
+	return "", nil
+}
+
+// Denylist 是本地黑名单文件，每行一个地址，# 之后为拦截原因，空行和以 # 开头的行忽略：
+//
+//	0x8589427373D6D84E98730D7795D8f6f8731FDA16 # OFAC SDN
+//
+// Screen 每隔 Interval 检查一次文件修改时间，文件变化后重新加载；加载失败时保留原名单
+type Denylist struct {
+	Path     string
+	Interval time.Duration
+
+	logger *log.Logger
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	entries   map[string]string // 地址 -> 拦截原因
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewDenylist 加载黑名单文件
+func NewDenylist(path string) (*Denylist, error) {
+	d := &Denylist{
+		Path:     path,
+		Interval: DefaultDenylistInterval,
+		logger:   log.WithComponent("denylist"),
+		now:      time.Now,
+	}
+	if err := d.Reload(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reload 重新读取黑名单文件
+func (d *Denylist) Reload() error {
+	info, err := os.Stat(d.Path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(d.Path)
+	if err != nil {
+		return err
+	}
+	entries, err := parseDenylist(data)
+	if err != nil {
+		return fmt.Errorf("denylist %s: %w", d.Path, err)
+	}
+
+	d.mu.Lock()
+	d.entries, d.modTime, d.lastCheck = entries, info.ModTime(), d.now()
+	d.mu.Unlock()
+	d.logger.Info("denylist loaded", map[string]any{"path": d.Path, "entries": len(entries)})
+	return nil
+}
+
+func (d *Denylist) Screen(ctx context.Context, address string) (string, error) {
+	d.refresh()
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	reason, ok := d.entries[tokenKey(address)]
+	if !ok {
+		return "", nil
+	}
+	if reason == "" {
+		reason = "listed in " + d.Path
+	}
+	return reason, nil
+}
+
+// Len 返回黑名单中的地址数
+func (d *Denylist) Len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.entries)
+}
+
+// refresh 距上次检查超过 Interval 且文件已修改时重新加载
+func (d *Denylist) refresh() {
+	d.mu.Lock()
+	now := d.now()
+	if now.Sub(d.lastCheck) < d.Interval {
+		d.mu.Unlock()
+		return
+	}
+	d.lastCheck = now
+	modTime := d.modTime
+	d.mu.Unlock()
+
+	info, err := os.Stat(d.Path)
+	if err != nil {
+		d.logger.Warn("failed to stat denylist", map[string]any{"path": d.Path, "error": err})
+		return
+	}
+	if info.ModTime().Equal(modTime) {
+		return
+	}
+	if err := d.Reload(); err != nil {
+		d.logger.Error("failed to reload denylist, keeping previous entries", map[string]any{"path": d.Path, "error": err})
+	}
+}
+
+func parseDenylist(data []byte) (map[string]string, error) {
+	entries := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text, comment, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		switch len(fields) {
+		case 0:
+			continue
+		case 1:
+			entries[tokenKey(fields[0])] = strings.TrimSpace(comment)
+		default:
+			return nil, fmt.Errorf("line %d: expected one address, got %q", line, strings.TrimSpace(text))
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// HTTPScreener 调用外部筛查服务，请求为 POST {"address": "0x..."}，
+// 响应为 {"blocked": true, "reason": "OFAC SDN"}。服务不可用或响应异常时返回 ErrScreeningFailed
+type HTTPScreener struct {
+	URL    string
+	Header http.Header // 附加的请求头，如服务的 API 密钥
+	Client *http.Client
+}
+
+func (s *HTTPScreener) Screen(ctx context.Context, address string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"address": address})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrScreeningFailed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: %s: unexpected status %s", ErrScreeningFailed, s.URL, resp.Status)
+	}
+
+	var body struct {
+		Blocked *bool  `json:"blocked"`
+		Reason  string `json:"reason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrScreeningFailed, s.URL, err)
+	}
+	if body.Blocked == nil {
+		return "", fmt.Errorf("%w: %s: missing blocked field", ErrScreeningFailed, s.URL)
+	}
+	if !*body.Blocked {
+		return "", nil
+	}
+	if body.Reason == "" {
+		body.Reason = "blocked by " + s.URL
+	}
+	return body.Reason, nil
+}
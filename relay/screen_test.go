@@ -0,0 +1,152 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+func TestDenylistReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "denylist.txt")
+	if err := os.WriteFile(path, []byte("# sanctioned\n0xAbC # OFAC SDN\n\n0xdef\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	denylist, err := NewDenylist(path)
+	if err != nil {
+		t.Fatalf("NewDenylist failed: %v", err)
+	}
+	now := time.Now()
+	denylist.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// 地址大小写不同视为同一地址
+	if reason, _ := denylist.Screen(ctx, "0xabc"); reason != "OFAC SDN" {
+		t.Errorf("Expected OFAC SDN, got %q", reason)
+	}
+	if reason, _ := denylist.Screen(ctx, "0xDEF"); reason != "listed in "+path {
+		t.Errorf("Expected default reason, got %q", reason)
+	}
+	if reason, _ := denylist.Screen(ctx, "0x123"); reason != "" {
+		t.Errorf("Expected 0x123 to pass, got %q", reason)
+	}
+
+	// 文件修改后在下次检查时重新加载
+	if err := os.WriteFile(path, []byte("0x123 # added\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, now, now.Add(time.Minute))
+	if reason, _ := denylist.Screen(ctx, "0x123"); reason != "" {
+		t.Errorf("Expected reload to wait for the interval, got %q", reason)
+	}
+	now = now.Add(DefaultDenylistInterval)
+	if reason, _ := denylist.Screen(ctx, "0x123"); reason != "added" {
+		t.Errorf("Expected reloaded entry, got %q", reason)
+	}
+	if reason, _ := denylist.Screen(ctx, "0xabc"); reason != "" {
+		t.Errorf("Expected removed entry to pass, got %q", reason)
+	}
+
+	// 加载失败时保留原名单
+	if err := os.WriteFile(path, []byte("0x1 0x2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, now, now.Add(2*time.Minute))
+	now = now.Add(DefaultDenylistInterval)
+	if reason, _ := denylist.Screen(ctx, "0x123"); reason != "added" || denylist.Len() != 1 {
+		t.Errorf("Expected previous entries to be kept, got %q, %d entries", reason, denylist.Len())
+	}
+}
+
+func TestHTTPScreener(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-API-Key") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if status != http.StatusOK {
+			http.Error(w, "unavailable", status)
+			return
+		}
+		var req struct {
+			Address string `json:"address"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.Address {
+		case "0xbad":
+			w.Write([]byte(`{"blocked": true, "reason": "sanctioned entity"}`))
+		case "0xodd":
+			w.Write([]byte(`{"reason": "unknown"}`))
+		default:
+			w.Write([]byte(`{"blocked": false}`))
+		}
+	}))
+	defer srv.Close()
+	screener := &HTTPScreener{URL: srv.URL, Header: http.Header{"X-Api-Key": {"secret"}}}
+	ctx := context.Background()
+
+	if reason, err := screener.Screen(ctx, "0xbad"); err != nil || reason != "sanctioned entity" {
+		t.Fatalf("Expected blocked address, got %q, %v", reason, err)
+	}
+	if reason, err := screener.Screen(ctx, "0xgood"); err != nil || reason != "" {
+		t.Fatalf("Expected clear address, got %q, %v", reason, err)
+	}
+	if _, err := screener.Screen(ctx, "0xodd"); !errors.Is(err, ErrScreeningFailed) {
+		t.Fatalf("Expected ErrScreeningFailed for malformed response, got %v", err)
+	}
+	// 服务不可用时不放行
+	status = http.StatusServiceUnavailable
+	if _, err := screener.Screen(ctx, "0xgood"); !errors.Is(err, ErrScreeningFailed) || types.Classify(err) != types.ClassRetryable {
+		t.Fatalf("Expected retryable ErrScreeningFailed, got %v", err)
+	}
+}
+
+// staticScreener 拦截固定的地址
+type staticScreener map[string]string
+
+func (s staticScreener) Screen(ctx context.Context, address string) (string, error) {
+	return s[address], nil
+}
+
+func TestInTunnelHoldsBlockedAddresses(t *testing.T) {
+	states := memStates{}
+	tunnel := NewInTunnel(&fakeSource{}, &fakeTarget{}, fakeSigner{}, nil)
+	tunnel.States = NewStateMachine(states)
+	tunnel.ErrorHandler = nil
+	tunnel.Queue = NewQueue[InMsg](0, 16)
+	tunnel.Holds = NewHoldQueue(newMemHolds())
+	tunnel.Holds.Register(tunnel.Path, tunnel.release)
+	tunnel.Screener = Screeners{staticScreener{}, staticScreener{"0xbad": "OFAC SDN"}}
+
+	msg := func(logIndex uint, sender, receiver string) InMsg {
+		return InMsg{Nonce: uint64(logIndex), ChainID: "56", TxHash: testTxHash, LogIndex: logIndex, Sender: sender, Receiver: receiver, Amount: "100"}
+	}
+	ctx := context.Background()
+
+	clean := msg(1, "0xa", "0xb")
+	if ok, err := tunnel.admit(ctx, &clean); !ok || err != nil {
+		t.Fatalf("Expected clean message to be admitted, got %v, %v", ok, err)
+	}
+
+	blocked := msg(2, "0xa", "0xbad")
+	if ok, err := tunnel.admit(ctx, &blocked); ok || err != nil {
+		t.Fatalf("Expected blocked message to be held, got %v, %v", ok, err)
+	}
+	id, _ := blocked.ID()
+	held, err := tunnel.Holds.Get(id)
+	if err != nil || !strings.HasPrefix(held.Reason, ErrAddressBlocked.Error()) || !strings.HasSuffix(held.Reason, "receiver 0xbad: OFAC SDN") {
+		t.Fatalf("Unexpected hold %+v, %v", held, err)
+	}
+	if states[id].State != types.MsgStateHeld {
+		t.Errorf("Expected held state, got %+v", states[id])
+	}
+}
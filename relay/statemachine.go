@@ -0,0 +1,125 @@
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// ErrInvalidTransition 消息状态转换不合法
+var ErrInvalidTransition = errors.New("invalid message state transition")
+
+// TransitionHook 在状态转换持久化后被调用，用于指标、webhook 等。
+// 回调在转换方的协程中同步执行，不应阻塞
+type TransitionHook func(tr types.MessageTransition)
+
+// StateMachine 是跨链消息生命周期的状态机，按确定性消息 ID 记录每条消息的当前状态和每次状态转换。
+// 转换前按 types.CanTransition 校验，转换持久化后通知订阅的回调
+type StateMachine struct {
+	store  types.StateStore
+	logger *log.Logger
+
+	mu sync.Mutex // 串行化状态的读取和更新
+
+	hookMu   sync.RWMutex
+	hooks    map[uint64]TransitionHook
+	nextHook uint64
+}
+
+func NewStateMachine(store types.StateStore) *StateMachine {
+	return &StateMachine{
+		store:  store,
+		logger: log.WithComponent("state-machine"),
+		hooks:  make(map[uint64]TransitionHook),
+	}
+}
+
+// Get 返回消息的当前记录，不存在时返回 nil
+func (m *StateMachine) Get(id string) (*types.MessageRecord, error) {
+	return m.store.GetRecord(id)
+}
+
+// History 按时间返回消息的状态转换
+func (m *StateMachine) History(id string) ([]types.MessageTransition, error) {
+	return m.store.ListTransitions(id)
+}
+
+// Subscribe 订阅状态转换，返回取消订阅的函数
+func (m *StateMachine) Subscribe(hook TransitionHook) func() {
+	m.hookMu.Lock()
+	defer m.hookMu.Unlock()
+	id := m.nextHook
+	m.nextHook++
+	m.hooks[id] = hook
+	return func() {
+		m.hookMu.Lock()
+		defer m.hookMu.Unlock()
+		delete(m.hooks, id)
+	}
+}
+
+// Transition 将消息转换到 rec.State，detail 记录转换原因。
+// 消息已处于目标状态时不做任何操作；转换不合法时返回 ErrInvalidTransition
+func (m *StateMachine) Transition(rec types.MessageRecord, detail string) error {
+	m.mu.Lock()
+	cur, err := m.store.GetRecord(rec.ID)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	from := types.MsgStateUnknown
+	if cur != nil {
+		from = cur.State
+	}
+	if from == rec.State {
+		m.mu.Unlock()
+		return nil
+	}
+	if !types.CanTransition(from, rec.State) {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s %q -> %q", ErrInvalidTransition, rec.ID, from, rec.State)
+	}
+
+	tr := types.MessageTransition{
+		ID:     rec.ID,
+		Queue:  rec.Queue,
+		Nonce:  rec.Nonce,
+		From:   from,
+		To:     rec.State,
+		TxHash: rec.TxHash,
+		Detail: detail,
+		At:     time.Now(),
+	}
+	rec.UpdatedAt = tr.At
+	err = m.store.SaveTransition(rec, tr)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	m.logger.Debug("message state changed", map[string]any{
+		"id":    tr.ID,
+		"nonce": tr.Nonce,
+		"from":  tr.From,
+		"to":    tr.To,
+	})
+	m.notify(tr)
+	return nil
+}
+
+func (m *StateMachine) notify(tr types.MessageTransition) {
+	m.hookMu.RLock()
+	hooks := make([]TransitionHook, 0, len(m.hooks))
+	for _, hook := range m.hooks {
+		hooks = append(hooks, hook)
+	}
+	m.hookMu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(tr)
+	}
+}
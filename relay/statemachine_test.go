@@ -0,0 +1,73 @@
+package relay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// historyStates 在内存状态存储的基础上记录状态转换
+type historyStates struct {
+	memStates
+	history []types.MessageTransition
+}
+
+func (s *historyStates) SaveTransition(rec types.MessageRecord, tr types.MessageTransition) error {
+	s.memStates[rec.ID] = rec
+	s.history = append(s.history, tr)
+	return nil
+}
+
+func (s *historyStates) ListTransitions(id string) ([]types.MessageTransition, error) {
+	var out []types.MessageTransition
+	for _, tr := range s.history {
+		if tr.ID == id {
+			out = append(out, tr)
+		}
+	}
+	return out, nil
+}
+
+func TestStateMachineTransitions(t *testing.T) {
+	store := &historyStates{memStates: memStates{}}
+	m := NewStateMachine(store)
+
+	var seen []types.MessageState
+	unsubscribe := m.Subscribe(func(tr types.MessageTransition) { seen = append(seen, tr.To) })
+
+	rec := func(state types.MessageState) types.MessageRecord {
+		return types.MessageRecord{ID: "0x01", Queue: "bsc->tron", Nonce: 1, State: state}
+	}
+	for _, state := range []types.MessageState{types.MsgStateObserved, types.MsgStateQueued, types.MsgStateSubmitted} {
+		if err := m.Transition(rec(state), ""); err != nil {
+			t.Fatalf("Failed to transition to %s: %v", state, err)
+		}
+	}
+	// 重复转换到当前状态不记录
+	if err := m.Transition(rec(types.MsgStateSubmitted), ""); err != nil {
+		t.Fatalf("Expected repeated transition to be ignored, got %v", err)
+	}
+	if err := m.Transition(rec(types.MsgStateObserved), ""); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, got %v", err)
+	}
+
+	unsubscribe()
+	if err := m.Transition(rec(types.MsgStateCompleted), "done"); err != nil {
+		t.Fatalf("Failed to complete: %v", err)
+	}
+
+	if len(seen) != 3 || seen[2] != types.MsgStateSubmitted {
+		t.Errorf("Unexpected hook notifications %v", seen)
+	}
+	history, _ := m.History("0x01")
+	if len(history) != 4 {
+		t.Fatalf("Expected 4 transitions, got %+v", history)
+	}
+	if last := history[3]; last.From != types.MsgStateSubmitted || last.To != types.MsgStateCompleted || last.Detail != "done" {
+		t.Errorf("Unexpected transition %+v", last)
+	}
+	if got, _ := m.Get("0x01"); got == nil || got.State != types.MsgStateCompleted {
+		t.Errorf("Expected completed record, got %+v", got)
+	}
+}
@@ -0,0 +1,305 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+)
+
+// ErrTunnelRunning Tunnel 已在运行
+var ErrTunnelRunning = errors.New("tunnel already running")
+
+// TunnelState 表示 Tunnel 的运行状态
+type TunnelState int
+
+const (
+	StateStarting TunnelState = iota // 正在初始化
+	StateRunning                     // 所有子任务正常运行
+	StateDegraded                    // 存在失败后等待重启的子任务
+	StateDraining                    // 已停止接收新消息，等待在途消息完成
+	StateStopped                     // 已停止
+)
+
+func (s TunnelState) String() string {
+	switch s {
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateDegraded:
+		return "degraded"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// Backoff 定义失败重启的指数退避参数
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// DefaultBackoff 返回默认的退避参数
+func DefaultBackoff() Backoff {
+	return Backoff{Initial: time.Second, Max: time.Minute}
+}
+
+// Delay 返回第 attempt 次（从 0 开始）重试前的等待时间
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := b.Initial
+	for i := 0; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	return min(d, b.Max)
+}
+
+// ChildStatus 是被监管子任务的状态快照
+type ChildStatus struct {
+	Running   bool      `json:"running"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"last_error,omitempty"`
+	LastFail  time.Time `json:"last_fail,omitempty"`
+}
+
+// supervisor 运行一组子任务，子任务失败后按退避重启，ctx 结束时等待全部退出
+type supervisor struct {
+	mu       sync.RWMutex
+	children map[string]*ChildStatus
+	backoff  Backoff
+	wg       sync.WaitGroup
+	logger   *log.Logger
+	onChange func()
+}
+
+func newSupervisor(backoff Backoff, logger *log.Logger, onChange func()) *supervisor {
+	return &supervisor{
+		children: make(map[string]*ChildStatus),
+		backoff:  backoff,
+		logger:   logger,
+		onChange: onChange,
+	}
+}
+
+// Go 启动一个被监管的子任务。fn 返回 nil 或 ctx 已结束时子任务退出，
+// 否则在退避后重新运行；持续运行超过最大退避时间后重置退避计数。
+func (s *supervisor) Go(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	status := &ChildStatus{}
+	s.children[name] = status
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		attempt := 0
+		for {
+			s.setRunning(status, true)
+			started := time.Now()
+			err := fn(ctx)
+			s.setRunning(status, false)
+
+			if ctx.Err() != nil || err == nil || errors.Is(err, context.Canceled) {
+				return
+			}
+			if time.Since(started) > s.backoff.Max {
+				attempt = 0
+			}
+
+			delay := s.backoff.Delay(attempt)
+			attempt++
+
+			s.mu.Lock()
+			status.Restarts++
+			status.LastError = err.Error()
+			status.LastFail = time.Now()
+			s.mu.Unlock()
+
+			s.logger.Warn("tunnel task failed, restarting", map[string]any{
+				"task":    name,
+				"attempt": attempt,
+				"delay":   delay,
+				"error":   err,
+			})
+
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (s *supervisor) setRunning(status *ChildStatus, running bool) {
+	s.mu.Lock()
+	status.Running = running
+	s.mu.Unlock()
+	if s.onChange != nil {
+		s.onChange()
+	}
+}
+
+// Healthy 判断所有子任务是否都在运行
+func (s *supervisor) Healthy() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.children {
+		if !c.Running {
+			return false
+		}
+	}
+	return true
+}
+
+// Status 返回所有子任务的状态快照
+func (s *supervisor) Status() map[string]ChildStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]ChildStatus, len(s.children))
+	for name, c := range s.children {
+		out[name] = *c
+	}
+	return out
+}
+
+// Wait 等待所有子任务退出
+func (s *supervisor) Wait() {
+	s.wg.Wait()
+}
+
+// retryInit 按退避重试 init，直到成功或 ctx 取消
+func retryInit(ctx context.Context, backoff Backoff, logger *log.Logger, name string, init func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := init()
+		if err == nil {
+			return nil
+		}
+		delay := backoff.Delay(attempt)
+		logger.Warn("failed to init tunnel, retrying", map[string]any{
+			"path":  name,
+			"delay": delay,
+			"error": err,
+		})
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// lifecycle 管理 Tunnel 的运行状态、子任务 supervisor 以及 Start/Stop 启动的后台运行
+type lifecycle struct {
+	name   string
+	logger *log.Logger
+
+	stateMu sync.RWMutex
+	state   TunnelState
+	sup     *supervisor
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	drained  chan struct{} // 排空时关闭，子任务据此停止接收新消息
+	draining bool
+}
+
+func (l *lifecycle) setState(state TunnelState) {
+	l.stateMu.Lock()
+	defer l.stateMu.Unlock()
+	if l.state != state {
+		l.logger.Info("tunnel state changed", map[string]any{
+			"path": l.name,
+			"from": l.state.String(),
+			"to":   state.String(),
+		})
+	}
+	l.state = state
+}
+
+// supervise 创建本次运行的 supervisor，子任务状态变化时更新 Tunnel 状态
+func (l *lifecycle) supervise(ctx context.Context, backoff Backoff) *supervisor {
+	sup := newSupervisor(backoff, l.logger, func() {
+		if ctx.Err() == nil {
+			l.updateState()
+		}
+	})
+	l.stateMu.Lock()
+	l.sup = sup
+	l.drained = make(chan struct{})
+	l.draining = false
+	l.stateMu.Unlock()
+	return sup
+}
+
+// updateState 根据子任务是否都在运行更新状态
+func (l *lifecycle) updateState() {
+	l.stateMu.RLock()
+	sup := l.sup
+	l.stateMu.RUnlock()
+	if sup == nil || l.isDraining() {
+		return
+	}
+	if sup.Healthy() {
+		l.setState(StateRunning)
+	} else {
+		l.setState(StateDegraded)
+	}
+}
+
+// State 返回 Tunnel 当前的运行状态
+func (l *lifecycle) State() TunnelState {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return l.state
+}
+
+// tasks 返回子任务状态，尚未运行时返回 nil
+func (l *lifecycle) tasks() map[string]ChildStatus {
+	l.stateMu.RLock()
+	sup := l.sup
+	l.stateMu.RUnlock()
+	if sup == nil {
+		return nil
+	}
+	return sup.Status()
+}
+
+// start 在后台执行 run，通过 Stop 停止
+func (l *lifecycle) start(run func(ctx context.Context) error) error {
+	l.stateMu.Lock()
+	defer l.stateMu.Unlock()
+	if l.cancel != nil {
+		return ErrTunnelRunning
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		run(ctx)
+	}(l.done)
+	return nil
+}
+
+// Stop 停止 Start 启动的 Tunnel 并等待所有子任务退出
+func (l *lifecycle) Stop() {
+	l.stateMu.Lock()
+	cancel, done := l.cancel, l.done
+	l.cancel, l.done = nil, nil
+	l.stateMu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
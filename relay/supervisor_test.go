@@ -0,0 +1,53 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := b.Delay(attempt); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestSupervisorRestartsFailedTask(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sup := newSupervisor(Backoff{Initial: time.Millisecond, Max: 10 * time.Millisecond}, log.WithComponent("test"), nil)
+
+	var runs atomic.Int32
+	sup.Go(ctx, "flaky", func(ctx context.Context) error {
+		if runs.Add(1) <= 2 {
+			return errors.New("boom")
+		}
+		<-ctx.Done()
+		return nil
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for !sup.Healthy() || runs.Load() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("task not restarted, runs=%d", runs.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	status := sup.Status()["flaky"]
+	if status.Restarts != 2 || status.LastError != "boom" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	cancel()
+	sup.Wait()
+	if sup.Healthy() {
+		t.Error("Expected task to be stopped after cancel")
+	}
+}
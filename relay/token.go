@@ -0,0 +1,193 @@
+package relay
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// 代币换算错误
+var (
+	ErrUnknownToken       = errors.New("token not registered")
+	ErrPrecisionLoss      = errors.New("amount would lose precision")
+	ErrAmountOutOfRange   = errors.New("amount out of range")
+	ErrInvalidTokenConfig = errors.New("invalid token config")
+)
+
+func init() {
+	types.RegisterClass(ErrUnknownToken, types.ClassFatal)
+	types.RegisterClass(ErrPrecisionLoss, types.ClassFatal)
+	types.RegisterClass(ErrAmountOutOfRange, types.ClassFatal)
+}
+
+// maxDecimals 限制代币精度，避免换算时构造过大的 10 的幂
+const maxDecimals = 77
+
+// Token 是一个跨链代币在源端和目标端的对应关系。Min、Max 为源端最小单位的单笔金额限制，为 nil 时不限制；
+// 金额超过 ApprovalThreshold（源端最小单位）的消息需人工审批，为 nil 时不需要
+type Token struct {
+	Symbol            string
+	Source            string // 源端代币地址
+	Target            string // 目标端代币地址
+	SourceDecimals    uint8
+	TargetDecimals    uint8
+	Min               *big.Int
+	Max               *big.Int
+	ApprovalThreshold *big.Int
+}
+
+// Scale 将源端最小单位的金额换算为目标端最小单位。目标端精度较低且换算有余数时返回 ErrPrecisionLoss，
+// 结果超出 uint256 时返回 ErrAmountOutOfRange
+func (t Token) Scale(amount *big.Int) (*big.Int, error) {
+	out := new(big.Int)
+	switch {
+	case t.TargetDecimals >= t.SourceDecimals:
+		out.Mul(amount, pow10(t.TargetDecimals-t.SourceDecimals))
+	default:
+		rem := new(big.Int)
+		out.QuoRem(amount, pow10(t.SourceDecimals-t.TargetDecimals), rem)
+		if rem.Sign() != 0 {
+			return nil, fmt.Errorf("%w: %s %s from %d to %d decimals", ErrPrecisionLoss, t.Symbol, amount, t.SourceDecimals, t.TargetDecimals)
+		}
+	}
+	if out.BitLen() > 256 {
+		return nil, fmt.Errorf("%w: %s %s exceeds uint256 after scaling", ErrAmountOutOfRange, t.Symbol, amount)
+	}
+	return out, nil
+}
+
+// TokenRegistry 是一个跨链桥的代币登记表，按源端代币地址查找。nil TokenRegistry 不换算金额
+type TokenRegistry struct {
+	tokens map[string]Token
+}
+
+// NewTokenRegistry 创建代币登记表，检查地址、精度和金额限制
+func NewTokenRegistry(tokens []Token) (*TokenRegistry, error) {
+	r := &TokenRegistry{tokens: make(map[string]Token, len(tokens))}
+	for _, token := range tokens {
+		switch {
+		case token.Source == "" || token.Target == "":
+			return nil, fmt.Errorf("%w: %s: source and target are required", ErrInvalidTokenConfig, token.Symbol)
+		case token.SourceDecimals > maxDecimals || token.TargetDecimals > maxDecimals:
+			return nil, fmt.Errorf("%w: %s: decimals exceed %d", ErrInvalidTokenConfig, token.Symbol, maxDecimals)
+		case token.Min != nil && token.Min.Sign() < 0, token.Max != nil && token.Max.Sign() <= 0:
+			return nil, fmt.Errorf("%w: %s: invalid min or max", ErrInvalidTokenConfig, token.Symbol)
+		case token.ApprovalThreshold != nil && token.ApprovalThreshold.Sign() < 0:
+			return nil, fmt.Errorf("%w: %s: invalid approval threshold", ErrInvalidTokenConfig, token.Symbol)
+		case token.Min != nil && token.Max != nil && token.Min.Cmp(token.Max) > 0:
+			return nil, fmt.Errorf("%w: %s: min %s exceeds max %s", ErrInvalidTokenConfig, token.Symbol, token.Min, token.Max)
+		}
+		key := tokenKey(token.Source)
+		if _, ok := r.tokens[key]; ok {
+			return nil, fmt.Errorf("%w: duplicate source token %s", ErrInvalidTokenConfig, token.Source)
+		}
+		r.tokens[key] = token
+	}
+	return r, nil
+}
+
+// Lookup 按源端代币地址查找代币。消息未携带代币地址（源端事件没有代币字段）时，
+// 只登记了一种代币的跨链桥使用该代币，登记了多种代币时无法确定，返回 false
+func (r *TokenRegistry) Lookup(source string) (Token, bool) {
+	if r == nil {
+		return Token{}, false
+	}
+	if source == "" && len(r.tokens) == 1 {
+		for _, token := range r.tokens {
+			return token, true
+		}
+	}
+	token, ok := r.tokens[tokenKey(source)]
+	return token, ok
+}
+
+// Tokens 返回按符号排序的全部代币
+func (r *TokenRegistry) Tokens() []Token {
+	if r == nil {
+		return nil
+	}
+	out := make([]Token, 0, len(r.tokens))
+	for _, token := range r.tokens {
+		out = append(out, token)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Symbol != out[j].Symbol {
+			return out[i].Symbol < out[j].Symbol
+		}
+		return out[i].Source < out[j].Source
+	})
+	return out
+}
+
+// Apply 检查消息的源端代币和金额限制，将金额和源端携带的手续费换算为目标端最小单位，并记录目标端代币。
+// 已换算的消息（TargetToken 不为空）不再重复换算
+func (r *TokenRegistry) Apply(msg *InMsg) error {
+	if r == nil || msg.TargetToken != "" {
+		return nil
+	}
+	token, ok := r.Lookup(msg.Token)
+	if !ok {
+		return fmt.Errorf("%w: %q of nonce %d", ErrUnknownToken, msg.Token, msg.Nonce)
+	}
+
+	value, err := msg.Value()
+	if err != nil {
+		return err
+	}
+	if token.Min != nil && value.Cmp(token.Min) < 0 {
+		return fmt.Errorf("%w: nonce %d amount %s below min %s %s", ErrAmountOutOfRange, msg.Nonce, value, token.Min, token.Symbol)
+	}
+	if token.Max != nil && value.Cmp(token.Max) > 0 {
+		return fmt.Errorf("%w: nonce %d amount %s above max %s %s", ErrAmountOutOfRange, msg.Nonce, value, token.Max, token.Symbol)
+	}
+	amount, err := token.Scale(value)
+	if err != nil {
+		return fmt.Errorf("nonce %d: %w", msg.Nonce, err)
+	}
+
+	feePaid := msg.FeePaid
+	if feePaid != "" {
+		paid, ok := new(big.Int).SetString(feePaid, 10)
+		if !ok || paid.Sign() < 0 {
+			return fmt.Errorf("%w: invalid fee %q of nonce %d", ErrInvalidMessage, feePaid, msg.Nonce)
+		}
+		if paid, err = token.Scale(paid); err != nil {
+			return fmt.Errorf("nonce %d fee: %w", msg.Nonce, err)
+		}
+		feePaid = paid.String()
+	}
+
+	msg.SourceAmount, msg.SourceFeePaid = msg.Amount, msg.FeePaid
+	msg.Amount, msg.FeePaid, msg.TargetToken = amount.String(), feePaid, token.Target
+	return nil
+}
+
+// ApprovalReason 返回消息需要人工审批的原因，金额未超过代币审批阈值时返回空字符串。
+// 只检查未换算的消息：消息在换算前已经检查过阈值
+func (r *TokenRegistry) ApprovalReason(msg InMsg) string {
+	if r == nil || msg.TargetToken != "" {
+		return ""
+	}
+	token, ok := r.Lookup(msg.Token)
+	if !ok || token.ApprovalThreshold == nil {
+		return ""
+	}
+	// 金额无效的消息由 Apply 拒绝
+	value, err := msg.Value()
+	if err != nil || value.Cmp(token.ApprovalThreshold) <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("amount %s above approval threshold %s %s", value, token.ApprovalThreshold, token.Symbol)
+}
+
+// tokenKey 规范化代币地址：0x 开头的 EVM 地址不区分大小写，其他格式（如 Tron base58）保持原样
+func tokenKey(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
@@ -0,0 +1,136 @@
+package relay
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestTokenScale(t *testing.T) {
+	tests := []struct {
+		name     string
+		src, dst uint8
+		amount   *big.Int
+		want     string
+		err      error
+	}{
+		{"same decimals", 18, 18, big.NewInt(123), "123", nil},
+		{"scale up", 6, 18, big.NewInt(1500000), "1500000000000000000", nil},
+		{"scale down exact", 18, 6, bigString("1500000000000000000"), "1500000", nil},
+		{"scale down zero", 18, 6, big.NewInt(0), "0", nil},
+		{"precision loss", 18, 6, bigString("1500000000000000001"), "", ErrPrecisionLoss},
+		{"dust below one unit", 18, 6, big.NewInt(999999999999), "", ErrPrecisionLoss},
+		{"uint256 boundary", 0, 0, maxUint256, maxUint256.String(), nil},
+		{"overflow after scaling", 0, 1, maxUint256, "", ErrAmountOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := Token{Symbol: "USDT", SourceDecimals: tt.src, TargetDecimals: tt.dst}
+			got, err := token.Scale(tt.amount)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v, got %v %v", tt.err, got, err)
+				}
+				return
+			}
+			if err != nil || got.String() != tt.want {
+				t.Fatalf("expected %s, got %v err %v", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestTokenRegistryApply(t *testing.T) {
+	registry, err := NewTokenRegistry([]Token{{
+		Symbol:         "USDT",
+		Source:         "0x55d398326f99059fF775485246999027B3197955",
+		Target:         "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		SourceDecimals: 18,
+		TargetDecimals: 6,
+		Min:            bigString("1000000000000000000"),
+		Max:            bigString("1000000000000000000000000"),
+	}})
+	if err != nil {
+		t.Fatalf("NewTokenRegistry failed: %v", err)
+	}
+
+	// 地址大小写不同也能查到
+	msg := InMsg{Nonce: 1, Token: "0x55D398326F99059FF775485246999027B3197955", Amount: "2500000000000000000", FeePaid: "1000000000000"}
+	if err := registry.Apply(&msg); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if msg.Amount != "2500000" || msg.FeePaid != "1" || msg.TargetToken != "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t" ||
+		msg.SourceAmount != "2500000000000000000" || msg.SourceFeePaid != "1000000000000" {
+		t.Fatalf("unexpected converted message %+v", msg)
+	}
+	// 已换算的消息不再重复换算
+	if err := registry.Apply(&msg); err != nil || msg.Amount != "2500000" {
+		t.Fatalf("expected idempotent apply, got %s err %v", msg.Amount, err)
+	}
+
+	rejected := []struct {
+		msg InMsg
+		err error
+	}{
+		{InMsg{Nonce: 2, Token: "0x0000000000000000000000000000000000000001", Amount: "1"}, ErrUnknownToken},
+		{InMsg{Nonce: 3, Token: "0x55d398326f99059ff775485246999027b3197955", Amount: "999999999999999999"}, ErrAmountOutOfRange},
+		{InMsg{Nonce: 4, Token: "0x55d398326f99059ff775485246999027b3197955", Amount: "1000000000000000000000001"}, ErrAmountOutOfRange},
+		{InMsg{Nonce: 5, Token: "0x55d398326f99059ff775485246999027b3197955", Amount: "1000000000000000001"}, ErrPrecisionLoss},
+		{InMsg{Nonce: 6, Token: "0x55d398326f99059ff775485246999027b3197955", Amount: "1000000000000000000", FeePaid: "1"}, ErrPrecisionLoss},
+	}
+	for _, tt := range rejected {
+		msg := tt.msg
+		if err := registry.Apply(&msg); !errors.Is(err, tt.err) {
+			t.Fatalf("nonce %d: expected %v, got %v", msg.Nonce, tt.err, err)
+		}
+		if msg != tt.msg {
+			t.Fatalf("nonce %d: rejected message modified: %+v", msg.Nonce, msg)
+		}
+	}
+
+	// nil 登记表不换算
+	var none *TokenRegistry
+	plain := InMsg{Nonce: 7, Amount: "1"}
+	if err := none.Apply(&plain); err != nil || plain.Amount != "1" {
+		t.Fatalf("expected passthrough, got %+v err %v", plain, err)
+	}
+}
+
+func TestTokenRegistryEmptyToken(t *testing.T) {
+	usdt := Token{Symbol: "USDT", Source: "0x55d398326f99059fF775485246999027B3197955", Target: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", SourceDecimals: 18, TargetDecimals: 6}
+	usdc := Token{Symbol: "USDC", Source: "0x8ac76a51cc950d9822d68b83fe1ad97b32cd580d", Target: "TEkxiTehnzSmSe2XqrBj4w32RUN966rdz8", SourceDecimals: 18, TargetDecimals: 6}
+
+	// 只登记一种代币时，未携带代币地址的消息使用该代币
+	single, _ := NewTokenRegistry([]Token{usdt})
+	msg := InMsg{Nonce: 1, Amount: "2000000000000000000"}
+	if err := single.Apply(&msg); err != nil || msg.Amount != "2000000" || msg.TargetToken != usdt.Target {
+		t.Fatalf("Expected empty token to use the only registered token, got %+v err %v", msg, err)
+	}
+
+	// 登记多种代币时无法确定代币
+	multi, _ := NewTokenRegistry([]Token{usdt, usdc})
+	msg = InMsg{Nonce: 2, Amount: "2000000000000000000"}
+	if err := multi.Apply(&msg); !errors.Is(err, ErrUnknownToken) {
+		t.Fatalf("Expected %v, got %v", ErrUnknownToken, err)
+	}
+}
+
+func TestNewTokenRegistryRejectsInvalidConfig(t *testing.T) {
+	bad := [][]Token{
+		{{Symbol: "A", Target: "0x2"}},
+		{{Symbol: "A", Source: "0x1", Target: "0x2", SourceDecimals: 78}},
+		{{Symbol: "A", Source: "0x1", Target: "0x2", Min: big.NewInt(2), Max: big.NewInt(1)}},
+		{{Symbol: "A", Source: "0x1", Target: "0x2", ApprovalThreshold: big.NewInt(-1)}},
+		{{Symbol: "A", Source: "0xAB", Target: "0x2"}, {Symbol: "B", Source: "0xab", Target: "0x3"}},
+	}
+	for i, tokens := range bad {
+		if _, err := NewTokenRegistry(tokens); !errors.Is(err, ErrInvalidTokenConfig) {
+			t.Fatalf("case %d: expected ErrInvalidTokenConfig, got %v", i, err)
+		}
+	}
+}
+
+func bigString(s string) *big.Int {
+	v, _ := new(big.Int).SetString(s, 10)
+	return v
+}
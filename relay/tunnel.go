@@ -1,6 +1,11 @@
 package relay
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
 	"time"
 
 	"github.com/st-chain/me-bridge/log"
@@ -8,13 +13,31 @@ import (
 	"github.com/st-chain/me-bridge/types"
 )
 
+// ErrReplayBusy 重新投递的消息过多，需稍后重试
+var ErrReplayBusy = errors.New("replay buffer full")
+
+// 默认的 Tunnel 运行参数
+const (
+	DefaultConfirmInterval = 5 * time.Second  // 轮询目标端已处理序号的间隔
+	DefaultGapInterval     = time.Second      // 检查队列缺口的间隔
+	DefaultPendingTimeout  = 10 * time.Minute // 已提交的消息等待打包的最长时间，超过后视为交易已丢弃
+)
+
 type Sequence struct {
 	Height uint64
 	ID     uint64
 }
 
-// InTunnel 处理跨链入向消息的通道
+// InTunnel 处理跨链入向消息的通道。
+// Run 启动后由 supervisor 管理三个子任务：
+//   - watcher：同步历史消息并订阅源端，按 nonce 排序后放入队列
+//   - processor：将队列中的消息交给目标端处理
+//   - confirmer：轮询目标端已处理的序号，确认队列中的消息
+//
+// 子任务失败后按退避重启，ctx 取消时等待所有子任务退出。
 type InTunnel struct {
+	lifecycle
+
 	Path     string
 	Source   InEndpoint
 	Target   OutEndpoint
@@ -24,100 +47,767 @@ type InTunnel struct {
 	Nonce         uint64 // 当前使用的nonce
 	TxRecorder    *TxRecorder
 	FeeCalculator *FeeCalculator
+	Tokens        *TokenRegistry  // 代币登记表，为 nil 时不换算金额
+	Limits        *RateLimiter    // 滚动窗口限流，为 nil 时不限流
+	Screener      Screener        // 发送和接收地址筛查，为 nil 时不筛查
+	Verifier      *SourceVerifier // 签名前在另一节点上核对源端事件，为 nil 时不核对
+	Funds         *BalanceMonitor // 目标端提交账户的余额监控，余额不足时暂停提交，为 nil 时不检查
+	Gas           *GasPolicy      // 目标链的 gas 价格策略，价格过高时推迟非紧急消息，为 nil 时不检查
 
-	Msgs         chan InMsg         // 跨入消息通道（从源端订阅）
-	ErrorHandler types.ErrorHandler // 错误处理器
-	logger       log.Logger
+	Msgs            chan InMsg         // 跨入消息通道（从源端订阅）
+	Queue           *Queue[InMsg]      // 按 nonce 排序后的跨入消息队列
+	Store           types.MessageStore // 队列持久化存储，为 nil 时使用内存队列
+	States          *StateMachine      // 消息生命周期状态机，为 nil 时只依赖链上检查去重
+	DeadLetters     *DeadLetterQueue   // 死信队列，为 nil 时失败的消息只记录日志
+	Ledger          types.LedgerStore  // 中继器账本，用于偿付能力对账，为 nil 时不记账
+	Holds           *HoldQueue         // 挂起队列，为 nil 时超出限流的消息等待窗口滚动后重试，需要审批或被筛查拦截的消息进入死信
+	Controls        *Controls          // 跨链桥的暂停开关，为 nil 时不可暂停
+	ErrorHandler    types.ErrorHandler // 错误处理器
+	Backoff         Backoff            // 初始化和子任务重启的退避参数
+	ConfirmInterval time.Duration      // 轮询目标端已处理序号的间隔
+	PendingTimeout  time.Duration      // 上次运行已提交、链上尚未处理的消息等待打包的时间，超过后重新提交
+	Batch           *BatchConfig       // 批量提交的聚合条件，为 nil 时逐条处理
+	logger          *log.Logger
 
-	// 控制通道
-	// stopCh chan struct{}
-	// done   chan struct{}
+	mu         sync.RWMutex // 保护 Sequence、Nonce、batcher、submitted、skipped 和 approved
+	subscribed bool         // 源端订阅只建立一次，节点切换后的重新订阅由终端负责
+	batcher    *Batcher
+	submitted  map[uint64]string // 已提交待确认消息的 nonce -> 消息 ID
+	skipped    uint64            // 因已处理而跳过的消息数
+	held       *InMsg            // 去重检查失败的消息，下次运行时优先处理
+	replays    chan InMsg        // 运维从死信队列重新投递的消息
+	approved   map[string]bool   // 运维批准放行、尚未提交的挂起消息 ID
 }
 
 func NewInTunnel(source InEndpoint, target OutEndpoint, key signer.Signer, feeCalculator *FeeCalculator) *InTunnel {
+	logger := log.WithComponent("in-tunnel")
 	return &InTunnel{
-		Path:          "InTunnel", // TODO: source.Name() + "->" + target.Name(),
-		Source:        source,
-		Target:        target,
-		Key:           key,
-		TxRecorder:    NewTxRecorder(0),
-		FeeCalculator: feeCalculator,
-		Msgs:          make(chan InMsg, 1024),
-		ErrorHandler:  NewErrorHandler(3, time.Second*10),
+		lifecycle:       lifecycle{name: "InTunnel", logger: logger},
+		Path:            "InTunnel", // TODO: source.Name() + "->" + target.Name(),
+		Source:          source,
+		Target:          target,
+		Key:             key,
+		TxRecorder:      NewTxRecorder(0),
+		FeeCalculator:   feeCalculator,
+		Msgs:            make(chan InMsg, 1024),
+		ErrorHandler:    NewErrorHandler(3, time.Second*10),
+		Backoff:         DefaultBackoff(),
+		ConfirmInterval: DefaultConfirmInterval,
+		PendingTimeout:  DefaultPendingTimeout,
+		logger:          logger,
+		submitted:       make(map[uint64]string),
+		replays:         make(chan InMsg, 16),
+		approved:        make(map[string]bool),
+	}
+}
+
+// backfill 回补队列中持续存在的 nonce 缺口，重复消息由队列丢弃
+func (t *InTunnel) backfill(gap Gap) {
+	toHeight := gap.ToHeight
+	if toHeight == 0 {
+		latest, err := t.Source.LatestHeight()
+		if err != nil {
+			t.logger.Error("failed to get source height for backfill", map[string]any{"error": err})
+			return
+		}
+		toHeight = uint64(latest)
+	}
+	msgs, err := t.Source.FilterInMsgs(gap.FromHeight, toHeight)
+	if err != nil {
+		t.logger.Error("failed to backfill nonce gap", map[string]any{
+			"from_nonce": gap.FromNonce,
+			"to_nonce":   gap.ToNonce,
+			"error":      err,
+		})
+		return
+	}
+	for _, msg := range msgs {
+		t.push(msg)
 	}
 }
 
 // Init 同步确定性跨链信息
-func (t *InTunnel) Init() {
+func (t *InTunnel) Init() error {
 	seq, height := t.Target.GetSequence()
+	nonce := t.Target.GetNonce(t.Key.Address())
+	t.mu.Lock()
 	t.Sequence.ID = seq
 	t.Sequence.Height = height
-	t.Nonce = t.Target.GetNonce(t.Key.Address())
+	t.Nonce = nonce
+	t.mu.Unlock()
+
+	if t.Store != nil {
+		// 持久化队列会重新投递上次未确认的消息
+		queue, err := NewDurableQueue[InMsg](t.Path, t.Store, cap(t.Msgs))
+		if err != nil {
+			return err
+		}
+		t.Queue = queue
+	} else {
+		t.Queue = NewQueue[InMsg](seq, cap(t.Msgs))
+	}
+	t.Queue.SetGapHandler(DefaultGapTimeout, t.backfill)
+	// 恢复重启前的限流用量
+	return t.Limits.Load()
 }
 
 // GetHistoryMsgs 获取历史跨链消息
 func (t *InTunnel) GetHistoryMsgs() error {
-	lastHeight := max(t.Sequence.Height, t.Source.LastHeight())
+	latest, err := t.Source.LatestHeight()
+	if err != nil {
+		return err
+	}
+	lastHeight := max(t.Sequence.Height, uint64(latest))
 	msgs, err := t.Source.FilterInMsgs(t.Sequence.Height, lastHeight)
 	if err != nil {
 		return err
 	}
 	for _, msg := range msgs {
-		t.Msgs <- msg
+		t.push(msg)
 	}
 
 	return nil
 }
 
-// Start 启动 Tunnel
-func (t *InTunnel) Start() error {
-	t.Init()
+// push 将源端消息记为已发现后放入队列，重复的消息由队列丢弃
+func (t *InTunnel) push(msg InMsg) {
+	t.observe(msg)
+	t.Queue.Push(msg)
+}
+
+// Run 初始化并运行 Tunnel，直到 ctx 取消。初始化失败时按退避重试
+func (t *InTunnel) Run(ctx context.Context) error {
+	t.setState(StateStarting)
+	defer t.setState(StateStopped)
+
+	if err := retryInit(ctx, t.Backoff, t.logger, t.Path, t.Init); err != nil {
+		return err
+	}
+
+	if t.DeadLetters != nil {
+		t.DeadLetters.Register(t.Path, t.Replay)
+		defer t.DeadLetters.Register(t.Path, nil)
+	}
+	if t.Holds != nil {
+		t.Holds.Register(t.Path, t.release)
+		defer t.Holds.Register(t.Path, nil)
+	}
+
+	sup := t.supervise(ctx, t.Backoff)
 
+	sup.Go(ctx, "watcher", t.watch)
+	sup.Go(ctx, "processor", t.process)
+	sup.Go(ctx, "confirmer", t.confirm)
+
+	t.logger.Info("tunnel started", map[string]any{"path": t.Path, "sequence": t.Sequence.ID})
+	<-ctx.Done()
+	sup.Wait()
+	t.logger.Info("tunnel stopped", map[string]any{"path": t.Path, "sequence": t.Sequence.ID})
+	return nil
+}
+
+// watch 同步源端历史消息并订阅新消息，按 nonce 排序后放入队列，同时定期检查缺口
+func (t *InTunnel) watch(ctx context.Context) error {
 	// 同步源端历史消息
 	// TODO: 能否用订阅直接代替
 	if err := t.GetHistoryMsgs(); err != nil {
-		return t.HandleError(err, map[string]interface{}{
-			"operation": "GetHistoryMsgs",
-		})
+		return t.HandleError(ctx, err, map[string]any{"operation": "GetHistoryMsgs"})
 	}
 
 	// 订阅源端入向消息
-	if err := t.Source.SubscribeToInMsgs(t.Msgs); err != nil {
-		// TODO: 退出订阅
-		return t.HandleError(err, map[string]interface{}{
-			"operation": "SubscribeToInMsgs",
-		})
+	if !t.subscribed {
+		if err := t.Source.SubscribeToInMsgs(t.Msgs); err != nil {
+			return t.HandleError(ctx, err, map[string]any{"operation": "SubscribeToInMsgs"})
+		}
+		t.subscribed = true
 	}
 
-	// 启动消息处理协程
-	if err := t.Target.ProcessInMsgs(t.Msgs); err != nil {
-		// 根据错误策略处理错误
-		return t.HandleError(err, map[string]interface{}{
-			"operation": "ProcessInMsgs",
-		})
+	// 排空或暂停接收时不再读取新消息，未读取的消息留在通道中或在重启后从源端重新同步
+	intake := t.intake(t.Controls.inbound())
+	ticker := time.NewTicker(DefaultGapInterval)
+	defer ticker.Stop()
+	for {
+		msgs := t.Msgs
+		if !intake.Open() {
+			msgs = nil
+		}
+		select {
+		case msg := <-msgs:
+			t.push(msg)
+		case <-intake.drained:
+			intake.drain()
+		case <-intake.changed:
+			intake.refresh()
+		case <-ticker.C:
+			if intake.Open() {
+				t.Queue.CheckGaps()
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// process 将队列中的消息去重后转发给目标端处理。每次运行使用独立的通道，
+// 退出时关闭通道以停止目标端的处理。目标端上报的失败消息进入死信队列，因余额不足失败的消息暂停提交后重新投递。
+// 排空、暂停提交或账户余额不足时不再提交新消息，只继续处理目标端上报的失败。
+// 被 gas 价格策略推迟的消息保留在队首，间隔一段时间后重新检查，期间不提交后续消息以保持 nonce 顺序
+func (t *InTunnel) process(ctx context.Context) error {
+	out := make(chan InMsg)
+	defer close(out)
+
+	var failures <-chan ProcessError
+	errc := make(chan error, 1)
+	if t.Batch != nil {
+		processor, ok := t.Target.(BatchProcessor)
+		if !ok {
+			return t.HandleError(ctx, ErrBatchUnsupported, map[string]any{"operation": "ProcessBatch"})
+		}
+		batcher := t.newBatcher(processor)
+		go func() { errc <- batcher.Run(ctx, out) }()
+	} else {
+		var err error
+		if failures, err = t.Target.ProcessInMsgs(out); err != nil {
+			return t.HandleError(ctx, err, map[string]any{"operation": "ProcessInMsgs"})
+		}
+	}
+
+	intake := t.intake(t.Controls.outbound()).withFunds(t.Funds.funds())
+	var retry <-chan time.Time // 推迟的消息重新检查的时间
+	for {
+		var msg *InMsg
+		msgs, replays := t.Queue.Msgs, t.replays
+		switch {
+		case !intake.Open() || retry != nil:
+			msgs, replays = nil, nil
+		default:
+			msg = t.held
+		}
+		if msg == nil {
+			select {
+			case m := <-msgs:
+				msg = &m
+			case m := <-replays:
+				msg = &m
+			case <-intake.drained:
+				intake.drain()
+				continue
+			case <-intake.changed:
+				intake.refresh()
+				continue
+			case <-intake.funded:
+				intake.refreshFunds()
+				continue
+			case <-retry:
+				retry = nil
+				continue
+			case pe, ok := <-failures:
+				if !ok {
+					failures = nil
+					continue
+				}
+				t.fail(ctx, pe)
+				continue
+			case err := <-errc:
+				return t.HandleError(ctx, err, map[string]any{"operation": "ProcessBatch"})
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		original := *msg
+		admit, err := t.admit(ctx, msg)
+		if errors.Is(err, ErrGasDeferred) {
+			// 保留换算前的消息，价格回落后按新的 gas 成本重新计算手续费
+			t.held = &original
+			retry = time.After(t.Gas.retryInterval())
+			continue
+		}
+		if errors.Is(err, ErrSubmissionPending) {
+			// 上次提交的交易可能仍在打包，等待链上结果后再决定是否重新提交
+			t.held = &original
+			retry = time.After(t.ConfirmInterval)
+			continue
+		}
+		if err != nil {
+			// 无法确认是否已处理时不能提交，保留消息等待重启后重试
+			t.held = msg
+			return t.HandleError(ctx, err, map[string]any{"operation": "IsProcessed", "nonce": msg.Nonce})
+		}
+		t.held = nil
+		if !admit {
+			continue
+		}
+
+		select {
+		case out <- *msg:
+			t.transition(*msg, types.MsgStateSubmitted, "", "")
+		case err := <-errc:
+			return t.HandleError(ctx, err, map[string]any{"operation": "ProcessBatch"})
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// Replay 重新投递消息，消息仍会经过去重检查，已完成的消息不会重复提交
+func (t *InTunnel) Replay(msg InMsg) error {
+	if t.isDraining() {
+		return ErrTunnelDraining
+	}
+	select {
+	case t.replays <- msg:
+		return nil
+	default:
+		return ErrReplayBusy
+	}
+}
+
+// release 放行运维批准的挂起消息，消息仍会经过去重检查，但不受限流
+func (t *InTunnel) release(msg InMsg) error {
+	id, err := msg.ID()
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.approved[id] = true
+	t.mu.Unlock()
+	if err := t.Replay(msg); err != nil {
+		t.mu.Lock()
+		delete(t.approved, id)
+		t.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// fail 处理目标端上报的失败。配置了余额监控时，因余额不足失败的消息不进入死信：
+// 暂停提交并告警，消息重新投递，充值后随队列继续提交
+func (t *InTunnel) fail(ctx context.Context, pe ProcessError) {
+	if t.Funds == nil || types.Classify(pe.Err) != types.ClassInsufficientFunds {
+		t.deadLetter(pe)
+		return
+	}
+	t.Funds.Exhausted(ctx, pe.Err)
+	if err := t.Replay(pe.Msg); err != nil {
+		t.deadLetter(pe)
+		return
+	}
+	t.mu.Lock()
+	delete(t.submitted, pe.Msg.Nonce)
+	t.mu.Unlock()
+	t.logger.Warn("message requeued after insufficient funds", map[string]any{"path": t.Path, "nonce": pe.Msg.Nonce, "error": pe.Err})
+}
+
+// deadLetter 将失败的消息记为失败并移入死信队列，持久化成功后确认队列中的消息
+func (t *InTunnel) deadLetter(pe ProcessError) {
+	t.logger.Error("message processing failed", map[string]any{
+		"path":    t.Path,
+		"nonce":   pe.Msg.Nonce,
+		"retries": pe.RetryCount,
+		"error":   pe.Err,
+	})
+
+	t.transition(pe.Msg, types.MsgStateFailed, pe.TxHash, pe.Error())
+	t.mu.Lock()
+	delete(t.submitted, pe.Msg.Nonce)
+	t.mu.Unlock()
+
+	if t.DeadLetters == nil {
+		return
+	}
+	if _, err := t.DeadLetters.Add(t.Path, pe); err != nil {
+		t.logger.Error("failed to add dead letter", map[string]any{"nonce": pe.Msg.Nonce, "error": err})
+		return
+	}
+	if err := t.Queue.Ack(pe.Msg.Nonce); err != nil {
+		t.logger.Error("failed to ack dead letter", map[string]any{"nonce": pe.Msg.Nonce, "error": err})
+	}
+}
+
+// admit 在提交前检查消息是否已处理：先查本地状态，再通过目标端 isProcessed 查询链上状态。
+// 已处理的消息记为完成并跳过；未处理的消息在另一节点上核对源端事件，检查人工审批、计算手续费、检查 gas 价格策略和限流，
+// 记为等待提交后放行。被 gas 价格策略推迟的消息返回 ErrGasDeferred，已提交未满 PendingTimeout、
+// 链上尚未处理的消息（如重启前已广播的交易）返回 ErrSubmissionPending
+func (t *InTunnel) admit(ctx context.Context, msg *InMsg) (bool, error) {
+	id, err := msg.ID()
+	if err != nil {
+		// 无法计算 ID 的消息无法保证幂等，拒绝提交并交给运维处理
+		t.deadLetter(ProcessError{Msg: *msg, Err: err, Timestamp: time.Now()})
+		return false, nil
+	}
+
+	var rec *types.MessageRecord
+	if t.States != nil {
+		if rec, err = t.States.Get(id); err != nil {
+			return false, err
+		}
+		if rec != nil && rec.State.Terminal() {
+			t.skip(*msg, id, "local")
+			return false, nil
+		}
+	}
+
+	if checker, ok := t.Target.(ProcessedChecker); ok {
+		processed, err := checker.IsProcessed(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		if processed {
+			if err := t.advance(id, msg.Nonce, types.MsgStateCompleted, "", "processed on chain"); err != nil {
+				return false, err
+			}
+			t.settle(id)
+			t.skip(*msg, id, "chain")
+			return false, nil
+		}
+	}
+	if rec != nil && rec.State == types.MsgStateSubmitted && time.Since(rec.UpdatedAt) < t.PendingTimeout {
+		return false, fmt.Errorf("%w: %s submitted at %s", ErrSubmissionPending, id, rec.UpdatedAt.Format(time.RFC3339))
+	}
+
+	if err := t.Verifier.Verify(ctx, *msg); err != nil {
+		if errors.Is(err, ErrSourceMismatch) {
+			t.logger.Error("source event mismatch", map[string]any{"nonce": msg.Nonce, "id": id, "observer": msg.Observer, "error": err})
+		}
+		return t.reject(msg, err)
+	}
+	approved, ok, err := t.review(ctx, msg, id)
+	if err != nil || !ok {
+		return t.reject(msg, err)
+	}
+	if err := t.convert(msg); err != nil {
+		return t.reject(msg, err)
+	}
+	if err := t.Gas.Check(ctx, *msg, id, t.gasCost(), approved); err != nil {
+		return t.reject(msg, err)
+	}
+	reservation, ok, err := t.limit(msg, approved)
+	if err != nil || !ok {
+		return t.reject(msg, err)
+	}
+
+	if err := t.advance(id, msg.Nonce, types.MsgStateQueued, "", ""); err != nil {
+		// 消息没有提交，撤销预留的限流用量，重试时重新检查
+		if rerr := t.Limits.Release(reservation); rerr != nil {
+			t.logger.Warn("failed to release rate limit usage", map[string]any{"nonce": msg.Nonce, "error": rerr})
+		}
+		if errors.Is(err, ErrInvalidTransition) {
+			// 状态不允许重新提交，交给运维处理
+			t.deadLetter(ProcessError{Msg: *msg, Err: err, Timestamp: time.Now()})
+			return false, nil
+		}
+		return false, err
+	}
+	t.book(id, *msg)
+	t.mu.Lock()
+	t.submitted[msg.Nonce] = id
+	t.mu.Unlock()
+	return true, nil
+}
+
+// convert 将消息金额换算为目标端代币单位并计算手续费
+func (t *InTunnel) convert(msg *InMsg) error {
+	if err := t.Tokens.Apply(msg); err != nil {
+		return err
+	}
+	return t.FeeCalculator.Apply(msg)
+}
+
+// gasCost 返回手续费使用的 gas 成本来源给出的当前成本，用于检查 gas 成本占消息金额的比例。
+// 未配置成本比例或成本来源、查询失败时返回 nil（不检查比例）
+func (t *InTunnel) gasCost() *big.Int {
+	if t.Gas == nil || t.Gas.MaxFeeBps == 0 || t.FeeCalculator == nil || t.FeeCalculator.Gas == nil {
+		return nil
+	}
+	cost, err := t.FeeCalculator.Gas.GasCost()
+	if err != nil {
+		t.logger.Warn("failed to get gas cost for gas policy", map[string]any{"path": t.Path, "error": err})
+		return nil
+	}
+	return cost
+}
+
+// reject 处理未放行的消息：致命错误（如源端事件不一致、代币未登记、金额超限、手续费不足）交给运维处理，
+// 其他错误返回由 supervisor 退避后重试
+func (t *InTunnel) reject(msg *InMsg, err error) (bool, error) {
+	if err != nil && types.Classify(err) == types.ClassFatal {
+		t.deadLetter(ProcessError{Msg: *msg, Err: err, Timestamp: time.Now()})
+		return false, nil
+	}
+	return false, err
+}
+
+// review 检查消息的人工审批状态。运维批准的消息返回 approved；挂起等待审批、已拒绝或已过期的消息不再提交；
+// 发送或接收地址被筛查拦截、金额超过代币审批阈值的消息挂起等待审批
+func (t *InTunnel) review(ctx context.Context, msg *InMsg, id string) (approved, ok bool, err error) {
+	t.mu.Lock()
+	approved = t.approved[id]
+	delete(t.approved, id)
+	t.mu.Unlock()
+	if approved {
+		return true, true, nil
+	}
+
+	if t.Holds != nil {
+		held, err := t.Holds.Lookup(id)
+		if err != nil {
+			return false, false, err
+		}
+		if held != nil {
+			if held.Status == types.HoldApproved {
+				return true, true, nil
+			}
+			t.logger.Info("skipping held message", map[string]any{"nonce": msg.Nonce, "id": id, "status": held.Status})
+			return false, false, nil
+		}
+	}
+
+	reason, err := ScreenMsg(ctx, t.Screener, *msg)
+	if err != nil {
+		return false, false, err
+	}
+	if reason != "" {
+		return false, false, t.hold(msg, fmt.Errorf("%w: %s", ErrAddressBlocked, reason))
+	}
+	if reason := t.Tokens.ApprovalReason(*msg); reason != "" {
+		return false, false, t.hold(msg, fmt.Errorf("%w: %s", ErrApprovalRequired, reason))
+	}
+	return false, true, nil
+}
+
+// limit 检查消息是否超出限流，超出时挂起等待审批。运维批准的消息不受限流，但仍计入用量
+func (t *InTunnel) limit(msg *InMsg, approved bool) (*Reservation, bool, error) {
+	if approved {
+		reservation, err := t.Limits.Record(*msg)
+		if err != nil {
+			t.logger.Warn("failed to record rate limit usage", map[string]any{"nonce": msg.Nonce, "error": err})
+		}
+		return reservation, true, nil
 	}
 
+	reservation, err := t.Limits.Reserve(*msg)
+	if err == nil || !errors.Is(err, ErrRateLimited) {
+		return reservation, err == nil, err
+	}
+	return nil, false, t.hold(msg, err)
+}
+
+// hold 将消息挂起等待审批并确认队列中的消息。未配置挂起队列时返回 cause：
+// 需要审批或被筛查拦截的消息交给死信，超出限流的消息等待窗口滚动后重试
+func (t *InTunnel) hold(msg *InMsg, cause error) error {
+	if t.Holds == nil {
+		return cause
+	}
+	if _, err := t.Holds.Hold(t.Path, *msg, cause.Error()); err != nil {
+		return err
+	}
+	t.transition(*msg, types.MsgStateHeld, "", cause.Error())
+	if err := t.Queue.Ack(msg.Nonce); err != nil {
+		t.logger.Error("failed to ack held message", map[string]any{"nonce": msg.Nonce, "error": err})
+	}
 	return nil
 }
 
-// HandleError 根据错误策略处理错误
-func (t *InTunnel) HandleError(err error, metadata map[string]interface{}) error {
-	t.logger.Error("handling error", metadata, map[string]any{
-		"error": err,
+// book 将入队的消息记入账本，目标端完成后由 settle 计入已释放金额。
+// 账本只用于对账，写入失败只记录日志，差额由对账发现
+func (t *InTunnel) book(id string, msg InMsg) {
+	if t.Ledger == nil {
+		return
+	}
+	token := msg.TargetToken
+	if token == "" {
+		token = msg.Token
+	}
+	entry := types.LedgerEntry{ID: id, Bridge: t.Path, Token: token, Amount: msg.Amount, UpdatedAt: time.Now()}
+	if err := t.Ledger.SaveLedgerEntry(entry); err != nil {
+		t.logger.Error("failed to record ledger entry", map[string]any{"id": id, "nonce": msg.Nonce, "error": err})
+	}
+}
+
+// settle 将账本中的消息记为已完成，不在账本中的消息（如未配置账本时入队）跳过
+func (t *InTunnel) settle(id string) {
+	if t.Ledger == nil {
+		return
+	}
+	entry, err := t.Ledger.GetLedgerEntry(id)
+	if err == nil && entry != nil && !entry.Completed {
+		entry.Completed, entry.UpdatedAt = true, time.Now()
+		err = t.Ledger.SaveLedgerEntry(*entry)
+	}
+	if err != nil {
+		t.logger.Error("failed to settle ledger entry", map[string]any{"id": id, "error": err})
+	}
+}
+
+func (t *InTunnel) skip(msg InMsg, id, source string) {
+	t.mu.Lock()
+	t.skipped++
+	t.mu.Unlock()
+	t.logger.Info("skipping already processed message", map[string]any{
+		"nonce":  msg.Nonce,
+		"id":     id,
+		"source": source,
 	})
+}
+
+// advance 通过状态机转换消息状态，未配置状态机时忽略
+func (t *InTunnel) advance(id string, nonce uint64, state types.MessageState, txHash, detail string) error {
+	if t.States == nil {
+		return nil
+	}
+	return t.States.Transition(types.MessageRecord{ID: id, Queue: t.Path, Nonce: nonce, State: state, TxHash: txHash}, detail)
+}
+
+// transition 转换消息状态，失败只记录日志，用于不影响处理流程的状态更新
+func (t *InTunnel) transition(msg InMsg, state types.MessageState, txHash, detail string) {
+	id, err := msg.ID()
+	if err != nil {
+		return
+	}
+	if err := t.advance(id, msg.Nonce, state, txHash, detail); err != nil {
+		t.logger.Error("failed to update message state", map[string]any{
+			"id":    id,
+			"nonce": msg.Nonce,
+			"state": state,
+			"error": err,
+		})
+	}
+}
+
+// observe 将首次发现的源端消息记为已发现
+func (t *InTunnel) observe(msg InMsg) {
+	if t.States == nil {
+		return
+	}
+	id, err := msg.ID()
+	if err != nil {
+		return
+	}
+	rec, err := t.States.Get(id)
+	if err != nil || rec != nil {
+		return
+	}
+	t.transition(msg, types.MsgStateObserved, "", "")
+}
+
+// newBatcher 创建本次运行使用的批量提交器
+func (t *InTunnel) newBatcher(processor BatchProcessor) *Batcher {
+	batcher := NewBatcher(*t.Batch, processor)
+	batcher.Backoff = t.Backoff
+	batcher.OnFailed = func(msg InMsg, err error) {
+		t.fail(context.Background(), ProcessError{Msg: msg, Err: err, Timestamp: time.Now()})
+	}
+	t.mu.Lock()
+	t.batcher = batcher
+	t.mu.Unlock()
+	return batcher
+}
+
+// confirm 轮询目标端已处理的序号，确认队列中已完成的消息
+func (t *InTunnel) confirm(ctx context.Context) error {
+	ticker := time.NewTicker(t.ConfirmInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			seq, height := t.Target.GetSequence()
+			t.mu.RLock()
+			acked := t.Sequence.ID
+			t.mu.RUnlock()
 
-	// 调用错误处理器
+			var err error
+			for acked < seq {
+				if err = t.complete(acked + 1); err != nil {
+					break
+				}
+				acked++
+			}
+
+			t.mu.Lock()
+			t.Sequence.ID = acked
+			t.Sequence.Height = max(t.Sequence.Height, height)
+			t.mu.Unlock()
+			if err != nil {
+				return t.HandleError(ctx, err, map[string]any{"operation": "Ack", "nonce": acked + 1})
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// complete 将目标端已处理的 nonce 记为完成并确认队列，重启前已提交的消息同样结算账本
+func (t *InTunnel) complete(nonce uint64) error {
+	t.mu.RLock()
+	id, ok := t.submitted[nonce]
+	t.mu.RUnlock()
+	if !ok {
+		// 重启前已提交的消息不在 submitted 中，从重新投递的消息中找回 ID
+		if msg, found := t.Queue.Redelivered(nonce); found {
+			if mid, err := msg.ID(); err == nil {
+				id, ok = mid, true
+			}
+		}
+	}
+	if ok {
+		if err := t.advance(id, nonce, types.MsgStateCompleted, "", ""); err != nil {
+			return err
+		}
+		t.settle(id)
+	}
+	if err := t.Queue.Ack(nonce); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	delete(t.submitted, nonce)
+	t.mu.Unlock()
+	return nil
+}
+
+// HandleError 记录错误并交给错误处理器，返回的错误由 supervisor 决定重启
+func (t *InTunnel) HandleError(ctx context.Context, err error, metadata map[string]any) error {
+	metadata["path"] = t.Path
+	metadata["error"] = err
+	t.logger.Error("tunnel task error", metadata)
+
+	// 错误处理器只负责上报和分级，子任务总是退出并由 supervisor 按退避重启
 	if t.ErrorHandler != nil {
-		t.ErrorHandler.HandleError(nil, err, metadata)
+		t.ErrorHandler.HandleError(ctx, err, metadata)
 	}
+	return err
+}
 
-	// 重启 Tunnel
-	t.Stop()
-	return t.Start()
+// Start 在后台运行 Tunnel，通过 Stop 停止
+func (t *InTunnel) Start() error {
+	return t.start(t.Run)
 }
 
-// Stop 停止 Tunnel
-func (t *InTunnel) Stop() {
-	t.Source.Stop() // 停止源端订阅
-	t.Target.Stop() // 停止目标端处理
+// Status 返回 Tunnel 的状态信息
+func (t *InTunnel) Status() map[string]any {
+	t.mu.RLock()
+	status := map[string]any{
+		"path":     t.Path,
+		"state":    t.State().String(),
+		"sequence": t.Sequence.ID,
+		"height":   t.Sequence.Height,
+		"nonce":    t.Nonce,
+		"skipped":  t.skipped,
+	}
+	batcher := t.batcher
+	t.mu.RUnlock()
+
+	if batcher != nil {
+		status["batch"] = batcher.Metrics()
+	}
+	if tasks := t.tasks(); tasks != nil {
+		status["tasks"] = tasks
+	}
+	if t.Queue != nil {
+		status["queue"] = t.Queue.Metrics()
+	}
+	if t.Limits != nil {
+		status["limits"] = t.Limits.Utilization()
+	}
+	return status
 }
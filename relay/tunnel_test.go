@@ -0,0 +1,138 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// memStates 是测试用的内存消息状态存储
+type memStates map[string]types.MessageRecord
+
+func (s memStates) GetRecord(id string) (*types.MessageRecord, error) {
+	rec, ok := s[id]
+	if !ok {
+		return nil, nil
+	}
+	return &rec, nil
+}
+
+func (s memStates) SaveRecord(rec types.MessageRecord) error {
+	s[rec.ID] = rec
+	return nil
+}
+
+func (s memStates) SaveTransition(rec types.MessageRecord, tr types.MessageTransition) error {
+	s[rec.ID] = rec
+	return nil
+}
+
+func (s memStates) ListTransitions(id string) ([]types.MessageTransition, error) {
+	return nil, nil
+}
+
+// checkedTarget 是支持链上 isProcessed 查询的目标端
+type checkedTarget struct {
+	fakeTarget
+	processed map[string]bool
+}
+
+func (t *checkedTarget) IsProcessed(ctx context.Context, id string) (bool, error) {
+	return t.processed[id], nil
+}
+
+func TestInTunnelAdmitSkipsProcessedMessages(t *testing.T) {
+	target := &checkedTarget{processed: make(map[string]bool)}
+	states := memStates{}
+	tunnel := NewInTunnel(&fakeSource{}, target, fakeSigner{}, nil)
+	tunnel.States = NewStateMachine(states)
+	tunnel.ErrorHandler = nil
+	tunnel.Queue = NewQueue[InMsg](0, 16)
+
+	msg := func(logIndex uint) InMsg {
+		return InMsg{Nonce: uint64(logIndex), ChainID: "56", TxHash: testTxHash, LogIndex: logIndex}
+	}
+	id := func(m InMsg) string {
+		id, _ := m.ID()
+		return id
+	}
+	ctx := context.Background()
+
+	// 未处理的消息放行并记为等待提交
+	fresh := msg(1)
+	if ok, err := tunnel.admit(ctx, &fresh); !ok || err != nil {
+		t.Fatalf("Expected fresh message to be admitted, got %v, %v", ok, err)
+	}
+	if states[id(fresh)].State != types.MsgStateQueued {
+		t.Errorf("Expected queued state, got %+v", states[id(fresh)])
+	}
+
+	// 链上已处理的消息跳过并记为完成
+	onChain := msg(2)
+	target.processed[id(onChain)] = true
+	if ok, _ := tunnel.admit(ctx, &onChain); ok {
+		t.Error("Expected message processed on chain to be skipped")
+	}
+	if states[id(onChain)].State != types.MsgStateCompleted {
+		t.Errorf("Expected completed state, got %+v", states[id(onChain)])
+	}
+
+	// 目标端确认后本地记为完成，重放时不再查询链上直接跳过
+	if err := tunnel.complete(fresh.Nonce); err != nil {
+		t.Fatalf("Failed to complete message: %v", err)
+	}
+	if states[id(fresh)].State != types.MsgStateCompleted {
+		t.Errorf("Expected completed state, got %+v", states[id(fresh)])
+	}
+	if ok, _ := tunnel.admit(ctx, &fresh); ok {
+		t.Error("Expected locally completed message to be skipped")
+	}
+
+	// 无法计算 ID 的消息拒绝提交
+	if ok, _ := tunnel.admit(ctx, &InMsg{Nonce: 5}); ok {
+		t.Error("Expected message without id to be rejected")
+	}
+	if got := tunnel.Status()["skipped"]; got != uint64(2) {
+		t.Errorf("Expected 2 skipped, got %v", got)
+	}
+}
+
+func TestInTunnelAdmitWaitsForPendingSubmission(t *testing.T) {
+	target := &checkedTarget{processed: make(map[string]bool)}
+	states := memStates{}
+	tunnel := NewInTunnel(&fakeSource{}, target, fakeSigner{}, nil)
+	tunnel.States = NewStateMachine(states)
+	tunnel.ErrorHandler = nil
+	tunnel.Queue = NewQueue[InMsg](0, 16)
+	ctx := context.Background()
+
+	// 重启前已提交的消息
+	msg := InMsg{Nonce: 1, ChainID: "56", TxHash: testTxHash}
+	id, _ := msg.ID()
+	if ok, err := tunnel.admit(ctx, &msg); !ok || err != nil {
+		t.Fatalf("Expected message to be admitted, got %v, %v", ok, err)
+	}
+	tunnel.transition(msg, types.MsgStateSubmitted, "", "")
+
+	// 交易可能仍在打包，不重新提交
+	if ok, err := tunnel.admit(ctx, &msg); ok || !errors.Is(err, ErrSubmissionPending) {
+		t.Fatalf("Expected %v, got %v, %v", ErrSubmissionPending, ok, err)
+	}
+
+	// 打包后链上已处理，跳过
+	target.processed[id] = true
+	if ok, err := tunnel.admit(ctx, &msg); ok || err != nil || states[id].State != types.MsgStateCompleted {
+		t.Fatalf("Expected processed message to be completed, got %v, %v, %+v", ok, err, states[id])
+	}
+
+	// 超过等待时间仍未处理的交易视为已丢弃，重新提交
+	other := InMsg{Nonce: 2, ChainID: "56", TxHash: testTxHash, LogIndex: 1}
+	tunnel.admit(ctx, &other)
+	tunnel.transition(other, types.MsgStateSubmitted, "", "")
+	tunnel.PendingTimeout = 0
+	if ok, err := tunnel.admit(ctx, &other); !ok || err != nil {
+		t.Errorf("Expected dropped submission to be requeued, got %v, %v", ok, err)
+	}
+}
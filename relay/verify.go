@@ -0,0 +1,128 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// 源端事件核对错误
+var (
+	ErrSourceMismatch   = errors.New("source event mismatch")
+	ErrSourceUnverified = errors.New("source event not verified")
+)
+
+func init() {
+	// 另一节点上的收据与消息不符时可能是观察节点伪造了事件，不再提交，交给运维处理
+	types.RegisterClass(ErrSourceMismatch, types.ClassFatal)
+	// 节点不可用、确认数不足或正在重组时无法判断，等待重试
+	types.RegisterClass(ErrSourceUnverified, types.ClassRetryable)
+}
+
+// SourceReceipt 是从源端节点重新读取的交易收据中与消息相关的部分
+type SourceReceipt struct {
+	Node      string // 读取收据的节点
+	Found     bool   // 节点上存在该交易的收据
+	Succeeded bool   // 交易执行成功
+	Height    uint64 // 收据所在区块
+	Canonical bool   // 收据的区块哈希与节点主链上同高度的区块一致
+	Latest    uint64 // 节点的最新区块
+	Log       *InMsg // 收据中日志序号相同的跨链合约事件，不存在时为 nil
+}
+
+// SourceReader 由能够重新读取源端交易收据的终端实现。终端应使用观察到消息（msg.Observer）之外的节点，
+// 以防单个节点伪造事件
+type SourceReader interface {
+	ReadSource(ctx context.Context, msg InMsg) (*SourceReceipt, error)
+}
+
+// SourceVerifier 在签名前从另一节点重新读取源端收据，核对事件存在且字段一致、交易成功、
+// 区块仍在主链上且达到要求的确认数
+type SourceVerifier struct {
+	Reader SourceReader
+	Depth  uint64 // 要求的确认数，含事件所在区块
+}
+
+// NewSourceVerifier 使用 reader 和确认数构建 SourceVerifier
+func NewSourceVerifier(reader SourceReader, depth uint64) *SourceVerifier {
+	return &SourceVerifier{Reader: reader, Depth: depth}
+}
+
+// Verify 核对消息与源端收据，不一致时返回 ErrSourceMismatch，无法判断时返回 ErrSourceUnverified。
+// v 为 nil 时不核对
+func (v *SourceVerifier) Verify(ctx context.Context, msg InMsg) error {
+	if v == nil {
+		return nil
+	}
+	receipt, err := v.Reader.ReadSource(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("%w: tx %s: %w", ErrSourceUnverified, msg.TxHash, err)
+	}
+	if msg.Observer != "" && receipt.Node == msg.Observer {
+		return fmt.Errorf("%w: tx %s read from observing node %s", ErrSourceUnverified, msg.TxHash, receipt.Node)
+	}
+
+	if !receipt.Found {
+		// 节点尚未同步到事件所在区块时无法区分延迟和伪造
+		if receipt.Latest < msg.Height+v.Depth {
+			return fmt.Errorf("%w: tx %s not found on %s at height %d", ErrSourceUnverified, msg.TxHash, receipt.Node, receipt.Latest)
+		}
+		return fmt.Errorf("%w: tx %s not found on %s", ErrSourceMismatch, msg.TxHash, receipt.Node)
+	}
+	if !receipt.Succeeded {
+		return fmt.Errorf("%w: tx %s failed on %s", ErrSourceMismatch, msg.TxHash, receipt.Node)
+	}
+	if receipt.Height != msg.Height {
+		return fmt.Errorf("%w: tx %s at height %d on %s, message height %d", ErrSourceMismatch, msg.TxHash, receipt.Height, receipt.Node, msg.Height)
+	}
+	if !receipt.Canonical {
+		return fmt.Errorf("%w: block %d of tx %s is not canonical on %s", ErrSourceUnverified, receipt.Height, msg.TxHash, receipt.Node)
+	}
+	if confirmations := confirmationsOf(receipt); confirmations < v.Depth {
+		return fmt.Errorf("%w: tx %s has %d of %d confirmations on %s", ErrSourceUnverified, msg.TxHash, confirmations, v.Depth, receipt.Node)
+	}
+	if receipt.Log == nil {
+		return fmt.Errorf("%w: log %d of tx %s not found on %s", ErrSourceMismatch, msg.LogIndex, msg.TxHash, receipt.Node)
+	}
+	if diff := diffSource(msg, *receipt.Log); len(diff) > 0 {
+		return fmt.Errorf("%w: log %d of tx %s on %s: %s", ErrSourceMismatch, msg.LogIndex, msg.TxHash, receipt.Node, strings.Join(diff, ", "))
+	}
+	return nil
+}
+
+// confirmationsOf 返回收据所在区块的确认数
+func confirmationsOf(receipt *SourceReceipt) uint64 {
+	if receipt.Latest < receipt.Height {
+		return 0
+	}
+	return receipt.Latest - receipt.Height + 1
+}
+
+// diffSource 比较消息与收据中的事件，返回不一致的字段。已换算为目标端单位的消息
+// （运维放行或重新投递）比较换算前保留的源端金额和手续费
+func diffSource(msg, log InMsg) []string {
+	var diff []string
+	compare := func(field, got, want string, equal func(a, b string) bool) {
+		if !equal(got, want) {
+			diff = append(diff, fmt.Sprintf("%s %q != %q", field, got, want))
+		}
+	}
+	exact := func(a, b string) bool { return a == b }
+
+	compare("nonce", fmt.Sprint(msg.Nonce), fmt.Sprint(log.Nonce), exact)
+	compare("log_index", fmt.Sprint(msg.LogIndex), fmt.Sprint(log.LogIndex), exact)
+	compare("hash", msg.TxHash, log.TxHash, strings.EqualFold)
+	compare("sender", msg.Sender, log.Sender, strings.EqualFold)
+	compare("receiver", msg.Receiver, log.Receiver, strings.EqualFold)
+	compare("token", msg.Token, log.Token, strings.EqualFold)
+	amount, feePaid := msg.Amount, msg.FeePaid
+	if msg.TargetToken != "" {
+		amount, feePaid = msg.SourceAmount, msg.SourceFeePaid
+	}
+	compare("amount", amount, log.Amount, exact)
+	compare("fee_paid", feePaid, log.FeePaid, exact)
+	return diff
+}
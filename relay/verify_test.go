@@ -0,0 +1,124 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+// fakeReader 返回固定的源端收据
+type fakeReader struct {
+	receipt *SourceReceipt
+	err     error
+}
+
+func (r *fakeReader) ReadSource(ctx context.Context, msg InMsg) (*SourceReceipt, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	receipt := *r.receipt
+	return &receipt, nil
+}
+
+func TestSourceVerifier(t *testing.T) {
+	msg := InMsg{Nonce: 7, ChainID: "56", Height: 100, TxHash: testTxHash, LogIndex: 3, Sender: "0xA", Receiver: "0xb", Token: "0xc", Amount: "100", Observer: "node-0"}
+	event := msg
+	event.Sender, event.Observer = "0xa", ""
+	valid := SourceReceipt{Node: "node-1", Found: true, Succeeded: true, Height: 100, Canonical: true, Latest: 111, Log: &event}
+
+	tamper := func(f func(*InMsg)) *InMsg {
+		log := event
+		f(&log)
+		return &log
+	}
+	converted := msg
+	converted.Amount, converted.TargetToken, converted.SourceAmount = "1", "0xd", "100"
+	tamperedConverted := converted
+	tamperedConverted.SourceAmount = "1000"
+
+	cases := []struct {
+		name    string
+		msg     InMsg
+		receipt SourceReceipt
+		err     error
+		detail  string
+	}{
+		{name: "valid", msg: msg, receipt: valid},
+		{name: "same node", msg: msg, receipt: SourceReceipt{Node: "node-0"}, err: ErrSourceUnverified},
+		{name: "not synced", msg: msg, receipt: SourceReceipt{Node: "node-1", Latest: 105}, err: ErrSourceUnverified},
+		{name: "not found", msg: msg, receipt: SourceReceipt{Node: "node-1", Latest: 120}, err: ErrSourceMismatch, detail: "not found"},
+		{name: "reverted", msg: msg, receipt: SourceReceipt{Node: "node-1", Found: true, Height: 100, Latest: 120}, err: ErrSourceMismatch, detail: "failed"},
+		{name: "other block", msg: msg, receipt: SourceReceipt{Node: "node-1", Found: true, Succeeded: true, Height: 101, Canonical: true, Latest: 120}, err: ErrSourceMismatch},
+		{name: "reorg", msg: msg, receipt: SourceReceipt{Node: "node-1", Found: true, Succeeded: true, Height: 100, Latest: 120}, err: ErrSourceUnverified},
+		{name: "shallow", msg: msg, receipt: SourceReceipt{Node: "node-1", Found: true, Succeeded: true, Height: 100, Canonical: true, Latest: 105, Log: &event}, err: ErrSourceUnverified, detail: "6 of 12"},
+		{name: "missing log", msg: msg, receipt: SourceReceipt{Node: "node-1", Found: true, Succeeded: true, Height: 100, Canonical: true, Latest: 111}, err: ErrSourceMismatch},
+		{name: "amount", msg: msg, receipt: SourceReceipt{Node: "node-1", Found: true, Succeeded: true, Height: 100, Canonical: true, Latest: 111, Log: tamper(func(l *InMsg) { l.Amount = "1" })}, err: ErrSourceMismatch, detail: `amount "100" != "1"`},
+		{name: "receiver", msg: msg, receipt: SourceReceipt{Node: "node-1", Found: true, Succeeded: true, Height: 100, Canonical: true, Latest: 111, Log: tamper(func(l *InMsg) { l.Receiver = "0xe" })}, err: ErrSourceMismatch, detail: "receiver"},
+		{name: "converted", msg: converted, receipt: valid},
+		{name: "converted amount", msg: tamperedConverted, receipt: valid, err: ErrSourceMismatch, detail: `amount "1000" != "100"`},
+	}
+	for _, c := range cases {
+		verifier := NewSourceVerifier(&fakeReader{receipt: &c.receipt}, 12)
+		err := verifier.Verify(context.Background(), c.msg)
+		if c.err == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", c.name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, c.err) || !strings.Contains(err.Error(), c.detail) {
+			t.Errorf("%s: expected %v containing %q, got %v", c.name, c.err, c.detail, err)
+		}
+	}
+
+	// 节点不可用时不放行
+	err := NewSourceVerifier(&fakeReader{err: errors.New("connection refused")}, 12).Verify(context.Background(), msg)
+	if !errors.Is(err, ErrSourceUnverified) || types.Classify(err) != types.ClassRetryable {
+		t.Errorf("Expected retryable ErrSourceUnverified, got %v", err)
+	}
+}
+
+func TestInTunnelRejectsMismatchedSource(t *testing.T) {
+	states := memStates{}
+	tunnel := NewInTunnel(&fakeSource{}, &fakeTarget{}, fakeSigner{}, nil)
+	tunnel.States = NewStateMachine(states)
+	tunnel.ErrorHandler = nil
+	tunnel.Queue = NewQueue[InMsg](0, 16)
+	tunnel.DeadLetters = NewDeadLetterQueue(newMemDeadLetters())
+
+	msg := InMsg{Nonce: 1, ChainID: "56", Height: 100, TxHash: testTxHash, Receiver: "0xb", Amount: "100"}
+	reader := &fakeReader{receipt: &SourceReceipt{Node: "node-1", Found: true, Succeeded: true, Height: 100, Canonical: true, Latest: 100}}
+	tunnel.Verifier = NewSourceVerifier(reader, 3)
+	ctx := context.Background()
+
+	// 确认数不足时等待重试
+	if ok, err := tunnel.admit(ctx, &msg); ok || !errors.Is(err, ErrSourceUnverified) {
+		t.Fatalf("Expected unverified message to be retried, got %v, %v", ok, err)
+	}
+
+	// 事件金额与消息不符时交给死信
+	forged := msg
+	forged.Amount = "1"
+	reader.receipt.Latest, reader.receipt.Log = 102, &forged
+	if ok, err := tunnel.admit(ctx, &msg); ok || err != nil {
+		t.Fatalf("Expected mismatched message to be rejected, got %v, %v", ok, err)
+	}
+	id, _ := msg.ID()
+	if states[id].State != types.MsgStateFailed {
+		t.Fatalf("Expected failed state, got %+v", states[id])
+	}
+	dl, err := tunnel.DeadLetters.Get(id)
+	if err != nil || dl == nil || !strings.Contains(dl.Errors[0], `amount "100" != "1"`) {
+		t.Fatalf("Expected dead letter for mismatched amount, got %+v, %v", dl, err)
+	}
+
+	next := msg
+	next.Nonce, next.LogIndex = 2, 1
+	reader.receipt.Log = &next
+	if ok, err := tunnel.admit(ctx, &next); !ok || err != nil {
+		t.Fatalf("Expected verified message to be admitted, got %v, %v", ok, err)
+	}
+}
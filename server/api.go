@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/st-chain/me-bridge/chain"
+	"github.com/st-chain/me-bridge/log"
+)
+
+// API 提供运维管理接口
+type API struct {
+	server *Server
+	config *APIConfig
+	mux    *http.ServeMux
+	http   *http.Server
+	logger *log.Logger
+
+	operators []operatorCredential // 可审批挂起消息的运维
+}
+
+// NewAPI 创建管理接口并注册路由
+func NewAPI(server *Server, config *APIConfig) *API {
+	a := &API{
+		server: server,
+		config: config,
+		mux:    http.NewServeMux(),
+		logger: log.WithComponent("api"),
+	}
+
+	a.mux.HandleFunc("GET /status", a.handleStatus)
+	a.mux.HandleFunc("GET /networks/{network}/nodes", a.handleGetNodes)
+	a.mux.HandleFunc("PUT /networks/{network}/nodes", a.handleUpdateNodes)
+	a.mux.HandleFunc("GET /networks/{network}/gas", a.handleGetGas)
+
+	a.mux.HandleFunc("GET /bridges", a.handleListBridges)
+	a.mux.HandleFunc("GET /bridges/{bridge}/control", a.handleGetBridgeControl)
+	a.mux.HandleFunc("POST /bridges/{bridge}/control", a.handleControlBridge)
+	a.mux.HandleFunc("GET /bridges/{bridge}/fee", a.handleQuoteFee)
+	a.mux.HandleFunc("GET /bridges/{bridge}/limits", a.handleGetBridgeLimits)
+	a.mux.HandleFunc("GET /bridges/{bridge}/reconcile", a.handleGetReconcile)
+	a.mux.HandleFunc("POST /bridges/{bridge}/reconcile", a.handleReconcile)
+
+	a.mux.HandleFunc("GET /balances", a.handleGetBalances)
+	a.mux.HandleFunc("POST /balances/check", a.handleCheckBalances)
+
+	a.mux.HandleFunc("GET /drain", a.handleDrainStatus)
+	a.mux.HandleFunc("POST /drain", a.handleDrain)
+
+	a.mux.HandleFunc("GET /messages/{id}", a.handleGetMessage)
+
+	a.mux.HandleFunc("GET /deadletters", a.handleListDeadLetters)
+	a.mux.HandleFunc("GET /deadletters/{id}", a.handleGetDeadLetter)
+	a.mux.HandleFunc("GET /deadletters/{id}/audit", a.handleDeadLetterAudit)
+	a.mux.HandleFunc("PUT /deadletters/{id}/fee", a.handleSetDeadLetterFee)
+	a.mux.HandleFunc("POST /deadletters/{id}/replay", a.handleReplayDeadLetter)
+	a.mux.HandleFunc("POST /deadletters/{id}/discard", a.handleDiscardDeadLetter)
+
+	a.mux.HandleFunc("GET /holds", a.handleListHolds)
+	a.mux.HandleFunc("GET /holds/{id}", a.handleGetHold)
+	a.mux.HandleFunc("GET /holds/{id}/audit", a.handleHoldAudit)
+	a.mux.HandleFunc("POST /holds/{id}/approve", a.handleApproveHold)
+	a.mux.HandleFunc("POST /holds/{id}/reject", a.handleRejectHold)
+
+	return a
+}
+
+// Start 在后台启动 HTTP 服务
+func (a *API) Start() error {
+	operators, err := parseOperators(a.config.Operators)
+	if err != nil {
+		return err
+	}
+	a.operators = operators
+
+	timeout := time.Duration(a.config.Timeout) * time.Second
+	a.http = &http.Server{
+		Addr:         net.JoinHostPort(a.config.IP, strconv.Itoa(int(a.config.Port))),
+		Handler:      a.mux,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+	}
+
+	ln, err := net.Listen("tcp", a.http.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", a.http.Addr, err)
+	}
+
+	go func() {
+		if err := a.http.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.logger.Error("api server stopped", map[string]any{"error": err})
+		}
+	}()
+
+	a.logger.Info("api server started", map[string]any{"addr": a.http.Addr})
+	return nil
+}
+
+// Stop 优雅关闭 HTTP 服务
+func (a *API) Stop(ctx context.Context) error {
+	if a.http == nil {
+		return nil
+	}
+	return a.http.Shutdown(ctx)
+}
+
+func (a *API) handleStatus(w http.ResponseWriter, r *http.Request) {
+	networks := make(map[string][]map[string]any, len(a.server.Networks))
+	for name, endpoints := range a.server.Networks {
+		for _, ep := range endpoints {
+			networks[name] = append(networks[name], ep.Status())
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":   a.server.Status(),
+		"networks": networks,
+		"balances": a.server.balanceStatus(),
+		"gas":      a.server.gasStatus(),
+	})
+}
+
+func (a *API) handleGetNodes(w http.ResponseWriter, r *http.Request) {
+	endpoints, ok := a.server.Networks[r.PathValue("network")]
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrUnknownNetwork)
+		return
+	}
+	status := make([]map[string]any, 0, len(endpoints))
+	for _, ep := range endpoints {
+		status = append(status, ep.Status())
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// ActionUpdateNodes 是更新节点列表时运维签名的操作名称
+const ActionUpdateNodes = "update-nodes"
+
+func (a *API) handleUpdateNodes(w http.ResponseWriter, r *http.Request) {
+	network := r.PathValue("network")
+	op, ok := a.authenticate(w, r, ActionUpdateNodes, network)
+	if !ok {
+		return
+	}
+
+	var configs []*chain.ClientConfig
+	if err := json.NewDecoder(r.Body).Decode(&configs); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := a.server.UpdateNodes(network, configs); err != nil {
+		if errors.Is(err, ErrUnknownNetwork) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	a.logger.Info("nodes updated via api", map[string]any{
+		"network":  network,
+		"nodes":    len(configs),
+		"operator": op,
+		"remote":   r.RemoteAddr,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeJSON 以 JSON 格式写回响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 以 JSON 格式写回错误
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 以签名方式认证运维时使用的请求头
+const (
+	SignatureHeader = "X-Operator-Signature" // 对 OperatorMessage 的 EIP-191 签名（十六进制）
+	TimestampHeader = "X-Operator-Timestamp" // 签名时的 Unix 秒
+)
+
+// MaxSignatureAge 是签名时间与服务器时间的最大偏差，超出的签名视为重放
+const MaxSignatureAge = 5 * time.Minute
+
+var (
+	errUnauthorized = errors.New("unauthorized operator")
+	errNoOperators  = errors.New("no operators configured")
+)
+
+// OperatorMessage 返回运维对 target 执行 action 时签名的消息
+func OperatorMessage(action, target string, timestamp int64) string {
+	return fmt.Sprintf("me-bridge operator %s %s %d", action, target, timestamp)
+}
+
+// operatorCredential 是一名运维的认证信息
+type operatorCredential struct {
+	name      string
+	tokenHash []byte
+	address   *common.Address
+}
+
+// parseOperators 解析运维认证配置
+func parseOperators(configs []OperatorConfig) ([]operatorCredential, error) {
+	out := make([]operatorCredential, 0, len(configs))
+	seen := make(map[string]bool)
+	for _, config := range configs {
+		if config.Name == "" || seen[config.Name] {
+			return nil, fmt.Errorf("operator name %q is empty or duplicated", config.Name)
+		}
+		seen[config.Name] = true
+
+		cred := operatorCredential{name: config.Name}
+		if config.TokenHash != "" {
+			hash, err := hex.DecodeString(strings.TrimPrefix(config.TokenHash, "0x"))
+			if err != nil || len(hash) != sha256.Size {
+				return nil, fmt.Errorf("operator %s: invalid token_hash", config.Name)
+			}
+			cred.tokenHash = hash
+		}
+		if config.Address != "" {
+			if !common.IsHexAddress(config.Address) {
+				return nil, fmt.Errorf("operator %s: invalid address %q", config.Name, config.Address)
+			}
+			address := common.HexToAddress(config.Address)
+			cred.address = &address
+		}
+		if cred.tokenHash == nil && cred.address == nil {
+			return nil, fmt.Errorf("operator %s: token_hash or address is required", config.Name)
+		}
+		out = append(out, cred)
+	}
+	return out, nil
+}
+
+// authenticate 认证对 target 执行 action 的运维，返回运维名称，失败时写回 401。支持两种方式：
+//   - Authorization: Bearer <令牌>，令牌的 SHA-256 与配置的 token_hash 匹配
+//   - X-Operator-Signature 和 X-Operator-Timestamp，签名地址与配置的 address 匹配
+//
+// 未配置运维时拒绝所有运维操作
+func (a *API) authenticate(w http.ResponseWriter, r *http.Request, action, target string) (string, bool) {
+	if len(a.operators) == 0 {
+		writeError(w, http.StatusForbidden, errNoOperators)
+		return "", false
+	}
+
+	var (
+		name string
+		err  error
+	)
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		name, err = a.authenticateToken(token)
+	} else if sig := r.Header.Get(SignatureHeader); sig != "" {
+		name, err = a.authenticateSignature(sig, r.Header.Get(TimestampHeader), action, target)
+	} else {
+		err = errors.New("missing credentials")
+	}
+	if err != nil {
+		a.logger.Warn("operator authentication failed", map[string]any{
+			"action": action,
+			"target": target,
+			"remote": r.RemoteAddr,
+			"error":  err,
+		})
+		writeError(w, http.StatusUnauthorized, fmt.Errorf("%w: %w", errUnauthorized, err))
+		return "", false
+	}
+	return name, true
+}
+
+func (a *API) authenticateToken(token string) (string, error) {
+	hash := sha256.Sum256([]byte(token))
+	for _, cred := range a.operators {
+		if cred.tokenHash != nil && subtle.ConstantTimeCompare(cred.tokenHash, hash[:]) == 1 {
+			return cred.name, nil
+		}
+	}
+	return "", errors.New("unknown token")
+}
+
+func (a *API) authenticateSignature(signature, timestamp, action, target string) (string, error) {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %q", TimestampHeader, timestamp)
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > MaxSignatureAge || age < -MaxSignatureAge {
+		return "", fmt.Errorf("signature timestamp %d outside %s", ts, MaxSignatureAge)
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return "", fmt.Errorf("invalid %s", SignatureHeader)
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte(OperatorMessage(action, target, ts))), sig)
+	if err != nil {
+		return "", err
+	}
+	signer := crypto.PubkeyToAddress(*pub)
+	for _, cred := range a.operators {
+		if cred.address != nil && *cred.address == signer {
+			return cred.name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown signer %s", signer.Hex())
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+// RunBalanceMonitors 在后台检查各中继账户的余额，直到 ctx 取消
+func (s *Server) RunBalanceMonitors(ctx context.Context) {
+	for _, monitor := range s.Balances {
+		if s.Alerter != nil {
+			monitor.Alerter = s.Alerter
+		}
+		go monitor.Run(ctx)
+	}
+}
+
+// balanceStatus 返回各中继账户最近一次的余额检查结果，尚未检查的账户不返回
+func (s *Server) balanceStatus() []relay.BalanceStatus {
+	status := make([]relay.BalanceStatus, 0, len(s.Balances))
+	for _, monitor := range s.Balances {
+		if last := monitor.Last(); last != nil {
+			status = append(status, *last)
+		}
+	}
+	return status
+}
+
+// handleGetBalances 返回各中继账户的余额、告警级别和预计可用时间
+func (a *API) handleGetBalances(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.server.balanceStatus())
+}
+
+// ActionCheckBalances 是立即检查余额时运维签名的操作名称
+const ActionCheckBalances = "check-balances"
+
+// handleCheckBalances 立即检查一次各中继账户的余额并返回结果
+func (a *API) handleCheckBalances(w http.ResponseWriter, r *http.Request) {
+	if _, ok := a.authenticate(w, r, ActionCheckBalances, "balances"); !ok {
+		return
+	}
+	status := make([]relay.BalanceStatus, 0, len(a.server.Balances))
+	for _, monitor := range a.server.Balances {
+		status = append(status, monitor.Check(r.Context()))
+	}
+	writeJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/relay"
+	"github.com/st-chain/me-bridge/signer"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// ControlAction 是跨链桥的运行控制操作
+type ControlAction string
+
+const (
+	ActionPauseInbound   ControlAction = "pause-inbound"   // 暂停接收新消息
+	ActionResumeInbound  ControlAction = "resume-inbound"  // 恢复接收新消息
+	ActionPauseOutbound  ControlAction = "pause-outbound"  // 暂停提交交易
+	ActionResumeOutbound ControlAction = "resume-outbound" // 恢复提交交易
+	ActionEmergencyStop  ControlAction = "emergency-stop"  // 暂停两个方向并调用合约 pause()
+	ActionResume         ControlAction = "resume"          // 恢复两个方向并解除紧急停止
+)
+
+// 跨链桥控制错误
+var (
+	ErrUnknownBridge    = errors.New("unknown bridge")
+	ErrUnknownAction    = errors.New("unknown control action")
+	ErrEmergencyStopped = errors.New("bridge is emergency stopped, use resume")
+)
+
+// PauseTimeout 是紧急停止时调用合约 pause() 的超时时间
+const PauseTimeout = 30 * time.Second
+
+// Bridge 是 Server 管理的一个跨链桥
+type Bridge struct {
+	Name     string
+	Controls *relay.Controls        // 桥下所有 Tunnel 共享的暂停开关
+	Pausers  []relay.ContractPauser // 紧急停止时调用合约 pause() 的终端
+	Key      signer.Signer          // 调用合约 pause() 的运维密钥
+	Fees     *relay.FeeCalculator   // 跨入手续费，为 nil 时不收取
+	Quoter   *relay.FeeQuoter       // gas 成本报价，为 nil 时不提供报价
+	Limits   *relay.RateLimiter     // 滚动窗口限流，为 nil 时不限流
+
+	Reconciler *relay.Reconciler // 偿付能力对账，为 nil 时不对账
+	AutoPause  ControlAction     // 对账发现差额时执行的控制操作，为空时只告警
+
+	state types.BridgeControl
+}
+
+// ControlResult 是一次控制操作后的状态，紧急停止时包含合约 pause() 的结果
+type ControlResult struct {
+	types.BridgeControl
+	PauseTxs    []string `json:"pause_txs,omitempty"`
+	PauseErrors []string `json:"pause_errors,omitempty"`
+}
+
+// LoadControls 从存储恢复各跨链桥的暂停状态，在 Tunnel 启动前调用
+func (s *Server) LoadControls() error {
+	s.controlMu.Lock()
+	defer s.controlMu.Unlock()
+
+	for name, bridge := range s.Bridges {
+		state := types.BridgeControl{Bridge: name}
+		if s.Controls != nil {
+			saved, err := s.Controls.GetControl(name)
+			if err != nil {
+				return fmt.Errorf("bridge %s: %w", name, err)
+			}
+			if saved != nil {
+				state = *saved
+			}
+		}
+		bridge.state = state
+		bridge.Controls.Apply(state)
+		if state.InboundPaused || state.OutboundPaused || state.EmergencyStop {
+			log.WithComponent("control").Warn("bridge restored in paused state", map[string]any{
+				"bridge":          name,
+				"inbound_paused":  state.InboundPaused,
+				"outbound_paused": state.OutboundPaused,
+				"emergency_stop":  state.EmergencyStop,
+				"reason":          state.Reason,
+			})
+		}
+	}
+	return nil
+}
+
+// BridgeControls 按名称返回各跨链桥的控制状态
+func (s *Server) BridgeControls() []types.BridgeControl {
+	s.controlMu.Lock()
+	defer s.controlMu.Unlock()
+
+	out := make([]types.BridgeControl, 0, len(s.Bridges))
+	for name, bridge := range s.Bridges {
+		state := bridge.state
+		state.Bridge = name
+		out = append(out, state)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Bridge < out[j].Bridge })
+	return out
+}
+
+// ControlBridge 对跨链桥执行控制操作并持久化，每次操作写入审计日志。
+// 开关先于持久化生效，持久化失败时返回错误，运维需重试以保证重启后状态一致
+func (s *Server) ControlBridge(ctx context.Context, operator, name string, action ControlAction, reason string) (*ControlResult, error) {
+	result, err := s.controlBridge(ctx, operator, name, action, reason)
+
+	entry := types.AuditEntry{
+		Time:     time.Now(),
+		Operator: operator,
+		Action:   "bridge." + string(action),
+		Target:   name,
+		Detail:   reason,
+		Result:   "ok",
+	}
+	switch {
+	case err != nil:
+		entry.Result = err.Error()
+	case len(result.PauseErrors) > 0:
+		entry.Result = "contract pause failed: " + strings.Join(result.PauseErrors, "; ")
+	}
+	if s.Audit != nil {
+		if auditErr := s.Audit.AppendAudit(entry); auditErr != nil && err == nil {
+			err = auditErr
+		}
+	}
+
+	log.WithComponent("control").Warn("bridge control", map[string]any{
+		"operator": operator,
+		"bridge":   name,
+		"action":   action,
+		"reason":   reason,
+		"result":   entry.Result,
+	})
+	return result, err
+}
+
+func (s *Server) controlBridge(ctx context.Context, operator, name string, action ControlAction, reason string) (*ControlResult, error) {
+	s.controlMu.Lock()
+	defer s.controlMu.Unlock()
+
+	bridge, ok := s.Bridges[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownBridge, name)
+	}
+
+	state := bridge.state
+	switch action {
+	case ActionPauseInbound:
+		state.InboundPaused = true
+	case ActionPauseOutbound:
+		state.OutboundPaused = true
+	case ActionResumeInbound, ActionResumeOutbound:
+		if state.EmergencyStop {
+			return nil, ErrEmergencyStopped
+		}
+		if action == ActionResumeInbound {
+			state.InboundPaused = false
+		} else {
+			state.OutboundPaused = false
+		}
+	case ActionEmergencyStop:
+		state.InboundPaused, state.OutboundPaused, state.EmergencyStop = true, true, true
+	case ActionResume:
+		state.InboundPaused, state.OutboundPaused, state.EmergencyStop = false, false, false
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAction, action)
+	}
+	state.Bridge = name
+	state.Reason = reason
+	state.Operator = operator
+	state.UpdatedAt = time.Now()
+
+	bridge.state = state
+	bridge.Controls.Apply(state)
+	result := &ControlResult{BridgeControl: state}
+
+	if action == ActionEmergencyStop {
+		pauseCtx, cancel := context.WithTimeout(ctx, PauseTimeout)
+		defer cancel()
+		for _, pauser := range bridge.Pausers {
+			txHash, err := pauser.PauseContract(pauseCtx, bridge.Key)
+			if err != nil {
+				result.PauseErrors = append(result.PauseErrors, err.Error())
+				continue
+			}
+			result.PauseTxs = append(result.PauseTxs, txHash)
+		}
+	}
+
+	if s.Controls != nil {
+		if err := s.Controls.SaveControl(state); err != nil {
+			return result, fmt.Errorf("failed to persist bridge control: %w", err)
+		}
+	}
+	return result, nil
+}
+
+func (a *API) handleListBridges(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.server.BridgeControls())
+}
+
+func (a *API) handleGetBridgeControl(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("bridge")
+	for _, state := range a.server.BridgeControls() {
+		if state.Bridge == name {
+			writeJSON(w, http.StatusOK, state)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, fmt.Errorf("%w: %s", ErrUnknownBridge, name))
+}
+
+func (a *API) handleControlBridge(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Action ControlAction `json:"action"`
+		Reason string        `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// 签名覆盖具体的控制动作，暂停的签名不能用于恢复
+	bridge := r.PathValue("bridge")
+	op, ok := a.authenticate(w, r, string(body.Action), bridge)
+	if !ok {
+		return
+	}
+
+	result, err := a.server.ControlBridge(r.Context(), op, bridge, body.Action, body.Reason)
+	switch {
+	case errors.Is(err, ErrUnknownBridge):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrUnknownAction):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, ErrEmergencyStopped):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/st-chain/me-bridge/relay"
+	"github.com/st-chain/me-bridge/types"
+)
+
+// OperatorHeader 是客户端自报的操作员名称，不用于认证：审计日志记录令牌或签名认证的运维
+const OperatorHeader = "X-Operator"
+
+var errNoDeadLetters = errors.New("dead letter queue not configured")
+
+// deadLetters 返回死信队列，未配置时写回 503
+func (a *API) deadLetters(w http.ResponseWriter) *relay.DeadLetterQueue {
+	if a.server.DeadLetters == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoDeadLetters)
+	}
+	return a.server.DeadLetters
+}
+
+// writeDeadLetterError 将死信操作错误映射为 HTTP 状态码
+func writeDeadLetterError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, relay.ErrDeadLetterNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, relay.ErrDeadLetterClosed):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, relay.ErrNoReplayer), errors.Is(err, relay.ErrReplayBusy), errors.Is(err, relay.ErrTunnelDraining):
+		writeError(w, http.StatusServiceUnavailable, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (a *API) handleListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	dlq := a.deadLetters(w)
+	if dlq == nil {
+		return
+	}
+	list, err := dlq.List(types.DeadLetterStatus(r.URL.Query().Get("status")))
+	if err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	if list == nil {
+		list = []types.DeadLetter{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleGetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	dlq := a.deadLetters(w)
+	if dlq == nil {
+		return
+	}
+	dl, err := dlq.Get(r.PathValue("id"))
+	if err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dl)
+}
+
+func (a *API) handleDeadLetterAudit(w http.ResponseWriter, r *http.Request) {
+	dlq := a.deadLetters(w)
+	if dlq == nil {
+		return
+	}
+	audit, err := dlq.Audit(r.PathValue("id"))
+	if err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	if audit == nil {
+		audit = []types.AuditEntry{}
+	}
+	writeJSON(w, http.StatusOK, audit)
+}
+
+func (a *API) handleSetDeadLetterFee(w http.ResponseWriter, r *http.Request) {
+	dlq := a.deadLetters(w)
+	if dlq == nil {
+		return
+	}
+	op, ok := a.authenticate(w, r, relay.AuditDeadLetterFee, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	// 请求体为 null 时恢复默认手续费策略
+	var policy *types.FeePolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := dlq.SetFeePolicy(op, r.PathValue("id"), policy); err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) handleReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	dlq := a.deadLetters(w)
+	if dlq == nil {
+		return
+	}
+	op, ok := a.authenticate(w, r, relay.AuditDeadLetterReplay, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	if err := dlq.Replay(op, r.PathValue("id")); err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (a *API) handleDiscardDeadLetter(w http.ResponseWriter, r *http.Request) {
+	dlq := a.deadLetters(w)
+	if dlq == nil {
+		return
+	}
+	op, ok := a.authenticate(w, r, relay.AuditDeadLetterDiscard, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if err := dlq.Discard(op, r.PathValue("id"), body.Reason); err != nil {
+		writeDeadLetterError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/st-chain/me-bridge/log"
+	"github.com/st-chain/me-bridge/relay"
+)
+
+// DefaultDrainTimeout 是未配置时等待在途交易完成的最长时间
+const DefaultDrainTimeout = 2 * time.Minute
+
+// ErrDraining 服务已在排空
+var ErrDraining = errors.New("server is already draining")
+
+var errNotDraining = errors.New("server is not draining")
+
+// Tunnel 是由 Server 管理、支持排空的跨链通道，如 relay.InTunnel 和 relay.OutTunnel
+type Tunnel interface {
+	Drain(ctx context.Context) relay.DrainSummary
+	Status() map[string]any
+}
+
+// DrainReport 是服务排空的进度和结果
+type DrainReport struct {
+	StartedAt  time.Time            `json:"started_at"`
+	Deadline   time.Time            `json:"deadline"`
+	FinishedAt *time.Time           `json:"finished_at,omitempty"`
+	Tunnels    []relay.DrainSummary `json:"tunnels,omitempty"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// Done 返回排空是否已结束
+func (r *DrainReport) Done() bool {
+	return r.FinishedAt != nil
+}
+
+// drainState 记录 Server 的排空状态
+type drainState struct {
+	mu      sync.Mutex
+	report  *DrainReport
+	drained chan struct{}
+}
+
+func (d *drainState) done() chan struct{} {
+	if d.drained == nil {
+		d.drained = make(chan struct{})
+	}
+	return d.drained
+}
+
+// Drained 返回排空结束后关闭的通道，用于在 API 或命令触发排空后退出进程
+func (s *Server) Drained() <-chan struct{} {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	return s.drain.done()
+}
+
+// DrainReport 返回排空的进度和结果，尚未开始排空时返回 nil
+func (s *Server) DrainReport() *DrainReport {
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	if s.drain.report == nil {
+		return nil
+	}
+	report := *s.drain.report
+	return &report
+}
+
+// StartDrain 开始排空，返回排空的初始状态。排空在后台进行，结束后 Drained 返回的通道关闭
+func (s *Server) StartDrain(timeout time.Duration) (*DrainReport, error) {
+	report, err := s.beginDrain(timeout)
+	if err != nil {
+		return nil, err
+	}
+	go s.runDrain(report)
+	return s.DrainReport(), nil
+}
+
+// Drain 排空所有 Tunnel 并停止服务：停止接收新的源端消息，等待已提交的交易在 timeout 内完成，
+// 剩余的消息保留在持久化队列中，重启后重新投递。返回排空的结果
+func (s *Server) Drain(timeout time.Duration) (*DrainReport, error) {
+	report, err := s.beginDrain(timeout)
+	if err != nil {
+		return nil, err
+	}
+	s.runDrain(report)
+	return s.DrainReport(), nil
+}
+
+func (s *Server) beginDrain(timeout time.Duration) (*DrainReport, error) {
+	if timeout <= 0 {
+		timeout = s.DrainTimeout
+	}
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	if s.drain.report != nil {
+		return nil, ErrDraining
+	}
+	now := time.Now()
+	s.drain.report = &DrainReport{StartedAt: now, Deadline: now.Add(timeout)}
+	return s.drain.report, nil
+}
+
+func (s *Server) runDrain(report *DrainReport) {
+	logger := log.WithComponent("drain")
+	logger.Info("draining server", map[string]any{
+		"tunnels":  len(s.Tunnels),
+		"deadline": report.Deadline,
+	})
+
+	ctx, cancel := context.WithDeadline(context.Background(), report.Deadline)
+	defer cancel()
+
+	summaries := make([]relay.DrainSummary, len(s.Tunnels))
+	var wg sync.WaitGroup
+	for i, tunnel := range s.Tunnels {
+		wg.Add(1)
+		go func(i int, tunnel Tunnel) {
+			defer wg.Done()
+			summaries[i] = tunnel.Drain(ctx)
+		}(i, tunnel)
+	}
+	wg.Wait()
+
+	err := s.Stop()
+
+	pending, queued := 0, 0
+	for _, summary := range summaries {
+		pending += len(summary.Pending)
+		queued += summary.Queued
+	}
+	logger.Info("server drained", map[string]any{
+		"tunnels":  len(summaries),
+		"pending":  pending,
+		"queued":   queued,
+		"duration": time.Since(report.StartedAt).String(),
+		"error":    err,
+	})
+
+	s.drain.mu.Lock()
+	defer s.drain.mu.Unlock()
+	now := time.Now()
+	report.FinishedAt = &now
+	report.Tunnels = summaries
+	if err != nil {
+		report.Error = err.Error()
+	}
+	close(s.drain.done())
+}
+
+func (a *API) handleDrainStatus(w http.ResponseWriter, r *http.Request) {
+	report := a.server.DrainReport()
+	if report == nil {
+		writeError(w, http.StatusNotFound, errNotDraining)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleDrain 开始排空，请求体可指定 timeout（秒），排空结束后进程退出
+func (a *API) handleDrain(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Timeout int64 `json:"timeout"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	report, err := a.server.StartDrain(time.Duration(body.Timeout) * time.Second)
+	if errors.Is(err, ErrDraining) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	a.logger.Info("drain requested", map[string]any{"remote": r.RemoteAddr, "deadline": report.Deadline})
+	writeJSON(w, http.StatusAccepted, report)
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+// FeeQuoteView 是手续费报价接口的响应
+type FeeQuoteView struct {
+	Bridge  string          `json:"bridge"`
+	Model   relay.FeeModel  `json:"model"`
+	Mode    relay.FeeMode   `json:"mode,omitempty"`
+	Amount  string          `json:"amount"`
+	Fee     string          `json:"fee"`
+	Receive string          `json:"receive"` // 接收方实际收到的金额
+	Gas     *relay.FeeQuote `json:"gas,omitempty"`
+}
+
+// handleQuoteFee 返回金额 amount（最小单位，默认 0）的手续费，gas 报价使用缓存
+func (a *API) handleQuoteFee(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("bridge")
+	bridge, ok := a.server.Bridges[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: %s", ErrUnknownBridge, name))
+		return
+	}
+
+	amount := big.NewInt(0)
+	if s := r.URL.Query().Get("amount"); s != "" {
+		if _, ok := amount.SetString(s, 10); !ok || amount.Sign() < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid amount %q", s))
+			return
+		}
+	}
+
+	view := FeeQuoteView{Bridge: name, Amount: amount.String()}
+	if bridge.Quoter != nil {
+		quote, err := bridge.Quoter.Quote(r.Context())
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		view.Gas = quote
+	}
+
+	fee, err := bridge.Fees.CalculateFee(amount)
+	if errors.Is(err, relay.ErrInvalidFeeConfig) {
+		// 如金额超过最高档位
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	receive := new(big.Int).Set(amount)
+	if fees := bridge.Fees; fees != nil {
+		view.Model, view.Mode = fees.Model, fees.Mode
+		if fees.Mode != relay.FeeModeRequired {
+			receive.Sub(receive, fee)
+		}
+	}
+	if receive.Sign() < 0 {
+		receive.SetInt64(0)
+	}
+	view.Fee, view.Receive = fee.String(), receive.String()
+	writeJSON(w, http.StatusOK, view)
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+// RunGasPolicies 在后台定期查询各链的 gas 价格，直到 ctx 取消
+func (s *Server) RunGasPolicies(ctx context.Context) {
+	for _, policy := range s.Gas {
+		go policy.Run(ctx)
+	}
+}
+
+// gasStatus 返回各链的 gas 价格策略和近期价格
+func (s *Server) gasStatus() map[string]relay.GasStatus {
+	status := make(map[string]relay.GasStatus, len(s.Gas))
+	for network, policy := range s.Gas {
+		status[network] = policy.Status()
+	}
+	return status
+}
+
+// handleGetGas 返回一条链的 gas 价格策略、推迟中的消息数和近期价格
+func (a *API) handleGetGas(w http.ResponseWriter, r *http.Request) {
+	policy, ok := a.server.Gas[r.PathValue("network")]
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrUnknownNetwork)
+		return
+	}
+	writeJSON(w, http.StatusOK, policy.Status())
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/st-chain/me-bridge/relay"
+	"github.com/st-chain/me-bridge/types"
+)
+
+var errNoHolds = errors.New("hold queue not configured")
+
+// holds 返回挂起队列，未配置时写回 503
+func (a *API) holds(w http.ResponseWriter) *relay.HoldQueue {
+	if a.server.Holds == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoHolds)
+	}
+	return a.server.Holds
+}
+
+// writeHoldError 将挂起消息操作错误映射为 HTTP 状态码
+func writeHoldError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, relay.ErrHoldNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, relay.ErrHoldClosed), errors.Is(err, relay.ErrAlreadyApproved):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, relay.ErrHoldExpired):
+		writeError(w, http.StatusGone, err)
+	case errors.Is(err, relay.ErrNoReplayer), errors.Is(err, relay.ErrReplayBusy), errors.Is(err, relay.ErrTunnelDraining):
+		writeError(w, http.StatusServiceUnavailable, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (a *API) handleListHolds(w http.ResponseWriter, r *http.Request) {
+	holds := a.holds(w)
+	if holds == nil {
+		return
+	}
+	list, err := holds.List(types.HoldStatus(r.URL.Query().Get("status")))
+	if err != nil {
+		writeHoldError(w, err)
+		return
+	}
+	if list == nil {
+		list = []types.HeldMessage{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleGetHold(w http.ResponseWriter, r *http.Request) {
+	holds := a.holds(w)
+	if holds == nil {
+		return
+	}
+	held, err := holds.Get(r.PathValue("id"))
+	if err != nil {
+		writeHoldError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, held)
+}
+
+func (a *API) handleHoldAudit(w http.ResponseWriter, r *http.Request) {
+	holds := a.holds(w)
+	if holds == nil {
+		return
+	}
+	audit, err := holds.Audit(r.PathValue("id"))
+	if err != nil {
+		writeHoldError(w, err)
+		return
+	}
+	if audit == nil {
+		audit = []types.AuditEntry{}
+	}
+	writeJSON(w, http.StatusOK, audit)
+}
+
+func (a *API) handleApproveHold(w http.ResponseWriter, r *http.Request) {
+	holds := a.holds(w)
+	if holds == nil {
+		return
+	}
+	id := r.PathValue("id")
+	op, ok := a.authenticate(w, r, relay.AuditHoldApprove, id)
+	if !ok {
+		return
+	}
+	if err := holds.Approve(op, id); err != nil {
+		writeHoldError(w, err)
+		return
+	}
+	// 返回批准进度，批准数未达到要求时消息仍在等待
+	held, err := holds.Get(id)
+	if err != nil {
+		writeHoldError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, held)
+}
+
+func (a *API) handleRejectHold(w http.ResponseWriter, r *http.Request) {
+	holds := a.holds(w)
+	if holds == nil {
+		return
+	}
+	id := r.PathValue("id")
+	op, ok := a.authenticate(w, r, relay.AuditHoldReject, id)
+	if !ok {
+		return
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if err := holds.Reject(op, id, body.Reason); err != nil {
+		writeHoldError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetBridgeLimits 返回跨链桥各限流规则在当前窗口内的用量
+func (a *API) handleGetBridgeLimits(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("bridge")
+	bridge, ok := a.server.Bridges[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: %s", ErrUnknownBridge, name))
+		return
+	}
+	usage := bridge.Limits.Utilization()
+	if usage == nil {
+		usage = []relay.LimitUsage{}
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/st-chain/me-bridge/types"
+)
+
+var (
+	errNoStateMachine  = errors.New("message state machine not configured")
+	errMessageNotFound = errors.New("message not found")
+)
+
+// messageView 是消息的当前状态和状态转换历史
+type messageView struct {
+	types.MessageRecord
+	Transitions []types.MessageTransition `json:"transitions"`
+}
+
+func (a *API) handleGetMessage(w http.ResponseWriter, r *http.Request) {
+	if a.server.Messages == nil {
+		writeError(w, http.StatusServiceUnavailable, errNoStateMachine)
+		return
+	}
+
+	id := r.PathValue("id")
+	rec, err := a.server.Messages.Get(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if rec == nil {
+		writeError(w, http.StatusNotFound, errMessageNotFound)
+		return
+	}
+	history, err := a.server.Messages.History(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if history == nil {
+		history = []types.MessageTransition{}
+	}
+	writeJSON(w, http.StatusOK, messageView{MessageRecord: *rec, Transitions: history})
+}
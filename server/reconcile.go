@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/st-chain/me-bridge/relay"
+)
+
+// ErrNoReconciler 跨链桥未配置对账
+var ErrNoReconciler = errors.New("reconciliation not configured")
+
+// RunReconcilers 在后台运行各跨链桥的偿付能力对账，直到 ctx 取消。
+// 配置了 AutoPause 的跨链桥在发现差额时以系统身份执行该控制操作，写入审计日志
+func (s *Server) RunReconcilers(ctx context.Context) {
+	for name, bridge := range s.Bridges {
+		if bridge.Reconciler == nil {
+			continue
+		}
+		if s.Alerter != nil {
+			bridge.Reconciler.Alerter = s.Alerter
+		}
+		if action := bridge.AutoPause; action != "" {
+			bridge.Reconciler.Pause = func(ctx context.Context, reason string) error {
+				_, err := s.ControlBridge(ctx, relay.AuditSystem, name, action, reason)
+				return err
+			}
+		}
+		go bridge.Reconciler.Run(ctx)
+	}
+}
+
+// handleGetReconcile 返回跨链桥最近一次对账的结果
+func (a *API) handleGetReconcile(w http.ResponseWriter, r *http.Request) {
+	reconciler, ok := a.reconciler(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, reconciler.Last())
+}
+
+// ActionReconcile 是立即对账时运维签名的操作名称
+const ActionReconcile = "reconcile"
+
+// handleReconcile 立即执行一次对账并返回结果
+func (a *API) handleReconcile(w http.ResponseWriter, r *http.Request) {
+	if _, ok := a.authenticate(w, r, ActionReconcile, r.PathValue("bridge")); !ok {
+		return
+	}
+	reconciler, ok := a.reconciler(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, reconciler.Reconcile(r.Context()))
+}
+
+func (a *API) reconciler(w http.ResponseWriter, r *http.Request) (*relay.Reconciler, bool) {
+	name := r.PathValue("bridge")
+	bridge, ok := a.server.Bridges[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: %s", ErrUnknownBridge, name))
+		return nil, false
+	}
+	if bridge.Reconciler == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: %s", ErrNoReconciler, name))
+		return nil, false
+	}
+	return bridge.Reconciler, true
+}
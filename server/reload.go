@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/st-chain/me-bridge/chain"
+	"github.com/st-chain/me-bridge/log"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownNetwork 请求的网络未配置
+var ErrUnknownNetwork = errors.New("unknown network")
+
+// UpdateNodes 更新指定网络下所有端点的节点列表
+func (s *Server) UpdateNodes(network string, configs []*chain.ClientConfig) error {
+	endpoints, ok := s.Networks[network]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownNetwork, network)
+	}
+
+	var errs []error
+	for _, ep := range endpoints {
+		if err := ep.UpdateNodes(configs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReloadNodes 按配置文件中的网络配置更新所有端点的节点列表
+func (s *Server) ReloadNodes(config *ServerConfig) error {
+	var errs []error
+	for _, network := range config.Networks {
+		if _, ok := s.Networks[network.Name]; !ok {
+			continue
+		}
+		configs := make([]*chain.ClientConfig, 0, len(network.ClientConfigs))
+		for _, c := range network.ClientConfigs {
+			configs = append(configs, &chain.ClientConfig{
+				Name:    c.Name,
+				GRPCURL: c.GRPCURL,
+				RPCURL:  c.RPCURL,
+				WSURL:   c.WSURL,
+			})
+		}
+		if err := s.UpdateNodes(network.Name, configs); err != nil {
+			errs = append(errs, fmt.Errorf("network %s: %w", network.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WatchConfig 定期检查配置文件的修改时间，文件变化后重新加载节点列表，直到 ctx 结束
+func (s *Server) WatchConfig(ctx context.Context, path string, interval time.Duration) {
+	logger := log.WithComponent("config-watcher")
+
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil {
+				logger.Warn("failed to stat config file", map[string]any{"path": path, "error": err})
+				continue
+			}
+			if !info.ModTime().After(modTime) {
+				continue
+			}
+			modTime = info.ModTime()
+
+			if err := s.reloadFile(path); err != nil {
+				logger.Error("failed to reload config", map[string]any{"path": path, "error": err})
+				continue
+			}
+			logger.Info("config reloaded", map[string]any{"path": path})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reloadFile 读取并应用配置文件中的节点列表
+func (s *Server) reloadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var config ServerConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("failed to parse YAML config: %w", err)
+	}
+	return s.ReloadNodes(&config)
+}
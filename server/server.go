@@ -1,16 +1,68 @@
 package server
 
 import (
+	"sync"
+	"time"
+
 	"github.com/st-chain/me-bridge/chain"
 	"github.com/st-chain/me-bridge/relay"
+	"github.com/st-chain/me-bridge/types"
 )
 
 type Server struct {
-	
 	Relays map[string]*relay.Relay
+
+	// Networks 按网络名称索引的端点，用于运行时更新节点列表
+	Networks map[string][]NodeUpdater
+
+	// DeadLetters 保存处理失败、等待运维处理的消息
+	DeadLetters *relay.DeadLetterQueue
+
+	// Holds 保存超出限流、等待人工审批的消息
+	Holds *relay.HoldQueue
+
+	// Messages 记录消息生命周期状态，其他组件通过 Subscribe 订阅状态转换
+	Messages *relay.StateMachine
+
+	// Tunnels 是服务运行的跨链通道，排空时逐一停止
+	Tunnels []Tunnel
+
+	// Bridges 按名称索引的跨链桥，支持运行时暂停和紧急停止
+	Bridges map[string]*Bridge
+
+	// Controls 持久化跨链桥的暂停状态，为 nil 时重启后恢复运行
+	Controls types.ControlStore
+
+	// Audit 记录运维操作
+	Audit types.AuditLog
+
+	// Balances 监控各链上提交交易的中继账户余额
+	Balances []*relay.BalanceMonitor
+
+	// Gas 按网络名称索引的 gas 价格策略
+	Gas map[string]*relay.GasPolicy
+
+	// Alerter 发送对账差额、余额不足等需要运维关注的告警，为 nil 时只写入日志
+	Alerter relay.Alerter
+
+	// DrainTimeout 是排空时等待在途交易完成的默认时间
+	DrainTimeout time.Duration
+
+	drain     drainState
+	controlMu sync.Mutex
+}
+
+// NodeUpdater 由支持运行时更新节点列表的端点实现，如 chain.InEndpoint 和 chain.OutEndpoint
+type NodeUpdater interface {
+	UpdateNodes(configs []*chain.ClientConfig) error
+	Status() map[string]any
 }
 
+// Start 恢复跨链桥的暂停状态后启动所有中继器
 func (s *Server) Start() error {
+	if err := s.LoadControls(); err != nil {
+		return err
+	}
 	for _, relay := range s.Relays {
 		if err := relay.Start(); err != nil {
 			return err
@@ -29,9 +81,21 @@ func (s *Server) Stop() error {
 }
 
 func (s *Server) Status() string {
-	return "running"
+	report := s.DrainReport()
+	switch {
+	case report == nil:
+		return "running"
+	case report.Done():
+		return "drained"
+	default:
+		return "draining"
+	}
 }
 
+// Restart 停止并重新启动所有中继器，暂停状态从存储恢复
 func (s *Server) Restart() error {
-	return nil
+	if err := s.Stop(); err != nil {
+		return err
+	}
+	return s.Start()
 }
@@ -0,0 +1,201 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrorClass 表示错误的类别，决定各层如何处理错误
+type ErrorClass int
+
+const (
+	ClassUnknown           ErrorClass = iota // 无法识别，交由上层处理
+	ClassConnection                          // 节点连接错误，应切换节点
+	ClassTimeout                             // 请求超时，应切换节点
+	ClassRetryable                           // 临时错误，可在原节点重试
+	ClassIgnorable                           // 可忽略的错误，如交易已在交易池中
+	ClassInsufficientFunds                   // 账户余额不足，重试无意义
+	ClassFatal                               // 致命错误，如参数或签名无效
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ClassConnection:
+		return "connection"
+	case ClassTimeout:
+		return "timeout"
+	case ClassRetryable:
+		return "retryable"
+	case ClassIgnorable:
+		return "ignorable"
+	case ClassInsufficientFunds:
+		return "insufficient_funds"
+	case ClassFatal:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// JSON-RPC 错误码
+const (
+	rpcCodeServerError    = -32000 // geth 交易池等返回的通用服务端错误
+	rpcCodeLimitExceeded  = -32005 // 服务商限流
+	rpcCodeTimeout        = -32002 // geth 服务端请求超时
+	rpcCodeParseError     = -32700
+	rpcCodeInvalidRequest = -32600
+	rpcCodeMethodNotFound = -32601
+	rpcCodeInvalidParams  = -32602
+	rpcCodeInternalError  = -32603
+)
+
+// serverErrorClasses 将 geth 以 -32000 返回的交易池错误信息映射到错误类别。
+// 这些错误经过 JSON-RPC 后只剩下错误信息，只能按前缀匹配。
+var serverErrorClasses = []struct {
+	prefix string
+	class  ErrorClass
+}{
+	{"already known", ClassIgnorable},                            // txpool.ErrAlreadyKnown
+	{"nonce too low", ClassRetryable},                            // core.ErrNonceTooLow
+	{"nonce too high", ClassRetryable},                           // core.ErrNonceTooHigh
+	{"replacement transaction underpriced", ClassRetryable},      // txpool.ErrReplaceUnderpriced
+	{"transaction underpriced", ClassRetryable},                  // txpool.ErrUnderpriced
+	{"max fee per gas less than block base fee", ClassRetryable}, // core.ErrFeeCapTooLow
+	{"insufficient funds", ClassInsufficientFunds},               // core.ErrInsufficientFunds
+	{"intrinsic gas too low", ClassFatal},                        // core.ErrIntrinsicGas
+	{"exceeds block gas limit", ClassFatal},                      // txpool.ErrGasLimit
+	{"invalid sender", ClassFatal},                               // txpool.ErrInvalidSender
+	{"execution reverted", ClassFatal},
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []registeredError
+)
+
+type registeredError struct {
+	target error
+	class  ErrorClass
+}
+
+// RegisterClass 为哨兵错误注册类别，供各链客户端在 init 中声明自身错误的处理方式
+func RegisterClass(target error, class ErrorClass) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, registeredError{target: target, class: class})
+}
+
+// Classify 根据错误的类型和错误链判断错误类别
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ClassUnknown
+	}
+
+	registryMu.RLock()
+	for _, r := range registry {
+		if errors.Is(err, r.target) {
+			registryMu.RUnlock()
+			return r.class
+		}
+	}
+	registryMu.RUnlock()
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return ClassTimeout
+	}
+
+	if errors.Is(err, rpc.ErrClientQuit) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return ClassConnection
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return classifyRPCError(rpcErr)
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return classifyHTTPStatus(httpErr.StatusCode)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ClassTimeout
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ClassConnection
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ClassConnection
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return ClassConnection
+	}
+
+	return ClassUnknown
+}
+
+// classifyRPCError 按 JSON-RPC 错误码分类
+func classifyRPCError(err rpc.Error) ErrorClass {
+	switch err.ErrorCode() {
+	case rpcCodeServerError:
+		msg := strings.ToLower(err.Error())
+		for _, e := range serverErrorClasses {
+			if strings.HasPrefix(msg, e.prefix) {
+				return e.class
+			}
+		}
+		return ClassUnknown
+	case rpcCodeLimitExceeded:
+		return ClassConnection
+	case rpcCodeTimeout:
+		return ClassTimeout
+	case rpcCodeInternalError:
+		return ClassRetryable
+	case rpcCodeParseError, rpcCodeInvalidRequest, rpcCodeMethodNotFound, rpcCodeInvalidParams:
+		return ClassFatal
+	default:
+		return ClassUnknown
+	}
+}
+
+// classifyHTTPStatus 按 HTTP 状态码分类
+func classifyHTTPStatus(code int) ErrorClass {
+	switch {
+	case code == http.StatusTooManyRequests, code >= 500:
+		return ClassConnection
+	case code == http.StatusRequestTimeout:
+		return ClassTimeout
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		return ClassFatal
+	default:
+		return ClassUnknown
+	}
+}
+
+// IsConnectionError 判断错误是否应触发节点切换
+func IsConnectionError(err error) bool {
+	switch Classify(err) {
+	case ClassConnection, ClassTimeout:
+		return true
+	default:
+		return false
+	}
+}
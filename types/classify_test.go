@@ -0,0 +1,141 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// rpcErrorFrom 通过本地 JSON-RPC 服务返回指定错误，获得 go-ethereum 客户端实际产生的错误值
+func rpcErrorFrom(t *testing.T, code int, message string) error {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"error":{"code":%d,"message":%q}}`, code, message)
+	}))
+	defer srv.Close()
+
+	return callRPC(t, srv.URL)
+}
+
+// httpErrorFrom 通过本地 HTTP 服务返回指定状态码
+func httpErrorFrom(t *testing.T, status int) error {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, http.StatusText(status), status)
+	}))
+	defer srv.Close()
+
+	return callRPC(t, srv.URL)
+}
+
+func callRPC(t *testing.T, endpoint string) error {
+	t.Helper()
+
+	client, err := rpc.DialHTTP(endpoint)
+	if err != nil {
+		t.Fatalf("Failed to dial rpc: %v", err)
+	}
+	defer client.Close()
+
+	var result string
+	err = client.CallContext(context.Background(), &result, "eth_sendRawTransaction", "0x00")
+	if err == nil {
+		t.Fatal("Expected rpc error")
+	}
+	return err
+}
+
+// refusedErrorFrom 连接一个已关闭的端口，获得真实的连接拒绝错误
+func refusedErrorFrom(t *testing.T) error {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	return callRPC(t, "http://"+addr)
+}
+
+func TestClassify(t *testing.T) {
+	sentinel := errors.New("node unavailable")
+	RegisterClass(sentinel, ClassConnection)
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-timeoutCtx.Done()
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"nil", nil, ClassUnknown},
+		{"plain error", errors.New("something odd"), ClassUnknown},
+		{"registered sentinel", sentinel, ClassConnection},
+		{"wrapped sentinel", fmt.Errorf("get height: %w", sentinel), ClassConnection},
+		{"context deadline", timeoutCtx.Err(), ClassTimeout},
+		{"wrapped deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), ClassTimeout},
+		{"eof", io.EOF, ClassConnection},
+		{"client quit", rpc.ErrClientQuit, ClassConnection},
+		{"connection refused", refusedErrorFrom(t), ClassConnection},
+		{"already known", rpcErrorFrom(t, -32000, "already known"), ClassIgnorable},
+		{"nonce too low", rpcErrorFrom(t, -32000, "nonce too low: address 0xabc, tx: 1 state: 2"), ClassRetryable},
+		{"replacement underpriced", rpcErrorFrom(t, -32000, "replacement transaction underpriced"), ClassRetryable},
+		{"transaction underpriced", rpcErrorFrom(t, -32000, "transaction underpriced"), ClassRetryable},
+		{"insufficient funds", rpcErrorFrom(t, -32000, "insufficient funds for gas * price + value"), ClassInsufficientFunds},
+		{"intrinsic gas", rpcErrorFrom(t, -32000, "intrinsic gas too low"), ClassFatal},
+		{"unknown server error", rpcErrorFrom(t, -32000, "header not found"), ClassUnknown},
+		{"rate limited code", rpcErrorFrom(t, -32005, "limit exceeded"), ClassConnection},
+		{"server timeout code", rpcErrorFrom(t, -32002, "request timed out"), ClassTimeout},
+		{"method not found", rpcErrorFrom(t, -32601, "the method eth_foo does not exist/is not available"), ClassFatal},
+		{"internal error", rpcErrorFrom(t, -32603, "internal error"), ClassRetryable},
+		{"http 429", httpErrorFrom(t, http.StatusTooManyRequests), ClassConnection},
+		{"http 503", httpErrorFrom(t, http.StatusServiceUnavailable), ClassConnection},
+		{"http 401", httpErrorFrom(t, http.StatusUnauthorized), ClassFatal},
+		{"dns error", &net.DNSError{Err: "no such host", Name: "node.invalid"}, ClassConnection},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("execution reverted"), false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"connection refused", refusedErrorFrom(t), true},
+		{"nonce too low", rpcErrorFrom(t, -32000, "nonce too low"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsConnectionError(tt.err); got != tt.want {
+				t.Errorf("IsConnectionError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
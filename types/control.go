@@ -0,0 +1,24 @@
+package types
+
+import "time"
+
+// BridgeControl 是跨链桥的运行控制状态，持久化后重启时恢复
+type BridgeControl struct {
+	Bridge         string    `json:"bridge"`
+	InboundPaused  bool      `json:"inbound_paused"`  // 暂停接收新消息
+	OutboundPaused bool      `json:"outbound_paused"` // 暂停提交交易
+	EmergencyStop  bool      `json:"emergency_stop"`  // 已紧急停止，合约已请求 pause()
+	Reason         string    `json:"reason,omitempty"`
+	Operator       string    `json:"operator,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ControlStore 持久化跨链桥的运行控制状态
+type ControlStore interface {
+	// GetControl 返回跨链桥的控制状态，不存在时返回 nil
+	GetControl(bridge string) (*BridgeControl, error)
+	// SaveControl 写入或覆盖跨链桥的控制状态
+	SaveControl(c BridgeControl) error
+	// ListControls 返回所有已记录的控制状态
+	ListControls() ([]BridgeControl, error)
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetterStatus 死信的处理状态
+type DeadLetterStatus string
+
+const (
+	DeadLetterPending   DeadLetterStatus = "pending"   // 等待运维处理
+	DeadLetterReplayed  DeadLetterStatus = "replayed"  // 已重新投递
+	DeadLetterDiscarded DeadLetterStatus = "discarded" // 已永久丢弃
+)
+
+// FeePolicy 是运维为单条消息指定的手续费策略，重新投递时覆盖默认策略
+type FeePolicy struct {
+	GasPrice string `json:"gas_price,omitempty"` // 固定 gas 价格（wei）
+	GasLimit uint64 `json:"gas_limit,omitempty"` // 固定 gas 上限
+	MaxFee   string `json:"max_fee,omitempty"`   // 愿意支付的最高手续费（wei）
+}
+
+// DeadLetter 是重试耗尽或发生致命错误后无法自动处理的消息
+type DeadLetter struct {
+	ID         string           `json:"id"`    // 确定性消息 ID，无法计算时为 queue:nonce
+	Queue      string           `json:"queue"` // 所属 Tunnel
+	Nonce      uint64           `json:"nonce"`
+	Payload    json.RawMessage  `json:"payload"`  // 原始消息
+	Errors     []string         `json:"errors"`   // 每次失败的错误链，由外到内
+	Attempts   int              `json:"attempts"` // 累计处理次数
+	LastTxHash string           `json:"last_tx_hash,omitempty"`
+	FeePolicy  *FeePolicy       `json:"fee_policy,omitempty"`
+	Status     DeadLetterStatus `json:"status"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+// AuditEntry 记录一次运维操作
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Operator string    `json:"operator"`
+	Action   string    `json:"action"`           // 操作类型，如 replay、discard
+	Target   string    `json:"target"`           // 操作对象，如死信 ID
+	Detail   string    `json:"detail,omitempty"` // 操作参数
+	Result   string    `json:"result"`           // ok 或错误信息
+}
+
+// AuditLog 持久化运维审计日志
+type AuditLog interface {
+	// AppendAudit 追加一条审计日志
+	AppendAudit(entry AuditEntry) error
+	// ListAudit 按时间返回操作对象的审计日志，target 为空时返回全部
+	ListAudit(target string) ([]AuditEntry, error)
+}
+
+// DeadLetterStore 持久化死信和运维审计日志
+type DeadLetterStore interface {
+	AuditLog
+	// SaveDeadLetter 写入或覆盖死信
+	SaveDeadLetter(dl DeadLetter) error
+	// GetDeadLetter 返回死信，不存在时返回 nil
+	GetDeadLetter(id string) (*DeadLetter, error)
+	// ListDeadLetters 按创建时间返回指定状态的死信，status 为空时返回全部
+	ListDeadLetters(status DeadLetterStatus) ([]DeadLetter, error)
+}
@@ -0,0 +1,49 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// HoldStatus 挂起消息的审批状态
+type HoldStatus string
+
+const (
+	HoldPending  HoldStatus = "pending"  // 等待审批
+	HoldApproved HoldStatus = "approved" // 已批准，重新进入中继流程
+	HoldRejected HoldStatus = "rejected" // 已拒绝
+	HoldExpired  HoldStatus = "expired"  // 审批超时
+)
+
+// HoldApproval 是一名运维对挂起消息的批准
+type HoldApproval struct {
+	Operator string    `json:"operator"`
+	Time     time.Time `json:"time"`
+}
+
+// HeldMessage 是因超出限流或金额超过审批阈值等原因暂停自动中继、等待运维审批的消息。
+// 收到 Required 名不同运维的批准后放行
+type HeldMessage struct {
+	ID        string          `json:"id"`    // 确定性消息 ID
+	Queue     string          `json:"queue"` // 所属 Tunnel
+	Nonce     uint64          `json:"nonce"`
+	Payload   json.RawMessage `json:"payload"` // 原始消息
+	Reason    string          `json:"reason"`  // 挂起原因
+	Status    HoldStatus      `json:"status"`
+	Required  int             `json:"required"` // 放行所需的批准数
+	Approvals []HoldApproval  `json:"approvals,omitempty"`
+	ExpiresAt time.Time       `json:"expires_at,omitempty"` // 审批截止时间，零值表示不过期
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// HoldStore 持久化挂起消息和运维审计日志
+type HoldStore interface {
+	AuditLog
+	// SaveHold 写入或覆盖挂起消息
+	SaveHold(h HeldMessage) error
+	// GetHold 返回挂起消息，不存在时返回 nil
+	GetHold(id string) (*HeldMessage, error)
+	// ListHolds 按创建时间返回指定状态的挂起消息，status 为空时返回全部
+	ListHolds(status HoldStatus) ([]HeldMessage, error)
+}
@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// LedgerEntry 是中继器账本中的一条消息：入队时记为未完成，目标端完成后计入已释放金额
+type LedgerEntry struct {
+	ID        string    `json:"id"`     // 消息 ID
+	Bridge    string    `json:"bridge"` // 所属 Tunnel 路径
+	Token     string    `json:"token"`  // 目标端代币地址
+	Amount    string    `json:"amount"` // 目标端释放的金额（最小单位，十进制）
+	Completed bool      `json:"completed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LedgerTotal 是账本中一个跨链桥一种代币已完成消息和在途消息的累计金额
+type LedgerTotal struct {
+	Bridge   string `json:"bridge"`
+	Token    string `json:"token"`
+	Amount   string `json:"amount"`    // 已完成消息的累计金额（最小单位，十进制）
+	Count    uint64 `json:"count"`     // 已完成消息数
+	InFlight string `json:"in_flight"` // 已入队未完成消息的累计金额，目标端可能已释放但尚未确认
+}
+
+// LedgerStore 持久化中继器账本，用于与链上的锁定量和释放量对账
+type LedgerStore interface {
+	// SaveLedgerEntry 写入或覆盖账本记录
+	SaveLedgerEntry(e LedgerEntry) error
+	// GetLedgerEntry 返回账本记录，不存在时返回 nil
+	GetLedgerEntry(id string) (*LedgerEntry, error)
+	// LedgerTotals 按代币返回跨链桥已完成消息和在途消息的累计金额
+	LedgerTotals(bridge string) ([]LedgerTotal, error)
+}
@@ -0,0 +1,69 @@
+package types
+
+import "time"
+
+// MessageTransition 是消息的一次状态转换
+type MessageTransition struct {
+	ID     string       `json:"id"`
+	Queue  string       `json:"queue"`
+	Nonce  uint64       `json:"nonce"`
+	From   MessageState `json:"from"`
+	To     MessageState `json:"to"`
+	TxHash string       `json:"tx_hash,omitempty"`
+	Detail string       `json:"detail,omitempty"`
+	At     time.Time    `json:"at"`
+}
+
+// msgStateOrder 是消息正常处理流程中各状态的顺序
+var msgStateOrder = map[MessageState]int{
+	MsgStateUnknown:   0,
+	MsgStateObserved:  1,
+	MsgStateConfirmed: 2,
+	MsgStateHeld:      3,
+	MsgStateQueued:    4,
+	MsgStateSigned:    5,
+	MsgStateSubmitted: 6,
+	MsgStateMined:     7,
+	MsgStateFinalized: 8,
+	MsgStateCompleted: 9,
+}
+
+// Terminal 返回状态是否为终态
+func (s MessageState) Terminal() bool {
+	return s == MsgStateCompleted || s == MsgStateRefunded
+}
+
+// Valid 返回状态是否为已定义的状态
+func (s MessageState) Valid() bool {
+	_, ok := msgStateOrder[s]
+	return ok || s == MsgStateFailed || s == MsgStateRefunded
+}
+
+// CanTransition 返回状态转换是否合法：
+//   - 正常流程中只能前进，可以跳过中间状态（如终端不上报签名和打包）
+//   - 已签名、已提交、已打包的消息可以回到等待提交（交易被丢弃或回滚后重新提交）
+//   - 任何非终态都可以转为失败
+//   - 失败的消息可以重新等待提交、确认已在目标端完成或在源端退款
+//   - 完成和退款是终态
+func CanTransition(from, to MessageState) bool {
+	if !from.Valid() || !to.Valid() || from.Terminal() || to == MsgStateUnknown {
+		return false
+	}
+
+	switch from {
+	case MsgStateFailed:
+		return to == MsgStateQueued || to == MsgStateCompleted || to == MsgStateRefunded
+	case MsgStateSigned, MsgStateSubmitted, MsgStateMined:
+		if to == MsgStateQueued {
+			return true
+		}
+	}
+
+	switch to {
+	case MsgStateFailed:
+		return true
+	case MsgStateRefunded:
+		return false
+	}
+	return msgStateOrder[to] > msgStateOrder[from]
+}
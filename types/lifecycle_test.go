@@ -0,0 +1,37 @@
+package types
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to MessageState
+		want     bool
+	}{
+		{MsgStateUnknown, MsgStateObserved, true},
+		{MsgStateObserved, MsgStateConfirmed, true},
+		{MsgStateUnknown, MsgStateQueued, true},      // 可以跳过源端状态
+		{MsgStateQueued, MsgStateSubmitted, true},    // 可以跳过签名
+		{MsgStateSubmitted, MsgStateQueued, true},    // 交易被丢弃后重新提交
+		{MsgStateMined, MsgStateQueued, true},        // 回滚后重新提交
+		{MsgStateConfirmed, MsgStateObserved, false}, // 不能后退
+		{MsgStateObserved, MsgStateQueued, true},
+		{MsgStateObserved, MsgStateHeld, true},
+		{MsgStateHeld, MsgStateQueued, true},  // 审批通过
+		{MsgStateHeld, MsgStateFailed, true},  // 审批拒绝
+		{MsgStateQueued, MsgStateHeld, false}, // 已放行的消息不能再挂起
+		{MsgStateQueued, MsgStateFailed, true},
+		{MsgStateFailed, MsgStateQueued, true},
+		{MsgStateFailed, MsgStateRefunded, true},
+		{MsgStateFailed, MsgStateSubmitted, false},
+		{MsgStateQueued, MsgStateRefunded, false},
+		{MsgStateCompleted, MsgStateFailed, false},
+		{MsgStateRefunded, MsgStateQueued, false},
+		{MsgStateQueued, MsgStateUnknown, false},
+		{MsgStateQueued, MessageState("bogus"), false},
+	}
+	for _, c := range cases {
+		if got := CanTransition(c.from, c.to); got != c.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+}
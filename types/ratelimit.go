@@ -0,0 +1,32 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// RateUsage 是限流滚动窗口中一个时间桶的累计用量
+type RateUsage struct {
+	Key    string    `json:"key"`    // 限流对象，如 bsc->tron/token/0x55d3...
+	Bucket time.Time `json:"bucket"` // 时间桶起点
+	Value  string    `json:"value"`  // 累计金额（最小单位，十进制）
+	Count  uint64    `json:"count"`  // 累计消息数
+}
+
+// RateLimitStore 持久化限流用量，重启后恢复滚动窗口
+type RateLimitStore interface {
+	// AddUsage 将用量累加到对应的时间桶
+	AddUsage(u RateUsage) error
+	// ReleaseUsage 从对应的时间桶扣减用量，不低于 0，用于撤销没有提交的消息预留的用量
+	ReleaseUsage(u RateUsage) error
+	// ListUsage 返回起点不早于 since 的时间桶
+	ListUsage(since time.Time) ([]RateUsage, error)
+	// PruneUsage 删除限流对象属于 prefix 且起点早于 before 的时间桶，
+	// 多条桥共用一个存储时各自只清理自己的用量
+	PruneUsage(prefix string, before time.Time) error
+}
+
+// UsageKeyHasPrefix 判断限流对象 key 是否属于 prefix，即等于 prefix 或以 prefix+"/" 开头
+func UsageKeyHasPrefix(key, prefix string) bool {
+	return key == prefix || strings.HasPrefix(key, prefix+"/")
+}
@@ -0,0 +1,65 @@
+package types
+
+import "time"
+
+// StoredMessage 是持久化队列中的一条消息
+type StoredMessage struct {
+	Nonce     uint64    `json:"nonce"`
+	Payload   []byte    `json:"payload"`
+	Attempts  int       `json:"attempts"` // 已投递次数
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MessageStore 持久化队列消息，保证已接收但未确认的消息在重启后重新投递
+type MessageStore interface {
+	// Append 持久化一条已按序接收的消息，重复写入同一 nonce 应当幂等
+	Append(queue string, nonce uint64, payload []byte) error
+	// Ack 确认消息已在目标端完成，之后不再投递
+	Ack(queue string, nonce uint64) error
+	// Unacked 按 nonce 升序返回所有未确认的消息，并增加其投递次数
+	Unacked(queue string) ([]StoredMessage, error)
+	// LatestNonce 返回队列中已持久化的最大 nonce
+	LatestNonce(queue string) (uint64, error)
+	// Close 释放存储资源
+	Close() error
+}
+
+// MessageState 跨链消息的处理状态，状态之间的合法转换见 CanTransition
+type MessageState string
+
+const (
+	MsgStateUnknown   MessageState = ""          // 未记录
+	MsgStateObserved  MessageState = "observed"  // 已在源端发现
+	MsgStateConfirmed MessageState = "confirmed" // 已在源端达到确认深度
+	MsgStateHeld      MessageState = "held"      // 等待人工审批，如超出限流
+	MsgStateQueued    MessageState = "queued"    // 已通过检查，等待提交
+	MsgStateSigned    MessageState = "signed"    // 目标端交易已签名
+	MsgStateSubmitted MessageState = "submitted" // 已提交到目标端
+	MsgStateMined     MessageState = "mined"     // 目标端交易已打包
+	MsgStateFinalized MessageState = "finalized" // 目标端交易已达到最终性
+	MsgStateCompleted MessageState = "completed" // 已在目标端完成
+	MsgStateFailed    MessageState = "failed"    // 处理失败
+	MsgStateRefunded  MessageState = "refunded"  // 处理失败后已在源端退款
+)
+
+// MessageRecord 是按确定性消息 ID 持久化的处理记录
+type MessageRecord struct {
+	ID        string       `json:"id"`
+	Queue     string       `json:"queue"`
+	Nonce     uint64       `json:"nonce"`
+	State     MessageState `json:"state"`
+	TxHash    string       `json:"tx_hash,omitempty"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// StateStore 按确定性消息 ID 持久化消息的处理状态，用于在重启、重新订阅和人工重放时防止重复提交
+type StateStore interface {
+	// GetRecord 返回消息的处理记录，不存在时返回 nil
+	GetRecord(id string) (*MessageRecord, error)
+	// SaveRecord 写入或更新消息的处理记录
+	SaveRecord(rec MessageRecord) error
+	// SaveTransition 原子地更新处理记录并追加一条状态转换
+	SaveTransition(rec MessageRecord, tr MessageTransition) error
+	// ListTransitions 按时间返回消息的状态转换
+	ListTransitions(id string) ([]MessageTransition, error)
+}